@@ -0,0 +1,59 @@
+// Package atomicfile writes files through a temporary file renamed over
+// them, so a partial write is never visible and a failed one leaves the
+// file as it was.
+package atomicfile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// Write replaces the file at path with what write writes, keeping its
+// permissions, 0644 for a new file. Errors of write are returned as is.
+func Write(path string, write func(io.Writer) error) error {
+	mode := fs.FileMode(0o644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("Could not create file: %w", err)
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if err := write(tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return fmt.Errorf("Could not write file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("Could not write file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("Could not replace file: %w", err)
+	}
+
+	return nil
+}
+
+// WriteBytes replaces the file at path with content like Write.
+func WriteBytes(path string, content []byte) error {
+	return Write(path, func(w io.Writer) error {
+		if _, err := io.Copy(w, bytes.NewReader(content)); err != nil {
+			return fmt.Errorf("Could not write file: %w", err)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,157 @@
+// Package config resolves where the program keeps its data and logs.
+//
+// Every location can be set by a command-line flag, then by an environment
+// variable, and defaults to the XDG base directories on Linux and to the
+// user configuration directory elsewhere.
+package config
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+const (
+	// appName names the directories of the program.
+	appName = "expensetracker"
+
+	// DatabaseEnv is the environment variable setting the database path.
+	DatabaseEnv = "EXPENSETRACKER_DB"
+	// LogEnv is the environment variable setting the log file path.
+	LogEnv = "EXPENSETRACKER_LOG"
+	// UserEnv is the environment variable setting the user written
+	// in the audit log.
+	UserEnv = "EXPENSETRACKER_USER"
+
+	databaseName = "db.sqlite3"
+	logName      = "logs.txt"
+)
+
+// DatabasePath returns the path of the database: flagValue when set, then
+// the EXPENSETRACKER_DB environment variable, then db.sqlite3 in the data
+// directory. A db.sqlite3 in the working directory left by older versions
+// is still used when the data directory has none, so it is not lost.
+func DatabasePath(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	if path := os.Getenv(DatabaseEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := dataDir()
+	if err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(dir, databaseName)
+
+	if !exists(path) && exists(databaseName) {
+		return databaseName, nil
+	}
+
+	return path, nil
+}
+
+// journalExtensions are the extensions of plain text accounting journals.
+var journalExtensions = []string{".journal", ".ledger", ".hledger", ".j"}
+
+// IsJournal reports whether the database at path is a plain text
+// accounting journal rather than an sqlite database, from its extension.
+func IsJournal(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, journalExt := range journalExtensions {
+		if ext == journalExt {
+			return true
+		}
+	}
+	return false
+}
+
+// LogPath returns the path of the log file: flagValue when set, then the
+// EXPENSETRACKER_LOG environment variable, then logs.txt in the state
+// directory.
+func LogPath(flagValue string) (string, error) {
+	if flagValue != "" {
+		return flagValue, nil
+	}
+
+	if path := os.Getenv(LogEnv); path != "" {
+		return path, nil
+	}
+
+	dir, err := stateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(dir, logName), nil
+}
+
+// User returns who makes the changes written in the audit log: flagValue
+// when set, then the EXPENSETRACKER_USER environment variable, then the
+// name of the system user, empty when unknown.
+func User(flagValue string) string {
+	if flagValue != "" {
+		return flagValue
+	}
+
+	if name := os.Getenv(UserEnv); name != "" {
+		return name
+	}
+
+	current, err := user.Current()
+	if err != nil {
+		return ""
+	}
+
+	return current.Username
+}
+
+// dataDir returns the directory of the program data:
+// $XDG_DATA_HOME/expensetracker (~/.local/share by default) on Linux.
+func dataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// stateDir returns the directory of the program state, like logs:
+// $XDG_STATE_HOME/expensetracker (~/.local/state by default) on Linux.
+func stateDir() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// xdgDir returns the program directory inside the XDG base directory set by
+// env, or fallback inside the home directory when unset, on Linux and BSDs.
+// Other systems use the user configuration directory.
+func xdgDir(env, fallback string) (string, error) {
+	if runtime.GOOS == "windows" || runtime.GOOS == "darwin" {
+		dir, err := os.UserConfigDir()
+		if err != nil {
+			return "", err
+		}
+		return filepath.Join(dir, appName), nil
+	}
+
+	// Relative paths are invalid per the specification and ignored.
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, fallback, appName), nil
+}
+
+// exists reports whether a file exists at path.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return !errors.Is(err, fs.ErrNotExist)
+}
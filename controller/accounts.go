@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetAccounts returns every account.
+func (c *Controller) GetAccounts() ([]domain.Account, error) {
+	return c.db.GetAccounts()
+}
+
+// AddAccount adds Account to database if valid.
+func (c *Controller) AddAccount(account domain.Account) error {
+	account, err := validateAccount(account)
+	if err != nil {
+		return err
+	}
+
+	return c.db.InsertAccount(account)
+}
+
+// UpdateAccount updates an existing Account in database if valid.
+func (c *Controller) UpdateAccount(account domain.Account) error {
+	account, err := validateAccount(account)
+	if err != nil {
+		return err
+	}
+
+	return c.db.UpdateAccount(account)
+}
+
+// RemoveAccount removes Account from database if valid id.
+func (c *Controller) RemoveAccount(id int) error {
+	return c.db.DeleteAccount(id)
+}
+
+// CreateAccountData returns an account with every transaction and
+// the running balance after each of them, oldest first.
+func (c *Controller) CreateAccountData(id int) (domain.AccountData, error) {
+	accounts, err := c.db.GetAccounts()
+	if err != nil {
+		return domain.AccountData{}, err
+	}
+
+	data := domain.AccountData{}
+	found := false
+
+	for _, account := range accounts {
+		if account.Id == id {
+			data.Account = account
+			found = true
+		}
+	}
+
+	if !found {
+		return data, fmt.Errorf("Could not find account with id %d", id)
+	}
+
+	transactions, err := c.db.GetAccountTransactions(id)
+	if err != nil {
+		return data, err
+	}
+
+	balance := data.Account.OpeningBalance
+	data.Entries = []domain.BalanceEntry{}
+
+	for _, transaction := range transactions {
+		change := accountChange(transaction, id)
+		balance = balance.Add(change)
+		data.Entries = append(data.Entries, domain.BalanceEntry{
+			Expense: transaction,
+			Change:  change,
+			Balance: balance,
+		})
+	}
+
+	data.Balance = balance
+
+	return data, nil
+}
+
+// accountChange returns how much a transaction changed the balance of
+// an account: income and transfers to it add, expenses and transfers
+// from it subtract.
+func accountChange(transaction domain.Expense, accountId int) domain.Money {
+	switch {
+	case transaction.IsTransfer() && transaction.ToAccountId == accountId:
+		return transaction.Amount
+	case transaction.IsIncome():
+		return transaction.Amount
+	default:
+		return -transaction.Amount
+	}
+}
+
+// validateAccount normalizes the name of an account and checks its type.
+func validateAccount(account domain.Account) (domain.Account, error) {
+	account.Name = strings.Join(strings.Fields(account.Name), " ")
+	if account.Name == "" {
+		return account, errors.New("Account name should not be empty.")
+	}
+
+	switch account.Type {
+	case "":
+		account.Type = domain.Checking
+	case domain.Checking, domain.Savings, domain.CreditCard, domain.Cash:
+	default:
+		return account, errors.New("Account type should be checking, savings, credit card or cash.")
+	}
+
+	return account, nil
+}
+
+// validateTransfer checks that a transfer moves money between two different
+// accounts, and that other transactions have no destination account.
+func validateTransfer(expense domain.Expense) (domain.Expense, error) {
+	if !expense.IsTransfer() {
+		expense.ToAccountId = 0
+		expense.ToAccount = ""
+		return expense, nil
+	}
+
+	if expense.AccountId == 0 || expense.ToAccountId == 0 {
+		return expense, errors.New("Transfer needs an account to take from and one to put into.")
+	}
+
+	if expense.AccountId == expense.ToAccountId {
+		return expense, errors.New("Transfer accounts should be different.")
+	}
+
+	return expense, nil
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// createSavings returns a controller over memory storage with a "Savings"
+// account opened with 100.00 next to "Main", and the id of the account.
+func createSavings(t *testing.T) (*Controller, int) {
+	t.Helper()
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddAccount(domain.Account{Name: "Savings", Type: domain.Savings, OpeningBalance: 10000}))
+
+	accounts, err := c.GetAccounts()
+	check(t, err)
+	for _, account := range accounts {
+		if account.Name == "Savings" {
+			return c, account.Id
+		}
+	}
+
+	t.Fatal("could not find the savings account")
+	return nil, 0
+}
+
+func TestCreateAccountData(t *testing.T) {
+	c, savings := createSavings(t)
+	for _, expense := range []domain.Expense{
+		{Name: "salary", Date: "2023-02-01", Amount: 200000, Kind: domain.KindIncome, AccountId: 1},
+		{Name: "rent", Date: "2023-02-03", Amount: 90000, AccountId: 1},
+		{Name: "save", Date: "2023-02-05", Amount: 50000, Kind: domain.KindTransfer, AccountId: 1, ToAccountId: savings},
+		{Name: "coffee", Date: "2023-02-06", Amount: 250, AccountId: savings},
+	} {
+		check(t, c.AddExpense(expense))
+	}
+
+	for _, test := range []struct {
+		account  int
+		changes  []domain.Money
+		balances []domain.Money
+	}{
+		{1, []domain.Money{200000, -90000, -50000}, []domain.Money{200000, 110000, 60000}},
+		{savings, []domain.Money{50000, -250}, []domain.Money{60000, 59750}},
+	} {
+		data, err := c.CreateAccountData(test.account)
+		check(t, err)
+
+		if len(data.Entries) != len(test.changes) {
+			t.Fatalf("got %d entries for account %d, want %d", len(data.Entries), test.account, len(test.changes))
+		}
+		for i, entry := range data.Entries {
+			if entry.Change != test.changes[i] || entry.Balance != test.balances[i] {
+				t.Errorf("got entry %q of account %d changing %s to %s, want %s to %s",
+					entry.Expense.Name, test.account, entry.Change, entry.Balance, test.changes[i], test.balances[i])
+			}
+		}
+		if data.Balance != test.balances[len(test.balances)-1] {
+			t.Errorf("got balance %s for account %d, want %s", data.Balance, test.account, test.balances[len(test.balances)-1])
+		}
+	}
+
+	if _, err := c.CreateAccountData(99); err == nil {
+		t.Error("got data of an account that does not exist")
+	}
+}
+
+func TestValidateTransfer(t *testing.T) {
+	for _, test := range []struct {
+		name    string
+		expense domain.Expense
+		valid   bool
+	}{
+		{"transfer", domain.Expense{Kind: domain.KindTransfer, AccountId: 1, ToAccountId: 2}, true},
+		{"no source", domain.Expense{Kind: domain.KindTransfer, ToAccountId: 2}, false},
+		{"no destination", domain.Expense{Kind: domain.KindTransfer, AccountId: 1}, false},
+		{"same account", domain.Expense{Kind: domain.KindTransfer, AccountId: 1, ToAccountId: 1}, false},
+		{"expense", domain.Expense{Kind: domain.KindExpense, AccountId: 1, ToAccountId: 2, ToAccount: "Savings"}, true},
+	} {
+		expense, err := validateTransfer(test.expense)
+		if (err == nil) != test.valid {
+			t.Errorf("%s: got error %v, want valid %v", test.name, err, test.valid)
+		}
+		if !expense.IsTransfer() && (expense.ToAccountId != 0 || expense.ToAccount != "") {
+			t.Errorf("%s: kept destination account %d %q", test.name, expense.ToAccountId, expense.ToAccount)
+		}
+	}
+}
+
+func TestTransfersOutOfTotals(t *testing.T) {
+	c, savings := createSavings(t)
+	check(t, c.AddCategory(domain.Category{Name: "Home"}))
+	check(t, c.InsertBudgetMonth("1000.00", "2023-02"))
+	for _, expense := range []domain.Expense{
+		{Name: "salary", Date: "2023-02-01", Amount: 200000, Kind: domain.KindIncome, AccountId: 1},
+		{Name: "rent", Date: "2023-02-03", Amount: 90000, Category: "Home", AccountId: 1},
+		{Name: "save", Date: "2023-02-05", Amount: 50000, Kind: domain.KindTransfer, Category: "Home", AccountId: 1, ToAccountId: savings},
+	} {
+		check(t, c.AddExpense(expense))
+	}
+
+	data, err := c.CreateMonthData(2023, time.February)
+	check(t, err)
+
+	if len(data.Expenses) != 3 {
+		t.Errorf("got %d transactions, want 3 with the transfer", len(data.Expenses))
+	}
+	if data.TotalSpendings != 90000 || data.TotalIncome != 200000 {
+		t.Errorf("got spendings %s and income %s, want 900.00 and 2000.00", data.TotalSpendings, data.TotalIncome)
+	}
+	if data.MoneyLeft != 10000 || data.NetCashFlow != 110000 {
+		t.Errorf("got %s left and net %s, want 100.00 and 1100.00", data.MoneyLeft, data.NetCashFlow)
+	}
+	spent := map[string]domain.Money{}
+	for _, category := range data.Categories {
+		spent[category.Category] = category.Spent
+	}
+	if spent["Home"] != 90000 {
+		t.Errorf("got %s spent in Home, want 900.00", spent["Home"])
+	}
+}
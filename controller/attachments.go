@@ -0,0 +1,96 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// maxAttachmentSize is the largest file that can be attached, in bytes.
+const maxAttachmentSize = 25 << 20
+
+// attachmentExtensions maps the accepted attachment types
+// to the extension of their stored file.
+var attachmentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/bmp":       ".bmp",
+	"image/gif":       ".gif",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+}
+
+// GetAttachments returns the attachments of an expense.
+func (c *Controller) GetAttachments(expenseId int) ([]domain.Attachment, error) {
+	return c.db.GetAttachments(expenseId)
+}
+
+// AttachFile attaches the image or PDF found at path to an expense.
+// Its content is stored under its sha256 hash so attaching the same
+// file twice keeps a single copy.
+func (c *Controller) AttachFile(expenseId int, path string) error {
+	if expenseId == 0 {
+		return errors.New("Save the expense before attaching files.")
+	}
+
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return errors.New("File path should not be empty.")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("Could not read file: %w", err)
+	}
+
+	if info.IsDir() {
+		return errors.New("Only files can be attached.")
+	}
+
+	if info.Size() > maxAttachmentSize {
+		return fmt.Errorf("File should not be larger than %d MB.", maxAttachmentSize>>20)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("Could not read file: %w", err)
+	}
+
+	mimeType := http.DetectContentType(content)
+	extension, ok := attachmentExtensions[mimeType]
+	if !ok {
+		return errors.New("Only images and PDF files can be attached.")
+	}
+
+	sum := sha256.Sum256(content)
+	hash := hex.EncodeToString(sum[:])
+
+	return c.db.InsertAttachment(domain.Attachment{
+		ExpenseId: expenseId,
+		Name:      filepath.Base(path),
+		File:      hash + extension,
+		Hash:      hash,
+		MimeType:  mimeType,
+		Size:      int64(len(content)),
+		AddedAt:   c.now().Format(time.RFC3339),
+	}, content)
+}
+
+// RemoveAttachment removes an attachment, its file being removed
+// once no attachment refers to it.
+func (c *Controller) RemoveAttachment(id int) error {
+	return c.db.DeleteAttachment(id)
+}
+
+// AttachmentPath returns the location of the file of an attachment.
+func (c *Controller) AttachmentPath(attachment domain.Attachment) string {
+	return c.db.AttachmentPath(attachment.File)
+}
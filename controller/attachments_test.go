@@ -0,0 +1,109 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// pngContent is the start of a PNG file, enough to be detected as one.
+var pngContent = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+// writeFile writes content to a temporary file named name
+// and returns its path.
+func writeFile(t *testing.T, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	check(t, os.WriteFile(path, content, 0o600))
+	return path
+}
+
+func TestAttachFile(t *testing.T) {
+	s := memory.CreateStorage()
+	c := CreateController(s)
+	check(t, c.AddExpense(domain.Expense{Name: "lunch", Date: "2023-02-01", Amount: 1250}))
+	check(t, c.AddExpense(domain.Expense{Name: "dinner", Date: "2023-02-02", Amount: 2000}))
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	lunch, dinner := expenses[0].Id, expenses[1].Id
+
+	check(t, c.AttachFile(lunch, writeFile(t, "receipt.png", pngContent)))
+	check(t, c.AttachFile(dinner, writeFile(t, "copy.png", pngContent)))
+
+	lunchAttachments, err := c.GetAttachments(lunch)
+	check(t, err)
+	dinnerAttachments, err := c.GetAttachments(dinner)
+	check(t, err)
+	if len(lunchAttachments) != 1 || len(dinnerAttachments) != 1 {
+		t.Fatalf("got %d and %d attachments, want one each", len(lunchAttachments), len(dinnerAttachments))
+	}
+
+	attachment := lunchAttachments[0]
+	if attachment.Name != "receipt.png" || attachment.MimeType != "image/png" || attachment.File != attachment.Hash+".png" {
+		t.Errorf("got attachment %+v", attachment)
+	}
+	if dinnerAttachments[0].File != attachment.File {
+		t.Errorf("got files %q and %q for the same content, want one", attachment.File, dinnerAttachments[0].File)
+	}
+
+	for _, test := range []struct {
+		name      string
+		expenseId int
+		path      string
+	}{
+		{"unsaved expense", 0, writeFile(t, "receipt.png", pngContent)},
+		{"empty path", lunch, " "},
+		{"missing file", lunch, filepath.Join(t.TempDir(), "missing.png")},
+		{"directory", lunch, t.TempDir()},
+		{"text file", lunch, writeFile(t, "notes.txt", []byte("not an image"))},
+	} {
+		if err := c.AttachFile(test.expenseId, test.path); err == nil {
+			t.Errorf("%s: attached", test.name)
+		}
+	}
+}
+
+func TestSharedAttachmentCleanup(t *testing.T) {
+	s := memory.CreateStorage()
+	c := CreateController(s)
+	check(t, c.AddExpense(domain.Expense{Name: "lunch", Date: "2023-02-01", Amount: 1250}))
+	check(t, c.AddExpense(domain.Expense{Name: "dinner", Date: "2023-02-02", Amount: 2000}))
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	lunch, dinner := expenses[0].Id, expenses[1].Id
+
+	path := writeFile(t, "receipt.png", pngContent)
+	check(t, c.AttachFile(lunch, path))
+	check(t, c.AttachFile(lunch, path))
+	check(t, c.AttachFile(dinner, path))
+
+	attachments, err := c.GetAttachments(lunch)
+	check(t, err)
+	file := attachments[0].File
+
+	stored := func() bool {
+		_, ok := s.AttachmentContent(file)
+		return ok
+	}
+
+	check(t, c.RemoveAttachment(attachments[0].Id))
+	if !stored() {
+		t.Fatal("removed a file another attachment uses")
+	}
+
+	check(t, c.RemoveExpense(lunch))
+	check(t, c.PurgeExpense(lunch))
+	if !stored() {
+		t.Fatal("purged a file another expense uses")
+	}
+
+	dinnerAttachments, err := c.GetAttachments(dinner)
+	check(t, err)
+	check(t, c.RemoveAttachment(dinnerAttachments[0].Id))
+	if stored() {
+		t.Error("kept a file no attachment uses")
+	}
+}
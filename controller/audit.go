@@ -0,0 +1,424 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// expenseRecord is how an expense is written in the audit log,
+// its id being the key of the entry.
+type expenseRecord struct {
+	Date      string        `json:"date"`
+	Name      string        `json:"name"`
+	Kind      domain.Kind   `json:"kind"`
+	Amount    string        `json:"amount"`
+	Category  string        `json:"category,omitempty"`
+	Account   string        `json:"account,omitempty"`
+	ToAccount string        `json:"to_account,omitempty"`
+	Payee     string        `json:"payee,omitempty"`
+	Tags      []string      `json:"tags,omitempty"`
+	Splits    []splitRecord `json:"splits,omitempty"`
+	ImportId  string        `json:"import_id,omitempty"`
+	DeletedAt string        `json:"deleted_at,omitempty"`
+}
+
+// splitRecord is how a split line of an expense is written in the audit log.
+type splitRecord struct {
+	Category string `json:"category,omitempty"`
+	Amount   string `json:"amount"`
+	Note     string `json:"note,omitempty"`
+}
+
+// expenseRecordOf returns the record of an expense in the audit log.
+func expenseRecordOf(expense domain.Expense) expenseRecord {
+	record := expenseRecord{
+		Date:      expense.Date,
+		Name:      expense.Name,
+		Kind:      expense.Kind,
+		Amount:    expense.Amount.String(),
+		Category:  expense.Category,
+		Account:   expense.Account,
+		ToAccount: expense.ToAccount,
+		Payee:     expense.Payee,
+		Tags:      expense.Tags,
+		ImportId:  expense.ImportId,
+		DeletedAt: expense.DeletedAt,
+	}
+
+	for _, split := range expense.Splits {
+		record.Splits = append(record.Splits, splitRecord{
+			Category: split.Category,
+			Amount:   split.Amount.String(),
+			Note:     split.Note,
+		})
+	}
+
+	return record
+}
+
+// budgetRecord is how a budget is written in the audit log.
+type budgetRecord struct {
+	Category string `json:"category,omitempty"`
+	Amount   string `json:"amount"`
+}
+
+// SetUser sets who makes the following changes, as written
+// in the audit log. Empty means unknown.
+func (c *Controller) SetUser(user string) {
+	c.user = user
+}
+
+// GetAuditLog returns the audit entries matching a query, the latest first.
+func (c *Controller) GetAuditLog(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	if query.Limit < 0 {
+		return nil, errors.New("Audit query limit should not be negative.")
+	}
+
+	return c.db.GetAuditEntries(query)
+}
+
+// GetExpenseHistory returns the changes made to an expense, the latest first.
+func (c *Controller) GetExpenseHistory(id int) ([]domain.AuditEntry, error) {
+	return c.db.GetAuditEntries(domain.AuditQuery{Entity: domain.AuditExpense, Key: strconv.Itoa(id)})
+}
+
+// GetBudgetHistory returns the changes made to the budget and the category
+// budgets of a month (YYYY-MM or "default"), the latest first.
+func (c *Controller) GetBudgetHistory(yearMonth string) ([]domain.AuditEntry, error) {
+	if yearMonth != "default" {
+		formatted, err := formatDate(yearMonth)
+		if err != nil {
+			return nil, err
+		}
+		yearMonth = formatted
+	}
+
+	entries := []domain.AuditEntry{}
+
+	for _, entity := range []domain.AuditEntity{domain.AuditBudget, domain.AuditCategoryBudget} {
+		list, err := c.db.GetAuditEntries(domain.AuditQuery{Entity: entity, Key: yearMonth})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, list...)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Id > entries[j].Id
+	})
+
+	return entries, nil
+}
+
+// audit appends a change to the audit log, before and after being
+// marshalled to JSON unless nil.
+func (c *Controller) audit(action domain.AuditAction, entity domain.AuditEntity, key string, before, after any) error {
+	entry, err := c.auditEntry(action, entity, key, before, after)
+	if err != nil {
+		return err
+	}
+
+	if err := c.db.InsertAuditEntries([]domain.AuditEntry{entry}); err != nil {
+		return fmt.Errorf("Could not record %s of %s %s in audit log: %w", action, entity, key, err)
+	}
+
+	return nil
+}
+
+// auditEntry returns the audit entry of a change, before and after being
+// marshalled to JSON unless nil.
+func (c *Controller) auditEntry(action domain.AuditAction, entity domain.AuditEntity, key string, before, after any) (domain.AuditEntry, error) {
+	entry := domain.AuditEntry{
+		Timestamp: c.now().UTC().Format(time.RFC3339),
+		User:      c.user,
+		Action:    action,
+		Entity:    entity,
+		Key:       key,
+	}
+
+	for _, record := range []struct {
+		value  any
+		target *string
+	}{{before, &entry.Before}, {after, &entry.After}} {
+		if record.value == nil {
+			continue
+		}
+		content, err := json.Marshal(record.value)
+		if err != nil {
+			return entry, fmt.Errorf("Could not encode audit entry: %w", err)
+		}
+		*record.target = string(content)
+	}
+
+	return entry, nil
+}
+
+// rollBack undoes a change that could not be recorded in the audit log
+// and returns why, along with why it could not be undone if so.
+func rollBack(err error, undo func() error) error {
+	if undoErr := undo(); undoErr != nil {
+		return fmt.Errorf("%w (could not undo the change: %v)", err, undoErr)
+	}
+
+	return err
+}
+
+// findExpense returns the expense with an id, even in the trash.
+func (c *Controller) findExpense(id int) (domain.Expense, error) {
+	expense, ok, err := c.db.GetExpenseWithId(id)
+	if err != nil {
+		return expense, err
+	}
+
+	if !ok {
+		return expense, fmt.Errorf("Could not find expense with id %d", id)
+	}
+
+	return expense, nil
+}
+
+// insertExpense inserts an expense and records it in the audit log.
+func (c *Controller) insertExpense(expense domain.Expense) (int, error) {
+	id, err := c.db.InsertExpense(expense)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := c.auditInserts([]int{id}); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// insertExpenses inserts expenses, all of them or none,
+// and records them in the audit log.
+func (c *Controller) insertExpenses(expenses []domain.Expense) ([]int, error) {
+	ids, err := c.db.InsertExpenses(expenses)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.auditInserts(ids); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// auditInserts records expenses just inserted in the audit log, all of
+// them or none, and discards them when they cannot be recorded.
+func (c *Controller) auditInserts(ids []int) error {
+	discard := func() error { return c.db.DiscardExpenses(ids) }
+
+	entries := []domain.AuditEntry{}
+	for _, id := range ids {
+		after, err := c.findExpense(id)
+		if err != nil {
+			return rollBack(err, discard)
+		}
+
+		entry, err := c.auditEntry(domain.AuditInsert, domain.AuditExpense, strconv.Itoa(id), nil, expenseRecordOf(after))
+		if err != nil {
+			return rollBack(err, discard)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := c.db.InsertAuditEntries(entries); err != nil {
+		return rollBack(fmt.Errorf("Could not record new expenses in audit log: %w", err), discard)
+	}
+
+	return nil
+}
+
+// updateExpense replaces an expense as it is before with after
+// and records the change in the audit log, or puts it back as before.
+func (c *Controller) updateExpense(before, after domain.Expense) error {
+	if err := c.db.UpdateExpense(after); err != nil {
+		return err
+	}
+
+	updated, err := c.findExpense(after.Id)
+	if err == nil {
+		err = c.audit(domain.AuditUpdate, domain.AuditExpense, strconv.Itoa(after.Id), expenseRecordOf(before), expenseRecordOf(updated))
+	}
+	if err != nil {
+		return rollBack(err, func() error { return c.db.UpdateExpense(before) })
+	}
+
+	return nil
+}
+
+// changeExpense applies change to the expense with an id and records it
+// in the audit log as action, or applies undo when it cannot be recorded.
+func (c *Controller) changeExpense(action domain.AuditAction, id int, change, undo func(before domain.Expense) error) error {
+	before, err := c.findExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if err := change(before); err != nil {
+		return err
+	}
+
+	after, err := c.findExpense(id)
+	if err == nil {
+		err = c.audit(action, domain.AuditExpense, strconv.Itoa(id), expenseRecordOf(before), expenseRecordOf(after))
+	}
+	if err != nil {
+		return rollBack(err, func() error { return undo(before) })
+	}
+
+	return nil
+}
+
+// deleteExpense moves an expense to the trash.
+func (c *Controller) deleteExpense(id int) error {
+	return c.changeExpense(domain.AuditDelete, id,
+		func(domain.Expense) error { return c.db.DeleteExpense(id, c.now().UTC().Format(time.RFC3339)) },
+		func(domain.Expense) error { return c.db.RestoreExpense(id) },
+	)
+}
+
+// restoreExpense takes an expense out of the trash.
+func (c *Controller) restoreExpense(id int) error {
+	return c.changeExpense(domain.AuditRestore, id,
+		func(domain.Expense) error { return c.db.RestoreExpense(id) },
+		func(before domain.Expense) error { return c.db.DeleteExpense(id, before.DeletedAt) },
+	)
+}
+
+// purgeExpense permanently deletes an expense in the trash. Since that
+// cannot be undone, it is recorded in the audit log first.
+func (c *Controller) purgeExpense(id int) error {
+	before, err := c.findExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if before.DeletedAt == "" {
+		return fmt.Errorf("Could not find expense with id %d in the trash", id)
+	}
+
+	if err := c.audit(domain.AuditPurge, domain.AuditExpense, strconv.Itoa(id), expenseRecordOf(before), nil); err != nil {
+		return err
+	}
+
+	return c.db.PurgeExpense(id)
+}
+
+// setBudget sets the budget of a month (YYYY-MM) or the default budget,
+// or removes the budget of a month when set is false, and records the
+// change in the audit log.
+func (c *Controller) setBudget(date string, amount domain.Money, set bool) error {
+	var before, after any
+
+	var budget domain.Money
+	var err error
+	ok := true
+	if date == "default" {
+		budget, err = c.db.GetDefaultBudget()
+	} else {
+		budget, ok, err = c.db.GetBudgetWithYearMonth(date)
+	}
+	if err != nil {
+		return err
+	}
+	if ok {
+		before = budgetRecord{Amount: budget.String()}
+	}
+
+	switch {
+	case date == "default":
+		err = c.db.UpdateDefaultBudget(amount)
+	case set:
+		err = c.db.InsertBudget(amount, date)
+	default:
+		err = c.db.DeleteBudget(date)
+	}
+	if err != nil {
+		return err
+	}
+
+	action := domain.AuditUpdate
+	switch {
+	case !set:
+		action = domain.AuditDelete
+	case before == nil:
+		action = domain.AuditInsert
+	}
+
+	if set {
+		after = budgetRecord{Amount: amount.String()}
+	}
+
+	if err := c.audit(action, domain.AuditBudget, date, before, after); err != nil {
+		return rollBack(err, func() error {
+			switch {
+			case date == "default":
+				return c.db.UpdateDefaultBudget(budget)
+			case ok:
+				return c.db.InsertBudget(budget, date)
+			default:
+				return c.db.DeleteBudget(date)
+			}
+		})
+	}
+
+	return nil
+}
+
+// setCategoryBudget sets the budget of a category for a month (YYYY-MM)
+// or by default, or deletes it when not set, and records the change
+// in the audit log.
+func (c *Controller) setCategoryBudget(category domain.Category, date string, amount domain.Money, set bool) error {
+	budgets, err := c.db.GetCategoryBudgets(date)
+	if err != nil {
+		return err
+	}
+
+	if set {
+		err = c.db.InsertCategoryBudget(category.Id, amount, date)
+	} else {
+		err = c.db.DeleteCategoryBudget(category.Id, date)
+	}
+	if err != nil {
+		return err
+	}
+
+	var before, after any
+	budget, ok := budgets[category.Id]
+	if ok {
+		before = budgetRecord{Category: category.Name, Amount: budget.String()}
+	}
+
+	action := domain.AuditUpdate
+	switch {
+	case !set:
+		action = domain.AuditDelete
+	case !ok:
+		action = domain.AuditInsert
+	}
+
+	if set {
+		after = budgetRecord{Category: category.Name, Amount: amount.String()}
+	}
+
+	if err := c.audit(action, domain.AuditCategoryBudget, date, before, after); err != nil {
+		return rollBack(err, func() error {
+			if ok {
+				return c.db.InsertCategoryBudget(category.Id, budget, date)
+			}
+			return c.db.DeleteCategoryBudget(category.Id, date)
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// failingAudit is a storage whose audit log cannot be written to
+// while failing is set.
+type failingAudit struct {
+	*memory.Storage
+	failing bool
+}
+
+func (s *failingAudit) InsertAuditEntries(entries []domain.AuditEntry) error {
+	if s.failing {
+		return errors.New("disk full")
+	}
+	return s.Storage.InsertAuditEntries(entries)
+}
+
+func TestExpenseAuditRecord(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	check(t, c.AddExpense(domain.Expense{
+		Name:     "lunch",
+		Date:     "2023-02-01",
+		Amount:   1250,
+		Category: "Food",
+		Tags:     []string{"work"},
+		Splits:   []domain.Split{{Category: "Food", Amount: 1250, Note: "me"}},
+	}))
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	expense := expenses[0]
+	expense.Amount = 1300
+	expense.Splits = nil
+	check(t, c.UpdateExpense(expense))
+
+	history, err := c.GetExpenseHistory(expense.Id)
+	check(t, err)
+	if len(history) != 2 {
+		t.Fatalf("got %d audit entries, want 2", len(history))
+	}
+
+	decode := func(content string) map[string]any {
+		t.Helper()
+		record := map[string]any{}
+		check(t, json.Unmarshal([]byte(content), &record))
+		return record
+	}
+
+	added := decode(history[1].After)
+	if added["amount"] != "12.50" || added["name"] != "lunch" || added["category"] != "Food" || added["kind"] != "expense" {
+		t.Errorf("got record %v of the added expense", added)
+	}
+	splits, _ := added["splits"].([]any)
+	if len(splits) != 1 || splits[0].(map[string]any)["amount"] != "12.50" {
+		t.Errorf("got splits %v, want one of 12.50", added["splits"])
+	}
+
+	before, after := decode(history[0].Before), decode(history[0].After)
+	if before["amount"] != "12.50" || after["amount"] != "13.00" || after["splits"] != nil {
+		t.Errorf("got update from %v to %v", before, after)
+	}
+}
+
+func TestAuditFailureRollsBack(t *testing.T) {
+	s := &failingAudit{Storage: memory.CreateStorage()}
+	c := CreateController(s)
+	check(t, c.AddExpense(domain.Expense{Name: "lunch", Date: "2023-02-01", Amount: 1250}))
+	check(t, c.InsertBudgetMonth("100.00", "2023-02"))
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	lunch := expenses[0]
+
+	s.failing = true
+
+	if err := c.AddExpense(domain.Expense{Name: "dinner", Date: "2023-02-02", Amount: 2000}); err == nil {
+		t.Error("added an expense without audit entry")
+	}
+
+	renamed := lunch
+	renamed.Name = "brunch"
+	if err := c.UpdateExpense(renamed); err == nil {
+		t.Error("updated an expense without audit entry")
+	}
+
+	if err := c.RemoveExpense(lunch.Id); err == nil {
+		t.Error("deleted an expense without audit entry")
+	}
+
+	if err := c.InsertBudgetMonth("200.00", "2023-02"); err == nil {
+		t.Error("changed a budget without audit entry")
+	}
+	if err := c.UpdateDefaultBudget("50.00"); err == nil {
+		t.Error("changed the default budget without audit entry")
+	}
+
+	expenses, err = c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	if len(expenses) != 1 || expenses[0].Name != "lunch" || expenses[0].DeletedAt != "" {
+		t.Errorf("got expenses %+v, want lunch unchanged", expenses)
+	}
+
+	if budget, _, err := s.GetBudgetWithYearMonth("2023-02"); err != nil || budget != 10000 {
+		t.Errorf("got budget %s (%v), want 100.00", budget, err)
+	}
+	if budget, err := s.GetDefaultBudget(); err != nil || budget != 0 {
+		t.Errorf("got default budget %s (%v), want 0.00", budget, err)
+	}
+
+	s.failing = false
+	history, err := c.GetExpenseHistory(lunch.Id)
+	check(t, err)
+	if len(history) != 1 || history[0].Key != strconv.Itoa(lunch.Id) {
+		t.Errorf("got history %+v, want only the insert", history)
+	}
+}
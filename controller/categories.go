@@ -0,0 +1,327 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// GetCategories returns every category, archived ones included.
+func (c *Controller) GetCategories() ([]domain.Category, error) {
+	return c.db.GetCategories()
+}
+
+// AddCategory adds Category to database if valid.
+func (c *Controller) AddCategory(category domain.Category) error {
+	category.Id = 0
+
+	category, err := c.validateCategory(category)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.db.InsertCategory(category)
+	return err
+}
+
+// UpdateCategory updates an existing Category in database if valid.
+func (c *Controller) UpdateCategory(category domain.Category) error {
+	category, err := c.validateCategory(category)
+	if err != nil {
+		return err
+	}
+
+	return c.db.UpdateCategory(category)
+}
+
+// RemoveCategory removes Category from database if valid id.
+// Its expenses are left without category.
+func (c *Controller) RemoveCategory(id int) error {
+	return c.db.DeleteCategory(id)
+}
+
+// validateCategory normalizes the name of a category and checks that the
+// name is free, the color is a #rrggbb hex value and that the parent exists
+// without creating a cycle.
+func (c *Controller) validateCategory(category domain.Category) (domain.Category, error) {
+	category.Name = domain.NormalizeCategoryName(category.Name)
+	if category.Name == "" {
+		return category, errors.New("Category name should not be empty.")
+	}
+
+	if category.Color != "" && !colorPattern.MatchString(category.Color) {
+		return category, errors.New("Color should be like #a1b2c3.")
+	}
+
+	existing, found, err := c.db.GetCategoryWithName(category.Name)
+	if err != nil {
+		return category, err
+	}
+
+	if found && existing.Id != category.Id {
+		return category, fmt.Errorf("Category %q already exists.", existing.Name)
+	}
+
+	if category.ParentId == 0 {
+		return category, nil
+	}
+
+	categories, err := c.db.GetCategories()
+	if err != nil {
+		return category, err
+	}
+
+	parents := map[int]int{}
+	for _, other := range categories {
+		parents[other.Id] = other.ParentId
+	}
+
+	if _, ok := parents[category.ParentId]; !ok {
+		return category, errors.New("Parent category does not exist.")
+	}
+
+	for id := category.ParentId; id != 0; id = parents[id] {
+		if id == category.Id {
+			return category, errors.New("Category cannot be its own parent.")
+		}
+	}
+
+	return category, nil
+}
+
+// resolveCategory sets the CategoryId of an expense from its Category name
+// when no id was given, creating the category if it doesn't exist yet.
+func (c *Controller) resolveCategory(expense domain.Expense) (domain.Expense, error) {
+	if expense.CategoryId != 0 {
+		return expense, nil
+	}
+
+	id, name, err := c.resolveCategoryName(expense.Category)
+	if err != nil {
+		return expense, err
+	}
+
+	expense.CategoryId = id
+	expense.Category = name
+
+	return expense, nil
+}
+
+// resolveCategoryName returns the id and name of the category named name,
+// creating it if it doesn't exist yet. An empty name returns id 0.
+func (c *Controller) resolveCategoryName(name string) (int, string, error) {
+	name = domain.NormalizeCategoryName(name)
+	if name == "" {
+		return 0, "", nil
+	}
+
+	category, found, err := c.db.GetCategoryWithName(name)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !found {
+		category.Name = name
+		category.Id, err = c.db.InsertCategory(category)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return category.Id, category.Name, nil
+}
+
+// InsertCategoryBudgetMonth adds budget amount of an existing category
+// to database for a month if valid.
+func (c *Controller) InsertCategoryBudgetMonth(categoryName, amount, date string) error {
+	category, err := c.findCategory(categoryName)
+	if err != nil {
+		return err
+	}
+
+	money, err := domain.ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+
+	formattedDate, err := formatDate(date)
+	if err != nil {
+		return err
+	}
+
+	return c.recordCategoryBudget(category, formattedDate, money, false)
+}
+
+// InsertCategoryBudgetMonthAndDefault sets both the budget of an existing
+// category for a month and its default budget to amount, as a single
+// change to undo.
+func (c *Controller) InsertCategoryBudgetMonthAndDefault(categoryName, amount, date string) error {
+	category, err := c.findCategory(categoryName)
+	if err != nil {
+		return err
+	}
+
+	money, err := domain.ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+
+	formattedDate, err := formatDate(date)
+	if err != nil {
+		return err
+	}
+
+	return c.recordCategoryBudget(category, formattedDate, money, true)
+}
+
+// UpdateDefaultCategoryBudget updates the default monthly budget amount
+// of an existing category.
+func (c *Controller) UpdateDefaultCategoryBudget(categoryName, amount string) error {
+	category, err := c.findCategory(categoryName)
+	if err != nil {
+		return err
+	}
+
+	money, err := domain.ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+
+	return c.recordCategoryBudget(category, "default", money, false)
+}
+
+// recordCategoryBudget sets the budget of a category for a month (YYYY-MM)
+// or by default, and its default budget too when asDefault, and records
+// it in the history.
+func (c *Controller) recordCategoryBudget(category domain.Category, date string, amount domain.Money, asDefault bool) error {
+	budgets, err := c.db.GetCategoryBudgets(date)
+	if err != nil {
+		return err
+	}
+	before, ok := budgets[category.Id]
+
+	defaults, err := c.db.GetCategoryBudgets("default")
+	if err != nil {
+		return err
+	}
+	defaultBefore, hasDefault := defaults[category.Id]
+
+	description := fmt.Sprintf("budget of %s for %s set to %s", category.Name, date, amount)
+	if date == "default" {
+		description = fmt.Sprintf("default budget of %s set to %s", category.Name, amount)
+	}
+	undo := func() error { return c.setCategoryBudget(category, date, before, ok) }
+	redo := func() error { return c.setCategoryBudget(category, date, amount, true) }
+
+	if asDefault && date != "default" {
+		description += " and by default"
+		undo = inOrder(func() error { return c.setCategoryBudget(category, "default", defaultBefore, hasDefault) }, undo)
+		redo = inOrder(redo, func() error { return c.setCategoryBudget(category, "default", amount, true) })
+	}
+
+	if err := redo(); err != nil {
+		return rollBack(err, undo)
+	}
+
+	c.record(description, undo, redo)
+
+	return nil
+}
+
+// findCategory returns the existing category named name.
+func (c *Controller) findCategory(name string) (domain.Category, error) {
+	category, found, err := c.db.GetCategoryWithName(domain.NormalizeCategoryName(name))
+	if err != nil {
+		return category, err
+	}
+
+	if !found {
+		return category, fmt.Errorf("Category %q does not exist.", name)
+	}
+
+	return category, nil
+}
+
+// getCategoryBudgetsForYearMonth returns the budget of every category for
+// a month, falling back to the default budget of the category.
+func (c *Controller) getCategoryBudgetsForYearMonth(yearMonth string) (map[int]domain.Money, error) {
+	budgets, err := c.db.GetCategoryBudgets("default")
+	if err != nil {
+		return nil, err
+	}
+
+	monthBudgets, err := c.db.GetCategoryBudgets(yearMonth)
+	if err != nil {
+		return nil, err
+	}
+
+	for categoryId, amount := range monthBudgets {
+		budgets[categoryId] = amount
+	}
+
+	return budgets, nil
+}
+
+// calculateCategoryData returns the budget, spending and remaining money of
+// every category having a budget or spending, ordered like categories.
+// Income and transfers do not count as spending.
+// The spending of a category includes the spending of its descendants,
+// split expenses counting each split line in its own category.
+func calculateCategoryData(expenses []domain.Expense, categories []domain.Category, budgets map[int]domain.Money) []domain.CategoryData {
+	parents := map[int]int{}
+	for _, category := range categories {
+		parents[category.Id] = category.ParentId
+	}
+
+	spent := map[int]domain.Money{}
+	for _, expense := range expenses {
+		if !expense.IsSpending() {
+			continue
+		}
+
+		for _, share := range expense.CategoryAmounts() {
+			if share.CategoryId == 0 {
+				spent[0] = spent[0].Add(share.Amount)
+				continue
+			}
+
+			// Walk up the hierarchy, guarding against cycles.
+			seen := map[int]bool{}
+			for id := share.CategoryId; id != 0 && !seen[id]; id = parents[id] {
+				seen[id] = true
+				spent[id] = spent[id].Add(share.Amount)
+			}
+		}
+	}
+
+	list := []domain.CategoryData{}
+
+	for _, category := range categories {
+		budget, hasBudget := budgets[category.Id]
+		if !hasBudget && spent[category.Id] == 0 {
+			continue
+		}
+
+		list = append(list, domain.CategoryData{
+			CategoryId: category.Id,
+			Category:   category.Name,
+			Budget:     budget,
+			Spent:      spent[category.Id],
+			Remaining:  budget.Sub(spent[category.Id]),
+		})
+	}
+
+	if spent[0] != 0 {
+		list = append(list, domain.CategoryData{
+			Category:  "Uncategorized",
+			Spent:     spent[0],
+			Remaining: -spent[0],
+		})
+	}
+
+	return list
+}
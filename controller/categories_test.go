@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestCategoryBudgetUndo(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	food, err := c.findCategory("Food")
+	check(t, err)
+
+	check(t, c.UpdateDefaultCategoryBudget("Food", "300"))
+	check(t, c.InsertCategoryBudgetMonth("Food", "250", "2023-02"))
+	check(t, c.InsertCategoryBudgetMonth("Food", "200", "2023-02"))
+
+	budgets := func(date string) map[int]domain.Money {
+		t.Helper()
+		budgets, err := c.db.GetCategoryBudgets(date)
+		check(t, err)
+		return budgets
+	}
+
+	action, err := c.Undo()
+	check(t, err)
+	if action.Description != "budget of Food for 2023-02 set to 200.00" {
+		t.Errorf("undid %q", action.Description)
+	}
+	if got := budgets("2023-02"); !reflect.DeepEqual(got, map[int]domain.Money{food.Id: 25000}) {
+		t.Errorf("got %v after undo, want the previous month budget", got)
+	}
+
+	_, err = c.Undo()
+	check(t, err)
+	if got := budgets("2023-02"); len(got) != 0 {
+		t.Errorf("got %v after undo, want no month budget", got)
+	}
+
+	action, err = c.Undo()
+	check(t, err)
+	if action.Description != "default budget of Food set to 300.00" {
+		t.Errorf("undid %q", action.Description)
+	}
+	if got := budgets("default"); len(got) != 0 {
+		t.Errorf("got %v after undo, want no default budget", got)
+	}
+
+	_, err = c.Redo()
+	check(t, err)
+	if got := budgets("default"); !reflect.DeepEqual(got, map[int]domain.Money{food.Id: 30000}) {
+		t.Errorf("got %v after redo, want the default budget", got)
+	}
+}
@@ -12,13 +12,16 @@ import (
 )
 
 type Controller struct {
-	db domain.Storage
+	db      domain.Storage
+	now     func() time.Time
+	history history
+	user    string
 }
 
 // CreateController returns pointer to Controller struct
 // which contains Storage interface.
 func CreateController(db domain.Storage) *Controller {
-	return &Controller{db: db}
+	return &Controller{db: db, now: time.Now}
 }
 
 // splitDate takes in a date string (YYYY-MM-DD | YYYY-MM) and
@@ -47,6 +50,10 @@ func formatDate(dateString string) (string, error) {
 	splittedDate := splitDate(dateString)
 	splittedNumberDate := []int{}
 
+	if len(splittedDate) < 2 || len(splittedDate) > 3 {
+		return "", errors.New("Date should be YYYY-MM-DD or YYYY-MM.")
+	}
+
 	for i := range splittedDate {
 		digit, err := strconv.Atoi(splittedDate[i])
 		if err != nil {
@@ -83,70 +90,326 @@ func formatDate(dateString string) (string, error) {
 	return date, nil
 }
 
-// AddExpense adds Expense to database if valid.
+// formatDateAs validate and format a full date written in order, like
+// 31/01/2023 in DateDMY, and returns a new date string. Its separators may
+// be missing (20230131), the year should have four digits or two taken as
+// 20YY and anything after a space or a T, like a time, is ignored.
+func formatDateAs(dateString string, order domain.DateOrder) (string, error) {
+	dateString = strings.TrimSpace(dateString)
+	if i := strings.IndexAny(dateString, " T"); i > 0 {
+		dateString = dateString[:i]
+	}
+
+	splittedDate := splitDate(dateString)
+
+	if len(splittedDate) == 1 && len(dateString) == 8 {
+		if order == domain.DateYMD {
+			splittedDate = []string{dateString[:4], dateString[4:6], dateString[6:]}
+		} else {
+			splittedDate = []string{dateString[:2], dateString[2:4], dateString[4:]}
+		}
+	}
+
+	if len(splittedDate) != 3 {
+		return "", fmt.Errorf("Date should be %s.", order)
+	}
+
+	switch order {
+	case domain.DateYMD:
+	case domain.DateDMY:
+		splittedDate = []string{splittedDate[2], splittedDate[1], splittedDate[0]}
+	case domain.DateMDY:
+		splittedDate = []string{splittedDate[2], splittedDate[0], splittedDate[1]}
+	default:
+		return "", fmt.Errorf("Date order should be %s, %s or %s.", domain.DateYMD, domain.DateDMY, domain.DateMDY)
+	}
+
+	switch len(splittedDate[0]) {
+	case 2:
+		splittedDate[0] = "20" + splittedDate[0]
+	case 4:
+	default:
+		return "", fmt.Errorf("Date should be %s with a year of 4 or 2 digits.", order)
+	}
+
+	return formatDate(strings.Join(splittedDate, "-"))
+}
+
+// AddExpense adds Expense to database if valid, after filling its
+// category, payee and tags from the categorization rules.
 func (c *Controller) AddExpense(expense domain.Expense) error {
-	date, err := formatDate(expense.Date)
+	expense.Id = 0
+
+	expense, err := c.autoCategorize(expense)
 	if err != nil {
 		return err
 	}
 
+	expense, err = c.validateExpense(expense)
+	if err != nil {
+		return err
+	}
+
+	id, err := c.insertExpense(expense)
+	if id != 0 {
+		c.record(
+			fmt.Sprintf("add %q", expense.Name),
+			func() error { return c.deleteExpense(id) },
+			func() error { return c.restoreExpense(id) },
+		)
+	}
+
+	return err
+}
+
+// UpdateExpense updates an existing Expense in database if valid.
+func (c *Controller) UpdateExpense(expense domain.Expense) error {
+	expense, err := c.validateExpense(expense)
+	if err != nil {
+		return err
+	}
+
+	before, err := c.findExpense(expense.Id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.updateExpense(before, expense); err != nil {
+		return err
+	}
+
+	c.record(
+		fmt.Sprintf("edit %q", expense.Name),
+		func() error { return c.updateExpense(expense, before) },
+		func() error { return c.updateExpense(before, expense) },
+	)
+
+	return nil
+}
+
+// validateExpense formats the date and kind of an expense, checks the
+// accounts of transfers, resolves its category, payee and split lines
+// and normalizes its tags.
+func (c *Controller) validateExpense(expense domain.Expense) (domain.Expense, error) {
+	date, err := formatDate(expense.Date)
+	if err != nil {
+		return expense, err
+	}
+
 	expense.Date = date
 
-	return c.db.InsertExpense(expense)
+	switch expense.Kind {
+	case "":
+		expense.Kind = domain.KindExpense
+	case domain.KindExpense, domain.KindIncome, domain.KindTransfer:
+	default:
+		return expense, errors.New("Kind should be expense, income or transfer.")
+	}
+
+	expense, err = validateTransfer(expense)
+	if err != nil {
+		return expense, err
+	}
+
+	expense, err = c.resolveCategory(expense)
+	if err != nil {
+		return expense, err
+	}
+
+	expense, err = c.resolvePayee(expense)
+	if err != nil {
+		return expense, err
+	}
+
+	expense.Tags = domain.NormalizeTags(expense.Tags)
+
+	return c.validateSplits(expense)
+}
+
+// validateSplits resolves the category of every split line of an expense
+// and checks they add up to its amount. Only expenses can be split.
+func (c *Controller) validateSplits(expense domain.Expense) (domain.Expense, error) {
+	if len(expense.Splits) == 0 {
+		return expense, nil
+	}
+
+	if !expense.IsSpending() {
+		return expense, errors.New("Only expenses can be split.")
+	}
+
+	splits := []domain.Split{}
+	amounts := []domain.Money{}
+
+	for _, split := range expense.Splits {
+		if split.Amount <= 0 {
+			return expense, errors.New("Split amount should be positive.")
+		}
+
+		if split.CategoryId == 0 {
+			id, name, err := c.resolveCategoryName(split.Category)
+			if err != nil {
+				return expense, err
+			}
+			split.CategoryId = id
+			split.Category = name
+		}
+
+		split.Note = strings.TrimSpace(split.Note)
+		splits = append(splits, split)
+		amounts = append(amounts, split.Amount)
+	}
+
+	if total := domain.Sum(amounts...); total != expense.Amount {
+		return expense, fmt.Errorf("Splits add up to %s but the expense is %s.", total, expense.Amount)
+	}
+
+	expense.Splits = splits
+
+	return expense, nil
 }
 
-// RemoveExpense removes Expense from database if valid id.
+// RemoveExpense moves Expense to the trash if valid id.
 func (c *Controller) RemoveExpense(id int) error {
-	return c.db.DeleteExpense(id)
+	expense, err := c.findExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.deleteExpense(id); err != nil {
+		return err
+	}
+
+	c.record(
+		fmt.Sprintf("delete %q", expense.Name),
+		func() error { return c.restoreExpense(id) },
+		func() error { return c.deleteExpense(id) },
+	)
+
+	return nil
 }
 
 // InsertBudgetMonth adds budget amount to database if valid.
 func (c *Controller) InsertBudgetMonth(amount, date string) error {
-	amountFloat, err := strconv.ParseFloat(amount, 64)
+	return c.insertBudgetMonth(amount, date, false)
+}
+
+// InsertBudgetMonthAndDefault sets both the budget of a month and the
+// default budget to amount, as a single change to undo.
+func (c *Controller) InsertBudgetMonthAndDefault(amount, date string) error {
+	return c.insertBudgetMonth(amount, date, true)
+}
+
+// insertBudgetMonth sets the budget of a month, and the default budget
+// too when asDefault, and records it in the history.
+func (c *Controller) insertBudgetMonth(amount, date string, asDefault bool) error {
+	money, err := domain.ParseMoney(amount)
 	if err != nil {
 		return err
 	}
 
-	formattedAmount := fmt.Sprintf("%.2f", amountFloat)
-
 	formattedDate, err := formatDate(date)
 	if err != nil {
 		return err
 	}
 
-	return c.db.InsertBudget(formattedAmount, formattedDate)
+	before, ok, err := c.db.GetBudgetWithYearMonth(formattedDate)
+	if err != nil {
+		return err
+	}
+
+	defaultBefore, err := c.db.GetDefaultBudget()
+	if err != nil {
+		return err
+	}
+
+	description := fmt.Sprintf("budget of %s set to %s", formattedDate, money)
+	undo := func() error { return c.setBudget(formattedDate, before, ok) }
+	redo := func() error { return c.setBudget(formattedDate, money, true) }
+
+	if asDefault {
+		description += " and by default"
+		undo = inOrder(func() error { return c.setBudget("default", defaultBefore, true) }, undo)
+		redo = inOrder(redo, func() error { return c.setBudget("default", money, true) })
+	}
+
+	if err := redo(); err != nil {
+		return rollBack(err, undo)
+	}
+
+	c.record(description, undo, redo)
+
+	return nil
 }
 
 // UpdateDefaultBudget updates the amount of default budget.
 func (c *Controller) UpdateDefaultBudget(amount string) error {
-	amountFloat, err := strconv.ParseFloat(amount, 64)
+	money, err := domain.ParseMoney(amount)
+	if err != nil {
+		return err
+	}
+
+	before, err := c.db.GetDefaultBudget()
 	if err != nil {
 		return err
 	}
 
-	formattedAmount := fmt.Sprintf("%.2f", amountFloat)
+	if err := c.setBudget("default", money, true); err != nil {
+		return err
+	}
+
+	c.record(
+		fmt.Sprintf("default budget set to %s", money),
+		func() error { return c.setBudget("default", before, true) },
+		func() error { return c.setBudget("default", money, true) },
+	)
 
-	return c.db.UpdateDefaultBudget(formattedAmount)
+	return nil
 }
 
-// TODO
-func (c *Controller) CreateMonthData(year int, monthNumber time.Month) domain.MonthData {
-	expenses := c.getExpensesForYearMonth(year, monthNumber)
+// CreateMonthData gathers expenses and budget of a month and
+// returns them with the total spent and the money left.
+// The budget includes the leftover carried over from previous months.
+func (c *Controller) CreateMonthData(year int, monthNumber time.Month) (domain.MonthData, error) {
+	if err := c.MaterializeRecurring(); err != nil {
+		return domain.MonthData{}, err
+	}
+
+	carriedOver, err := c.calculateCarriedOver(year, monthNumber)
+	if err != nil {
+		return domain.MonthData{}, err
+	}
+
+	categories, err := c.db.GetCategories()
+	if err != nil {
+		return domain.MonthData{}, err
+	}
+
+	return c.buildMonthData(year, monthNumber, carriedOver, categories)
+}
+
+// buildMonthData returns the data of a month receiving carriedOver
+// from the previous months.
+func (c *Controller) buildMonthData(year int, monthNumber time.Month, carriedOver domain.Money, categories []domain.Category) (domain.MonthData, error) {
+	expenses, err := c.getExpensesForYearMonth(year, monthNumber)
+	if err != nil {
+		return domain.MonthData{}, err
+	}
+
 	totalSpendings := calculateTotalExpenses(expenses)
+	totalIncome := calculateTotalIncome(expenses)
 
 	yearMonth := fmt.Sprintf("%d-%02d", year, int(monthNumber))
 
-	budgetMonth := c.db.GetBudgetWithYearMonth(yearMonth)
-
-	if budgetMonth == "" {
-		budgetMonth = c.db.GetDefaultBudget()
+	baseBudget, err := c.getMonthBudget(yearMonth)
+	if err != nil {
+		return domain.MonthData{}, err
 	}
 
-	budget, err := strconv.ParseFloat(budgetMonth, 64)
+	budget := baseBudget.Add(carriedOver)
 
+	categoryBudgets, err := c.getCategoryBudgetsForYearMonth(yearMonth)
 	if err != nil {
-		logger.Error("Could not parse string to float: " + err.Error())
-		budget = 0.00
+		return domain.MonthData{}, err
 	}
 
 	return domain.MonthData{
@@ -154,27 +417,100 @@ func (c *Controller) CreateMonthData(year int, monthNumber time.Month) domain.Mo
 		Month:          monthNumber,
 		Expenses:       expenses,
 		Budget:         budget,
+		BaseBudget:     baseBudget,
+		CarriedOver:    carriedOver,
 		TotalSpendings: totalSpendings,
-		MoneyLeft:      budget - totalSpendings,
+		MoneyLeft:      budget.Sub(totalSpendings),
+		Categories:     calculateCategoryData(expenses, categories, categoryBudgets),
+		TotalIncome:    totalIncome,
+		NetCashFlow:    totalIncome.Sub(totalSpendings),
+		SavingsRate:    calculateSavingsRate(totalIncome, totalSpendings),
+	}, nil
+}
+
+// getMonthBudget returns the budget set for a month (YYYY-MM),
+// falling back to the default budget.
+func (c *Controller) getMonthBudget(yearMonth string) (domain.Money, error) {
+	budget, ok, err := c.db.GetBudgetWithYearMonth(yearMonth)
+	if err != nil {
+		return 0, err
+	}
+
+	if !ok {
+		return c.db.GetDefaultBudget()
+	}
+
+	return budget, nil
+}
+
+// GetExpensesInRange returns the expenses dated from one date to another,
+// both included, ordered by date. A month-only from (YYYY-MM) starts on the
+// first day of its month and a month-only to ends on its last day.
+func (c *Controller) GetExpensesInRange(from, to string) ([]domain.Expense, error) {
+	from, err := formatDate(from)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err = formatDate(to)
+	if err != nil {
+		return nil, err
 	}
+
+	if len(splitDate(to)) == 2 {
+		last, err := time.Parse(dateLayout, domain.RangeDate(to))
+		if err != nil {
+			return nil, err
+		}
+		to = last.AddDate(0, 1, -1).Format(dateLayout)
+	}
+
+	return c.db.GetExpensesInRange(domain.RangeDate(from), domain.RangeDate(to))
 }
 
-// getExpensesForYearMonth format year and month into a string and
-// calls the database to fetch expenses of that month and returns it.
-func (c *Controller) getExpensesForYearMonth(year int, month time.Month) []domain.Expense {
-	yearMonth := fmt.Sprintf("%%%d-%02d%%", year, int(month))
-	spendings := c.db.GetExpensesWithYearMonth(yearMonth)
+// getExpensesForYearMonth returns the expenses dated within a month,
+// month-only expenses of that month included.
+func (c *Controller) getExpensesForYearMonth(year int, month time.Month) ([]domain.Expense, error) {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, 1, -1)
 
-	return spendings
+	return c.db.GetExpensesInRange(first.Format(dateLayout), last.Format(dateLayout))
 }
 
-// calculateTotalSpending returns the total amount for all expenses.
-func calculateTotalExpenses(expenses []domain.Expense) float64 {
-	total := 0.00
+// calculateTotalSpending returns the total amount for all expenses,
+// income and transfers left out.
+func calculateTotalExpenses(expenses []domain.Expense) domain.Money {
+	total := domain.Money(0)
 
 	for _, s := range expenses {
-		total += s.Amount
+		if !s.IsSpending() {
+			continue
+		}
+		total = total.Add(s.Amount)
 	}
 
 	return total
 }
+
+// calculateTotalIncome returns the total amount for all income.
+func calculateTotalIncome(expenses []domain.Expense) domain.Money {
+	total := domain.Money(0)
+
+	for _, s := range expenses {
+		if s.IsIncome() {
+			total = total.Add(s.Amount)
+		}
+	}
+
+	return total
+}
+
+// calculateSavingsRate returns the share of income that was not spent,
+// from 0 to 1 (negative when spending more than earned), 0 without income.
+func calculateSavingsRate(income, spendings domain.Money) float64 {
+	if income <= 0 {
+		return 0
+	}
+
+	return float64(income.Sub(spendings)) / float64(income)
+}
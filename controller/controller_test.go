@@ -0,0 +1,120 @@
+package controller
+
+import (
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestFormatDateAs(t *testing.T) {
+	tests := []struct {
+		date  string
+		order domain.DateOrder
+		want  string
+		fails bool
+	}{
+		{date: "2023-01-31", order: domain.DateYMD, want: "2023-01-31"},
+		{date: "20230131", order: domain.DateYMD, want: "2023-01-31"},
+		{date: "2023-01-31T10:00:00", order: domain.DateYMD, want: "2023-01-31"},
+		{date: "31/01/2023", order: domain.DateDMY, want: "2023-01-31"},
+		{date: "31.01.23", order: domain.DateDMY, want: "2023-01-31"},
+		{date: "01/31/2023 10:00", order: domain.DateMDY, want: "2023-01-31"},
+		{date: "1/31/23", order: domain.DateMDY, want: "2023-01-31"},
+		{date: "01312023", order: domain.DateMDY, want: "2023-01-31"},
+		{date: "1/31/23", order: domain.DateYMD, fails: true},
+		{date: "1/31/023", order: domain.DateMDY, fails: true},
+		{date: "31/01/2023", order: domain.DateMDY, fails: true},
+		{date: "2023-01", order: domain.DateYMD, fails: true},
+	}
+
+	for _, test := range tests {
+		got, err := formatDateAs(test.date, test.order)
+		if test.fails {
+			if err == nil {
+				t.Errorf("formatDateAs(%q, %s) = %q, want an error", test.date, test.order, got)
+			}
+			continue
+		}
+
+		if err != nil || got != test.want {
+			t.Errorf("formatDateAs(%q, %s) = %q, %v, want %q", test.date, test.order, got, err, test.want)
+		}
+	}
+}
+
+func TestValidateSplits(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	check(t, c.AddCategory(domain.Category{Name: "Home"}))
+
+	tests := []struct {
+		name    string
+		expense domain.Expense
+		fails   bool
+	}{
+		{"adding up", domain.Expense{Amount: 1000, Splits: []domain.Split{{Category: "Food", Amount: 400}, {Category: "Home", Amount: 600}}}, false},
+		{"short", domain.Expense{Amount: 1000, Splits: []domain.Split{{Category: "Food", Amount: 400}, {Category: "Home", Amount: 500}}}, true},
+		{"over", domain.Expense{Amount: 1000, Splits: []domain.Split{{Category: "Food", Amount: 400}, {Category: "Home", Amount: 700}}}, true},
+		{"zero", domain.Expense{Amount: 1000, Splits: []domain.Split{{Category: "Food", Amount: 1000}, {Category: "Home", Amount: 0}}}, true},
+		{"negative", domain.Expense{Amount: 1000, Splits: []domain.Split{{Category: "Food", Amount: 1200}, {Category: "Home", Amount: -200}}}, true},
+		{"income", domain.Expense{Amount: 1000, Kind: domain.KindIncome, Splits: []domain.Split{{Category: "Food", Amount: 1000}}}, true},
+	}
+
+	for _, test := range tests {
+		if test.expense.Kind == "" {
+			test.expense.Kind = domain.KindExpense
+		}
+		expense, err := c.validateSplits(test.expense)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: got error %v, want failing %v", test.name, err, test.fails)
+			continue
+		}
+		if err == nil && (expense.Splits[0].CategoryId == 0 || expense.Splits[0].Category != "Food") {
+			t.Errorf("%s: got split %+v, want the Food category resolved", test.name, expense.Splits[0])
+		}
+	}
+}
+
+func TestUpdateSplitExpense(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	check(t, c.AddCategory(domain.Category{Name: "Home"}))
+	check(t, c.AddExpense(domain.Expense{
+		Name:   "market",
+		Date:   "2023-02-01",
+		Amount: 1000,
+		Splits: []domain.Split{{Category: "Food", Amount: 400, Note: "fruit"}, {Category: "Home", Amount: 600}},
+	}))
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	expense := expenses[0]
+	expense.Name = "supermarket"
+	check(t, c.UpdateExpense(expense))
+
+	expenses, err = c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	splits := expenses[0].Splits
+	if expenses[0].Name != "supermarket" || len(splits) != 2 ||
+		splits[0].Category != "Food" || splits[0].Amount != 400 || splits[0].Note != "fruit" ||
+		splits[1].Category != "Home" || splits[1].Amount != 600 {
+		t.Errorf("got %+v after renaming, want the splits kept", expenses[0])
+	}
+
+	// Changing the amount without the splits no longer adds up.
+	expense = expenses[0]
+	expense.Amount = 1200
+	if err := c.UpdateExpense(expense); err == nil {
+		t.Error("updated an expense with splits not adding up")
+	}
+
+	expense.Splits[1].Amount = 800
+	check(t, c.UpdateExpense(expense))
+
+	expenses, err = c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	if expenses[0].Amount != 1200 || len(expenses[0].Splits) != 2 || expenses[0].Splits[1].Amount != 800 {
+		t.Errorf("got %+v, want 12.00 split in 4.00 and 8.00", expenses[0])
+	}
+}
@@ -0,0 +1,137 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/alx-b/expensetracker/atomicfile"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/exporter"
+)
+
+// currencyPattern matches the commodities beancount accepts, like EUR.
+var currencyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9'._-]{0,22}[A-Z0-9]$`)
+
+// CreateExportData returns the expenses dated from one date to another,
+// both included, and the budgets of the months between. Dates can be
+// months (YYYY-MM) or whole years (YYYY).
+func (c *Controller) CreateExportData(from, to string) (domain.ExportData, error) {
+	from, to = strings.TrimSpace(from), strings.TrimSpace(to)
+	if from == "" || to == "" {
+		return domain.ExportData{}, errors.New("Dates from and to should be set.")
+	}
+
+	if len(from) == 4 {
+		from += "-01"
+	}
+	if len(to) == 4 {
+		to += "-12"
+	}
+
+	if err := c.MaterializeRecurring(); err != nil {
+		return domain.ExportData{}, err
+	}
+
+	expenses, err := c.GetExpensesInRange(from, to)
+	if err != nil {
+		return domain.ExportData{}, err
+	}
+
+	from, _ = formatDate(from)
+	to, _ = formatDate(to)
+
+	first, err := time.Parse(dateLayout, domain.RangeDate(from))
+	if err != nil {
+		return domain.ExportData{}, err
+	}
+
+	last, err := time.Parse(dateLayout, domain.RangeDate(to))
+	if err != nil {
+		return domain.ExportData{}, err
+	}
+
+	if last.Before(first) {
+		return domain.ExportData{}, errors.New("Date from should not be after date to.")
+	}
+
+	data := domain.ExportData{
+		From:     from,
+		To:       to,
+		Expenses: expenses,
+		Budgets:  []domain.MonthBudget{},
+	}
+
+	for month := first.AddDate(0, 0, 1-first.Day()); !month.After(last); month = month.AddDate(0, 1, 0) {
+		yearMonth := month.Format("2006-01")
+
+		budget, err := c.getMonthBudget(yearMonth)
+		if err != nil {
+			return data, err
+		}
+
+		categories, err := c.getCategoryBudgetsForYearMonth(yearMonth)
+		if err != nil {
+			return data, err
+		}
+
+		data.Budgets = append(data.Budgets, domain.MonthBudget{Month: yearMonth, Budget: budget, Categories: categories})
+	}
+
+	if data.Accounts, err = c.db.GetAccounts(); err != nil {
+		return data, err
+	}
+
+	if data.Categories, err = c.db.GetCategories(); err != nil {
+		return data, err
+	}
+
+	return data, nil
+}
+
+// Export writes the expenses and budgets told by options to the file at
+// path, in the format its extension tells unless set, and returns the
+// number of expenses written.
+func (c *Controller) Export(path string, options domain.ExportOptions) (int, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return 0, errors.New("File path should not be empty.")
+	}
+
+	format := options.Format
+	if format == "" {
+		format, _ = exporter.FormatOf(path)
+	}
+
+	known := false
+	names := []string{}
+	for _, f := range exporter.Formats {
+		known = known || f == format
+		names = append(names, string(f))
+	}
+	if !known {
+		return 0, fmt.Errorf("Export format should be one of %s.", strings.Join(names, ", "))
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(options.Currency))
+	if currency != "" && !currencyPattern.MatchString(currency) {
+		return 0, errors.New("Currency should be a code of capital letters, like EUR.")
+	}
+
+	data, err := c.CreateExportData(options.From, options.To)
+	if err != nil {
+		return 0, err
+	}
+
+	err = atomicfile.Write(path, func(w io.Writer) error {
+		return exporter.Write(w, data, format, currency)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return len(data.Expenses), nil
+}
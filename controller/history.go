@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// maxHistory is the number of changes that can be undone in a session.
+const maxHistory = 100
+
+// command is a recorded change and the way to revert and replay it.
+type command struct {
+	action domain.Action
+	undo   func() error
+	redo   func() error
+}
+
+// history holds the changes that can be undone and those that were undone
+// and can be redone, the latest last.
+type history struct {
+	done   []command
+	undone []command
+	lastId int
+}
+
+// record adds a change that was just made to the history,
+// dropping the oldest change past maxHistory and any undone change.
+func (c *Controller) record(description string, undo, redo func() error) {
+	c.history.lastId++
+	c.history.done = append(c.history.done, command{
+		action: domain.Action{Id: c.history.lastId, Description: description},
+		undo:   undo,
+		redo:   redo,
+	})
+
+	if len(c.history.done) > maxHistory {
+		c.history.done = c.history.done[len(c.history.done)-maxHistory:]
+	}
+
+	c.history.undone = nil
+}
+
+// inOrder returns a step running steps one after the other,
+// stopping at the first failing.
+func inOrder(steps ...func() error) func() error {
+	return func() error {
+		for _, step := range steps {
+			if err := step(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// Undo reverts the latest change and returns it.
+func (c *Controller) Undo() (domain.Action, error) {
+	if len(c.history.done) == 0 {
+		return domain.Action{}, errors.New("Nothing to undo.")
+	}
+
+	cmd := c.history.done[len(c.history.done)-1]
+	if err := cmd.undo(); err != nil {
+		return cmd.action, fmt.Errorf("Could not undo %s: %w", cmd.action.Description, err)
+	}
+
+	c.history.done = c.history.done[:len(c.history.done)-1]
+	c.history.undone = append(c.history.undone, cmd)
+
+	return cmd.action, nil
+}
+
+// Redo replays the latest undone change and returns it.
+func (c *Controller) Redo() (domain.Action, error) {
+	if len(c.history.undone) == 0 {
+		return domain.Action{}, errors.New("Nothing to redo.")
+	}
+
+	cmd := c.history.undone[len(c.history.undone)-1]
+	if err := cmd.redo(); err != nil {
+		return cmd.action, fmt.Errorf("Could not redo %s: %w", cmd.action.Description, err)
+	}
+
+	c.history.undone = c.history.undone[:len(c.history.undone)-1]
+	c.history.done = append(c.history.done, cmd)
+
+	return cmd.action, nil
+}
+
+// LastAction returns the change Undo would revert
+// and whether there is one.
+func (c *Controller) LastAction() (domain.Action, bool) {
+	if len(c.history.done) == 0 {
+		return domain.Action{}, false
+	}
+
+	return c.history.done[len(c.history.done)-1].action, true
+}
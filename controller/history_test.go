@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// step undoes or redoes the latest change, checking its description.
+func step(t *testing.T, do func() (domain.Action, error), want string) {
+	t.Helper()
+	action, err := do()
+	check(t, err)
+	if action.Description != want {
+		t.Errorf("got %q, want %q", action.Description, want)
+	}
+}
+
+func TestExpenseUndoRedo(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+
+	names := func() []string {
+		t.Helper()
+		expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+		check(t, err)
+		names := []string{}
+		for _, expense := range expenses {
+			names = append(names, expense.Name)
+		}
+		return names
+	}
+	expect := func(want ...string) {
+		t.Helper()
+		if got := names(); strings.Join(got, ", ") != strings.Join(want, ", ") {
+			t.Errorf("got expenses %q, want %q", got, want)
+		}
+	}
+
+	check(t, c.AddExpense(domain.Expense{Name: "lunch", Date: "2023-02-01", Amount: 1250}))
+	step(t, c.Undo, `add "lunch"`)
+	expect()
+	step(t, c.Redo, `add "lunch"`)
+	expect("lunch")
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	expense := expenses[0]
+	expense.Name = "brunch"
+	check(t, c.UpdateExpense(expense))
+	step(t, c.Undo, `edit "brunch"`)
+	expect("lunch")
+	step(t, c.Redo, `edit "brunch"`)
+	expect("brunch")
+
+	check(t, c.RemoveExpense(expense.Id))
+	expect()
+	step(t, c.Undo, `delete "brunch"`)
+	expect("brunch")
+	step(t, c.Redo, `delete "brunch"`)
+	expect()
+
+	trash, err := c.GetTrash()
+	check(t, err)
+	if len(trash) != 1 || trash[0].Id != expense.Id {
+		t.Errorf("got trash %+v, want the deleted expense", trash)
+	}
+
+	// A new change drops the undone ones.
+	step(t, c.Undo, `delete "brunch"`)
+	check(t, c.AddExpense(domain.Expense{Name: "dinner", Date: "2023-02-02", Amount: 2000}))
+	if _, err := c.Redo(); err == nil {
+		t.Error("redid a change after a new one")
+	}
+}
+
+func TestBudgetUndoRedo(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+
+	budget := func(date string) (domain.Money, bool) {
+		t.Helper()
+		if date == "default" {
+			budget, err := c.db.GetDefaultBudget()
+			check(t, err)
+			return budget, true
+		}
+		budget, ok, err := c.db.GetBudgetWithYearMonth(date)
+		check(t, err)
+		return budget, ok
+	}
+	expect := func(date string, want domain.Money, wantOk bool) {
+		t.Helper()
+		if got, ok := budget(date); got != want || ok != wantOk {
+			t.Errorf("got budget of %s %s (%v), want %s (%v)", date, got, ok, want, wantOk)
+		}
+	}
+
+	check(t, c.UpdateDefaultBudget("500"))
+	check(t, c.InsertBudgetMonth("800", "2023-02"))
+	check(t, c.InsertBudgetMonth("900", "2023-02"))
+
+	step(t, c.Undo, "budget of 2023-02 set to 900.00")
+	expect("2023-02", 80000, true)
+	step(t, c.Undo, "budget of 2023-02 set to 800.00")
+	expect("2023-02", 0, false)
+	step(t, c.Undo, "default budget set to 500.00")
+	expect("default", 0, true)
+
+	step(t, c.Redo, "default budget set to 500.00")
+	expect("default", 50000, true)
+	step(t, c.Redo, "budget of 2023-02 set to 800.00")
+	expect("2023-02", 80000, true)
+
+	// Setting the month as default is one change.
+	check(t, c.InsertBudgetMonthAndDefault("1000", "2023-03"))
+	expect("2023-03", 100000, true)
+	expect("default", 100000, true)
+
+	step(t, c.Undo, "budget of 2023-03 set to 1000.00 and by default")
+	expect("2023-03", 0, false)
+	expect("default", 50000, true)
+	step(t, c.Undo, "budget of 2023-02 set to 800.00")
+
+	step(t, c.Redo, "budget of 2023-02 set to 800.00")
+	step(t, c.Redo, "budget of 2023-03 set to 1000.00 and by default")
+	expect("2023-03", 100000, true)
+	expect("default", 100000, true)
+}
+
+func TestCategoryBudgetAsDefaultUndo(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	food, err := c.findCategory("Food")
+	check(t, err)
+
+	check(t, c.UpdateDefaultCategoryBudget("Food", "300"))
+	check(t, c.InsertCategoryBudgetMonthAndDefault("Food", "250", "2023-02"))
+
+	budgets := func(date string) map[int]domain.Money {
+		t.Helper()
+		budgets, err := c.db.GetCategoryBudgets(date)
+		check(t, err)
+		return budgets
+	}
+	if got := budgets("default"); !reflect.DeepEqual(got, map[int]domain.Money{food.Id: 25000}) {
+		t.Errorf("got default budgets %v, want 250.00", got)
+	}
+
+	step(t, c.Undo, "budget of Food for 2023-02 set to 250.00 and by default")
+	if got := budgets("2023-02"); len(got) != 0 {
+		t.Errorf("got %v after undo, want no month budget", got)
+	}
+	if got := budgets("default"); !reflect.DeepEqual(got, map[int]domain.Money{food.Id: 30000}) {
+		t.Errorf("got default budgets %v after undo, want 300.00", got)
+	}
+
+	action, ok := c.LastAction()
+	if !ok || action.Description != "default budget of Food set to 300.00" {
+		t.Errorf("got last action %q, want the default budget", action.Description)
+	}
+}
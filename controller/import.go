@@ -0,0 +1,695 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/importer"
+)
+
+// maxImportSize is the largest statement that can be imported, in bytes.
+const maxImportSize = 10 << 20
+
+// duplicateDays is how many days apart an imported transaction and an
+// existing expense of the same amount are flagged as duplicates, as banks
+// book card payments a few days after they are made.
+const duplicateDays = 3
+
+// importProfilesKey is the setting holding the saved import profiles.
+const importProfilesKey = "import_profiles"
+
+// PreviewImport reads the CSV, OFX, QFX or QIF statement at path with a
+// profile and returns the expenses it holds, filled by the categorization
+// rules. Lines that cannot be read, transactions imported before and
+// likely duplicates of existing expenses are skipped unless told otherwise.
+func (c *Controller) PreviewImport(path string, profile domain.ImportProfile) (domain.ImportPreview, error) {
+	profile, err := validateImportProfile(profile, false)
+	if err != nil {
+		return domain.ImportPreview{}, err
+	}
+
+	content, err := readImportFile(path)
+	if err != nil {
+		return domain.ImportPreview{}, err
+	}
+
+	text, encoding, err := importer.Decode(content, profile.Encoding)
+	if err != nil {
+		return domain.ImportPreview{}, err
+	}
+
+	preview := domain.ImportPreview{
+		Encoding: encoding,
+		Header:   []string{},
+		Rows:     []domain.ImportRow{},
+	}
+
+	var transactions []importer.Transaction
+
+	switch strings.ToLower(filepath.Ext(strings.TrimSpace(path))) {
+	case ".ofx", ".qfx":
+		transactions, err = importer.ReadOFX(text)
+		profile.DateOrder = domain.DateYMD
+		profile.DecimalSeparator = ""
+		profile.ExpensesPositive = false
+	case ".qif":
+		// QIF dates are month first unless written day first.
+		transactions, err = importer.ReadQIF(text)
+		if profile.DateOrder != domain.DateDMY {
+			profile.DateOrder = domain.DateMDY
+		}
+		profile.ExpensesPositive = false
+	default:
+		transactions, err = readCSVTransactions(text, profile, &preview)
+	}
+	if err != nil {
+		return preview, err
+	}
+
+	occurrences := map[string]int{}
+	for _, transaction := range transactions {
+		row, err := c.importRow(transaction, profile)
+		if err != nil {
+			return preview, err
+		}
+
+		if row.Error == "" {
+			row.Expense.ImportId = importId(transaction, row.Expense, occurrences)
+		}
+		preview.Rows = append(preview.Rows, row)
+	}
+
+	if err := c.flagImported(preview.Rows); err != nil {
+		return preview, err
+	}
+
+	if err := c.flagDuplicates(preview.Rows); err != nil {
+		return preview, err
+	}
+
+	return preview, nil
+}
+
+// readCSVTransactions returns the transactions in the columns of a CSV
+// statement and sets the delimiter and header of its preview.
+func readCSVTransactions(text string, profile domain.ImportProfile, preview *domain.ImportPreview) ([]importer.Transaction, error) {
+	if strings.TrimSpace(profile.DateColumn) == "" ||
+		strings.TrimSpace(profile.NameColumn) == "" ||
+		strings.TrimSpace(profile.AmountColumn) == "" {
+		return nil, errors.New("Date, name and amount columns should be set.")
+	}
+
+	preview.Delimiter = profile.Delimiter
+	if preview.Delimiter == "" {
+		preview.Delimiter = importer.DetectDelimiter(text)
+	}
+
+	records, err := importer.ReadCSV(text, preview.Delimiter)
+	if err != nil {
+		return nil, err
+	}
+
+	if profile.HasHeader && len(records) > 0 {
+		preview.Header = records[0].Fields
+		records = records[1:]
+	}
+
+	columns := []int{}
+	for _, column := range []string{profile.DateColumn, profile.NameColumn, profile.AmountColumn, profile.CategoryColumn} {
+		index, err := columnIndex(preview.Header, column)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, index)
+	}
+
+	transactions := []importer.Transaction{}
+	for _, record := range records {
+		field := func(i int) string {
+			if i < 0 || i >= len(record.Fields) {
+				return ""
+			}
+			return record.Fields[i]
+		}
+
+		transactions = append(transactions, importer.Transaction{
+			Line:     record.Line,
+			Date:     field(columns[0]),
+			Name:     field(columns[1]),
+			Amount:   field(columns[2]),
+			Category: field(columns[3]),
+		})
+	}
+
+	return transactions, nil
+}
+
+// importId returns the id an imported transaction is known by: the one the
+// bank gave it or else a hash of its account, date, amount, name and how
+// many times the same one came before it in the statement.
+func importId(transaction importer.Transaction, expense domain.Expense, occurrences map[string]int) string {
+	if transaction.Id != "" {
+		return "id:" + transaction.Id
+	}
+
+	key := fmt.Sprintf("%d|%s|%s|%d|%s", expense.AccountId, expense.Date, expense.Kind, expense.Amount, strings.ToLower(transaction.Name))
+	occurrences[key]++
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%d", key, occurrences[key])))
+
+	return "hash:" + hex.EncodeToString(sum[:16])
+}
+
+// flagImported marks the rows imported before, even if since moved to the
+// trash or purged, as duplicates of their expense and skips them, along with the
+// rows repeating an earlier one of the statement.
+func (c *Controller) flagImported(rows []domain.ImportRow) error {
+	seen := map[string]bool{}
+
+	for i := range rows {
+		id := rows[i].Expense.ImportId
+		if id == "" {
+			continue
+		}
+
+		if seen[id] {
+			rows[i].Skip = true
+			continue
+		}
+		seen[id] = true
+
+		expense, ok, err := c.importedExpense(id)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			rows[i].Duplicate = expense
+			rows[i].Skip = true
+		}
+	}
+
+	return nil
+}
+
+// importedExpense returns the expense imported before from a statement
+// transaction and whether there is one, purged expenses counting with an
+// Id of 0.
+func (c *Controller) importedExpense(importId string) (domain.Expense, bool, error) {
+	expense, ok, err := c.db.GetExpenseWithImportId(importId)
+	if err != nil || ok {
+		return expense, ok, err
+	}
+
+	purged, err := c.db.IsImportIdPurged(importId)
+	return domain.Expense{}, purged, err
+}
+
+// ImportExpenses adds the expenses of the rows not skipped, all of them or
+// none, as a single change to undo. Transactions imported before are left
+// out, so overlapping statements never add an expense twice.
+func (c *Controller) ImportExpenses(rows []domain.ImportRow) (int, error) {
+	expenses := []domain.Expense{}
+	seen := map[string]bool{}
+
+	for _, row := range rows {
+		if row.Skip || row.Error != "" {
+			continue
+		}
+
+		if id := row.Expense.ImportId; id != "" {
+			_, ok, err := c.importedExpense(id)
+			if err != nil {
+				return 0, err
+			}
+			if ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+		}
+
+		expense := row.Expense
+		expense.Id = 0
+
+		expense, err := c.validateExpense(expense)
+		if err != nil {
+			return 0, fmt.Errorf("Could not import line %d: %w", row.Line, err)
+		}
+
+		expenses = append(expenses, expense)
+	}
+
+	if len(expenses) == 0 {
+		return 0, errors.New("Nothing to import.")
+	}
+
+	ids, err := c.insertExpenses(expenses)
+	if len(ids) > 0 {
+		c.record(
+			fmt.Sprintf("import %d expenses", len(ids)),
+			func() error { return c.eachExpense(ids, c.deleteExpense) },
+			func() error { return c.eachExpense(ids, c.restoreExpense) },
+		)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return len(ids), nil
+}
+
+// eachExpense applies change to the expenses with the given ids.
+func (c *Controller) eachExpense(ids []int, change func(int) error) error {
+	for _, id := range ids {
+		if err := change(id); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readImportFile returns the content of the statement at path.
+func readImportFile(path string) ([]byte, error) {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return nil, errors.New("File path should not be empty.")
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %w", err)
+	}
+
+	if info.IsDir() {
+		return nil, errors.New("Only files can be imported.")
+	}
+
+	if info.Size() > maxImportSize {
+		return nil, fmt.Errorf("File should not be larger than %d MB.", maxImportSize>>20)
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %w", err)
+	}
+
+	return content, nil
+}
+
+// columnIndex returns the index of a column named by its header, ignoring
+// case, or numbered from 1. An empty column is -1.
+func columnIndex(header []string, column string) (int, error) {
+	column = strings.TrimSpace(column)
+	if column == "" {
+		return -1, nil
+	}
+
+	for i, name := range header {
+		if strings.EqualFold(name, column) {
+			return i, nil
+		}
+	}
+
+	number, err := strconv.Atoi(column)
+	if err != nil || number < 1 {
+		return -1, fmt.Errorf("Column %q is neither in the header nor a number from 1.", column)
+	}
+
+	return number - 1, nil
+}
+
+// importRow returns the row of a statement transaction, filled by the
+// categorization rules. A transaction that cannot be read gets an Error.
+// Without a decimal separator, it is detected from each amount.
+func (c *Controller) importRow(transaction importer.Transaction, profile domain.ImportProfile) (domain.ImportRow, error) {
+	row := domain.ImportRow{Line: transaction.Line}
+	name := strings.TrimSpace(transaction.Name)
+
+	decimal := profile.DecimalSeparator
+	if decimal == "" {
+		decimal = detectDecimal(transaction.Amount)
+	}
+
+	formattedDate, err := formatDateAs(transaction.Date, profile.DateOrder)
+	if err == nil {
+		row.Expense.Amount, err = parseImportAmount(transaction.Amount, decimal)
+	}
+	if err == nil && name == "" {
+		err = errors.New("Name should not be empty.")
+	}
+	if err != nil {
+		row.Error = err.Error()
+		row.Skip = true
+		return row, nil
+	}
+
+	spending := row.Expense.Amount < 0
+	if profile.ExpensesPositive {
+		spending = !spending
+	}
+
+	row.Expense.Kind = domain.KindIncome
+	if spending {
+		row.Expense.Kind = domain.KindExpense
+	}
+
+	if row.Expense.Amount < 0 {
+		row.Expense.Amount = -row.Expense.Amount
+	}
+
+	row.Expense.Name = name
+	row.Expense.Date = formattedDate
+	row.Expense.Category = strings.TrimSpace(transaction.Category)
+	row.Expense.AccountId = profile.AccountId
+
+	row.Expense, err = c.autoCategorize(row.Expense)
+
+	return row, err
+}
+
+// detectDecimal returns the decimal separator of an amount: the last of
+// point and comma when both are written, else the one written unless it
+// is followed by 3 digits, which makes it a thousands separator.
+func detectDecimal(amount string) string {
+	point := strings.LastIndex(amount, ".")
+	comma := strings.LastIndex(amount, ",")
+
+	switch {
+	case point != -1 && comma != -1:
+		if comma > point {
+			return ","
+		}
+		return "."
+	case comma != -1:
+		if isThousands(amount[comma+1:]) {
+			return "."
+		}
+		return ","
+	case point != -1:
+		if isThousands(amount[point+1:]) {
+			return ","
+		}
+	}
+
+	return "."
+}
+
+// isThousands reports whether the digits after a separator,
+// currency symbols and spaces left out, are a group of 3.
+func isThousands(after string) bool {
+	digits := 0
+	for _, r := range after {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	return digits == 3
+}
+
+// parseImportAmount parses an amount of a statement written with decimal
+// as decimal separator, ignoring currency symbols and spaces. The other
+// separator is only allowed between groups of 3 digits before the decimals.
+// Amounts in parentheses or followed by a minus are negative.
+func parseImportAmount(amount, decimal string) (domain.Money, error) {
+	amount = strings.TrimSpace(amount)
+	written := amount
+
+	thousands := ","
+	if decimal == "," {
+		thousands = "."
+	}
+
+	negative := false
+	if strings.HasPrefix(amount, "(") && strings.HasSuffix(amount, ")") {
+		negative = true
+		amount = amount[1 : len(amount)-1]
+	}
+	if strings.HasSuffix(amount, "-") {
+		negative = true
+		amount = strings.TrimSuffix(amount, "-")
+	}
+
+	cleaned := strings.Builder{}
+	for _, r := range amount {
+		switch {
+		case r >= '0' && r <= '9', r == '-', r == '+':
+			cleaned.WriteRune(r)
+		case string(r) == decimal:
+			cleaned.WriteRune('.')
+		case string(r) == thousands:
+			cleaned.WriteRune('_')
+		}
+	}
+
+	whole, fraction, _ := strings.Cut(cleaned.String(), ".")
+	if strings.Contains(fraction, "_") || !isGrouped(strings.TrimLeft(whole, "+-")) {
+		return 0, fmt.Errorf("Could not parse amount %q.", written)
+	}
+
+	money, err := domain.ParseMoney(strings.ReplaceAll(cleaned.String(), "_", ""))
+	if err != nil {
+		return 0, err
+	}
+
+	if negative {
+		money = -money
+	}
+
+	return money, nil
+}
+
+// isGrouped reports whether digits separated by _ are thousands groups,
+// 1 to 3 digits followed by groups of 3.
+func isGrouped(whole string) bool {
+	groups := strings.Split(whole, "_")
+	if len(groups) == 1 {
+		return true
+	}
+
+	if len(groups[0]) < 1 || len(groups[0]) > 3 {
+		return false
+	}
+
+	for _, group := range groups[1:] {
+		if len(group) != 3 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// flagDuplicates marks the rows that are likely an existing expense as
+// duplicates of it and skips them. An existing expense is the duplicate
+// of one row at most, the one with the closest date. Imported expenses
+// are only matched by their import id.
+func (c *Controller) flagDuplicates(rows []domain.ImportRow) error {
+	from, to := "", ""
+	for _, row := range rows {
+		if row.Error != "" {
+			continue
+		}
+		date := domain.RangeDate(row.Expense.Date)
+		if from == "" || date < from {
+			from = date
+		}
+		if date > to {
+			to = date
+		}
+	}
+
+	if from == "" {
+		return nil
+	}
+
+	first, err := time.Parse(dateLayout, from)
+	if err != nil {
+		return err
+	}
+
+	last, err := time.Parse(dateLayout, to)
+	if err != nil {
+		return err
+	}
+
+	existing, err := c.db.GetExpensesInRange(
+		first.AddDate(0, 0, -duplicateDays).Format(dateLayout),
+		last.AddDate(0, 0, duplicateDays).Format(dateLayout),
+	)
+	if err != nil {
+		return err
+	}
+
+	matched := map[int]bool{}
+
+	for i := range rows {
+		if rows[i].Error != "" || rows[i].Skip {
+			continue
+		}
+
+		date, err := time.Parse(dateLayout, domain.RangeDate(rows[i].Expense.Date))
+		if err != nil {
+			return err
+		}
+
+		best, bestDays := -1, duplicateDays+1
+		for k, expense := range existing {
+			if matched[expense.Id] || expense.ImportId != "" || expense.Amount != rows[i].Expense.Amount || expense.Kind != rows[i].Expense.Kind {
+				continue
+			}
+
+			other, err := time.Parse(dateLayout, domain.RangeDate(expense.Date))
+			if err != nil {
+				return err
+			}
+
+			days := int(date.Sub(other).Hours() / 24)
+			if days < 0 {
+				days = -days
+			}
+
+			if days < bestDays {
+				best, bestDays = k, days
+			}
+		}
+
+		if best != -1 {
+			matched[existing[best].Id] = true
+			rows[i].Duplicate = existing[best]
+			rows[i].Skip = true
+		}
+	}
+
+	return nil
+}
+
+// GetImportProfiles returns the saved import profiles ordered by name.
+func (c *Controller) GetImportProfiles() ([]domain.ImportProfile, error) {
+	profiles := []domain.ImportProfile{}
+
+	value, ok, err := c.db.GetSetting(importProfilesKey)
+	if err != nil || !ok {
+		return profiles, err
+	}
+
+	if err := json.Unmarshal([]byte(value), &profiles); err != nil {
+		return profiles, fmt.Errorf("Could not read import profiles: %w", err)
+	}
+
+	return profiles, nil
+}
+
+// SaveImportProfile saves an import profile if valid, replacing the one
+// with the same name.
+func (c *Controller) SaveImportProfile(profile domain.ImportProfile) error {
+	profile, err := validateImportProfile(profile, true)
+	if err != nil {
+		return err
+	}
+
+	profiles, err := c.GetImportProfiles()
+	if err != nil {
+		return err
+	}
+
+	saved := []domain.ImportProfile{profile}
+	for _, existing := range profiles {
+		if !strings.EqualFold(existing.Name, profile.Name) {
+			saved = append(saved, existing)
+		}
+	}
+
+	return c.saveImportProfiles(saved)
+}
+
+// RemoveImportProfile removes the import profile with a name.
+func (c *Controller) RemoveImportProfile(name string) error {
+	profiles, err := c.GetImportProfiles()
+	if err != nil {
+		return err
+	}
+
+	saved := []domain.ImportProfile{}
+	for _, existing := range profiles {
+		if !strings.EqualFold(existing.Name, strings.TrimSpace(name)) {
+			saved = append(saved, existing)
+		}
+	}
+
+	if len(saved) == len(profiles) {
+		return errors.New("Import profile does not exist.")
+	}
+
+	return c.saveImportProfiles(saved)
+}
+
+// saveImportProfiles replaces the saved import profiles.
+func (c *Controller) saveImportProfiles(profiles []domain.ImportProfile) error {
+	sort.Slice(profiles, func(i, j int) bool {
+		return strings.ToLower(profiles[i].Name) < strings.ToLower(profiles[j].Name)
+	})
+
+	content, err := json.Marshal(profiles)
+	if err != nil {
+		return fmt.Errorf("Could not encode import profiles: %w", err)
+	}
+
+	return c.db.UpdateSetting(importProfilesKey, string(content))
+}
+
+// validateImportProfile checks the delimiter and formats of a profile and
+// fills the default ones. Saved profiles need a name.
+func validateImportProfile(profile domain.ImportProfile, named bool) (domain.ImportProfile, error) {
+	profile.Name = strings.TrimSpace(profile.Name)
+	if named && profile.Name == "" {
+		return profile, errors.New("Profile name should not be empty.")
+	}
+
+	if strings.EqualFold(profile.Delimiter, "tab") || profile.Delimiter == `\t` {
+		profile.Delimiter = "\t"
+	}
+	if profile.Delimiter != "\t" {
+		profile.Delimiter = strings.TrimSpace(profile.Delimiter)
+	}
+	if len([]rune(profile.Delimiter)) > 1 {
+		return profile, errors.New("Delimiter should be a single character.")
+	}
+
+	profile.Encoding = strings.ToLower(strings.TrimSpace(profile.Encoding))
+	if profile.Encoding != "" {
+		known := false
+		for _, encoding := range importer.Encodings {
+			known = known || encoding == profile.Encoding
+		}
+		if !known {
+			return profile, fmt.Errorf("Encoding should be one of %s.", strings.Join(importer.Encodings, ", "))
+		}
+	}
+
+	switch profile.DateOrder {
+	case "":
+		profile.DateOrder = domain.DateYMD
+	case domain.DateYMD, domain.DateDMY, domain.DateMDY:
+	default:
+		return profile, fmt.Errorf("Date order should be %s, %s or %s.", domain.DateYMD, domain.DateDMY, domain.DateMDY)
+	}
+
+	switch profile.DecimalSeparator {
+	case "", ".", ",":
+	default:
+		return profile, errors.New("Decimal separator should be empty, . or ,")
+	}
+
+	return profile, nil
+}
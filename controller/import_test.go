@@ -0,0 +1,247 @@
+package controller
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/importer"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestParseImportAmount(t *testing.T) {
+	tests := []struct {
+		amount  string
+		decimal string
+		want    domain.Money
+		fails   bool
+	}{
+		{amount: "12.34", want: 1234},
+		{amount: "12,34", want: 1234},
+		{amount: "-3.5", want: -350},
+		{amount: "1,234.56", want: 123456},
+		{amount: "1.234,56", want: 123456},
+		{amount: "1,234", want: 123400},
+		{amount: "1.234", want: 123400},
+		{amount: "1,234,567.80", want: 123456780},
+		{amount: "€ 1 234,56", want: 123456},
+		{amount: "(12.00)", want: -1200},
+		{amount: "12.00-", want: -1200},
+		{amount: "12,34", decimal: ".", fails: true},
+		{amount: "12.34", decimal: ",", fails: true},
+		{amount: "1,234.56", decimal: ".", want: 123456},
+		{amount: "1.234,56", decimal: ",", want: 123456},
+		{amount: "1,23,456.00", decimal: ".", fails: true},
+		{amount: ",123.00", decimal: ".", fails: true},
+		{amount: "12.34,5", decimal: ".", fails: true},
+		{amount: "1.234.5", fails: true},
+		{amount: "abc", fails: true},
+	}
+
+	for _, test := range tests {
+		decimal := test.decimal
+		if decimal == "" {
+			decimal = detectDecimal(test.amount)
+		}
+
+		got, err := parseImportAmount(test.amount, decimal)
+		if test.fails {
+			if err == nil {
+				t.Errorf("parseImportAmount(%q, %q) = %s, want an error", test.amount, decimal, got)
+			}
+			continue
+		}
+
+		if err != nil || got != test.want {
+			t.Errorf("parseImportAmount(%q, %q) = %s, %v, want %s", test.amount, decimal, got, err, test.want)
+		}
+	}
+}
+
+func TestReimportStatement(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	profile := domain.ImportProfile{
+		HasHeader:    true,
+		DateColumn:   "date",
+		NameColumn:   "name",
+		AmountColumn: "amount",
+	}
+
+	first := writeStatement(t, "date;name;amount\n2023-02-01;coffee;-2,50\n2023-02-01;coffee;-2,50\n2023-02-03;rent;-900,00\n")
+	second := writeStatement(t, "date;name;amount\n2023-02-01;coffee;-2,50\n2023-02-01;coffee;-2,50\n2023-02-03;rent;-900,00\n2023-02-05;salary;1.500,00\n")
+
+	importStatement(t, c, first, profile, 3)
+
+	preview, err := c.PreviewImport(second, profile)
+	check(t, err)
+	if preview.Delimiter != ";" || len(preview.Rows) != 4 {
+		t.Fatalf("got preview %+v, want 4 rows split by ;", preview)
+	}
+	for i, row := range preview.Rows[:3] {
+		if !row.Skip || row.Duplicate.Id == 0 {
+			t.Errorf("row %d not flagged as imported: %+v", i, row)
+		}
+	}
+
+	// Rows imported before are left out even when not skipped.
+	for i := range preview.Rows {
+		preview.Rows[i].Skip = false
+	}
+
+	count, err := c.ImportExpenses(preview.Rows)
+	check(t, err)
+	if count != 1 {
+		t.Errorf("imported %d expenses again, want only the salary", count)
+	}
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	if len(expenses) != 4 {
+		t.Errorf("got %d expenses, want 4", len(expenses))
+	}
+
+	if _, err := c.ImportExpenses(preview.Rows); err == nil {
+		t.Error("imported the same statement twice")
+	}
+}
+
+func TestReimportPurged(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	profile := domain.ImportProfile{
+		HasHeader:    true,
+		DateColumn:   "date",
+		NameColumn:   "name",
+		AmountColumn: "amount",
+	}
+	statement := writeStatement(t, "date;name;amount\n2023-02-01;coffee;-2,50\n")
+	importStatement(t, c, statement, profile, 1)
+
+	expenses, err := c.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	check(t, c.RemoveExpense(expenses[0].Id))
+
+	c.now = func() time.Time { return time.Now().AddDate(0, 0, 31) }
+	purged, err := c.PurgeExpiredTrash()
+	check(t, err)
+	if purged != 1 {
+		t.Fatalf("purged %d expenses, want 1", purged)
+	}
+
+	preview, err := c.PreviewImport(statement, profile)
+	check(t, err)
+	if len(preview.Rows) != 1 || !preview.Rows[0].Skip {
+		t.Errorf("got rows %+v, want the purged transaction flagged as imported", preview.Rows)
+	}
+
+	preview.Rows[0].Skip = false
+	if _, err := c.ImportExpenses(preview.Rows); err == nil {
+		t.Error("imported a purged transaction again")
+	}
+}
+
+func TestPreviewQIF(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	path := writeStatementAs(t, "statement.qif", "!Type:Bank\nD1/15'23\nT-1,234.56\nPRent\n^\nD02/01/2023\nT25.00\nPRefund\n^\n")
+
+	tests := []struct {
+		order domain.DateOrder
+		dates []string
+	}{
+		{"", []string{"2023-01-15", "2023-02-01"}},
+		{domain.DateYMD, []string{"2023-01-15", "2023-02-01"}},
+		{domain.DateDMY, []string{"", "2023-01-02"}},
+	}
+
+	for _, test := range tests {
+		preview, err := c.PreviewImport(path, domain.ImportProfile{DateOrder: test.order})
+		check(t, err)
+
+		if len(preview.Rows) != 2 {
+			t.Fatalf("got %d rows, want 2", len(preview.Rows))
+		}
+		for i, row := range preview.Rows {
+			if row.Expense.Date != test.dates[i] {
+				t.Errorf("%q: got date %q on line %d (%s), want %q", test.order, row.Expense.Date, row.Line, row.Error, test.dates[i])
+			}
+		}
+	}
+
+	preview, err := c.PreviewImport(path, domain.ImportProfile{})
+	check(t, err)
+	rent, refund := preview.Rows[0].Expense, preview.Rows[1].Expense
+	if rent.Amount != 123456 || rent.Kind != domain.KindExpense || refund.Amount != 2500 || refund.Kind != domain.KindIncome {
+		t.Errorf("got %+v and %+v, want a 1234.56 expense and a 25.00 income", rent, refund)
+	}
+}
+
+func TestReimportOFX(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	statement := "<OFX><ACCTID>111<STMTTRN><DTPOSTED>20230201<TRNAMT>-3.50<FITID>A1<NAME>Bakery</STMTTRN></OFX>"
+	importStatement(t, c, writeStatementAs(t, "first.ofx", statement), domain.ImportProfile{}, 1)
+
+	// The bank renamed the transaction, its FITID still matches.
+	renamed := strings.Replace(statement, "Bakery", "BAKERY TOWN", 1)
+	preview, err := c.PreviewImport(writeStatementAs(t, "second.qfx", renamed), domain.ImportProfile{})
+	check(t, err)
+	if len(preview.Rows) != 1 || !preview.Rows[0].Skip || preview.Rows[0].Expense.ImportId != "id:111/A1" {
+		t.Errorf("got rows %+v, want the transaction flagged as imported", preview.Rows)
+	}
+}
+
+func TestImportId(t *testing.T) {
+	expense := domain.Expense{Date: "2023-02-01", Kind: domain.KindExpense, Amount: 250, AccountId: 1}
+	coffee := importer.Transaction{Name: "Coffee"}
+
+	occurrences := map[string]int{}
+	first := importId(coffee, expense, occurrences)
+	second := importId(importer.Transaction{Name: "COFFEE"}, expense, occurrences)
+
+	if first == second {
+		t.Errorf("got the same id %q for two coffees of a statement", first)
+	}
+
+	if again := importId(coffee, expense, map[string]int{}); again != first {
+		t.Errorf("got %q in another statement, want %q", again, first)
+	}
+
+	otherAccount := expense
+	otherAccount.AccountId = 2
+	if id := importId(coffee, otherAccount, map[string]int{}); id == first {
+		t.Errorf("got the same id %q in another account", id)
+	}
+
+	if id := importId(importer.Transaction{Name: "Coffee", Id: "111/A1"}, expense, map[string]int{}); id != "id:111/A1" {
+		t.Errorf("got %q, want the bank id", id)
+	}
+}
+
+// writeStatement writes a CSV statement to a temporary file and returns its path.
+func writeStatement(t *testing.T, content string) string {
+	t.Helper()
+	return writeStatementAs(t, "statement.csv", content)
+}
+
+// writeStatementAs writes a statement to a temporary file named name
+// and returns its path.
+func writeStatementAs(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	check(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// importStatement imports every row of a statement and checks their count.
+func importStatement(t *testing.T, c *Controller, path string, profile domain.ImportProfile, want int) {
+	t.Helper()
+	preview, err := c.PreviewImport(path, profile)
+	check(t, err)
+
+	count, err := c.ImportExpenses(preview.Rows)
+	check(t, err)
+	if count != want {
+		t.Fatalf("imported %d expenses, want %d", count, want)
+	}
+}
@@ -0,0 +1,97 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// CreateYearData returns the data of every month of a year.
+func (c *Controller) CreateYearData(year int) (domain.PeriodData, error) {
+	return c.CreatePeriodData(year, time.January, 12)
+}
+
+// CreatePeriodData returns the per month totals, budgets and leftovers of
+// months consecutive months from start of year, with the per category
+// sums of the whole period. A quarter is 3 months from January, April,
+// July or October.
+func (c *Controller) CreatePeriodData(year int, start time.Month, months int) (domain.PeriodData, error) {
+	if !isNumberBetween(int(start), 1, 12) {
+		return domain.PeriodData{}, errors.New("Month should be from 1 to 12.")
+	}
+
+	if months < 1 {
+		return domain.PeriodData{}, errors.New("Period should be at least one month.")
+	}
+
+	if err := c.MaterializeRecurring(); err != nil {
+		return domain.PeriodData{}, err
+	}
+
+	settings, err := c.GetRolloverSettings()
+	if err != nil {
+		return domain.PeriodData{}, err
+	}
+
+	carriedOver, err := c.calculateCarriedOver(year, start)
+	if err != nil {
+		return domain.PeriodData{}, err
+	}
+
+	categories, err := c.db.GetCategories()
+	if err != nil {
+		return domain.PeriodData{}, err
+	}
+
+	period := domain.PeriodData{
+		Year:       year,
+		StartMonth: start,
+		Months:     []domain.MonthSummary{},
+		Budget:     carriedOver,
+	}
+	expenses := []domain.Expense{}
+	categoryBudgets := map[int]domain.Money{}
+
+	for i := 0; i < months; i++ {
+		date := time.Date(year, start+time.Month(i), 1, 0, 0, 0, 0, time.UTC)
+
+		month, err := c.buildMonthData(date.Year(), date.Month(), carriedOver, categories)
+		if err != nil {
+			return domain.PeriodData{}, err
+		}
+
+		budgets, err := c.getCategoryBudgetsForYearMonth(fmt.Sprintf("%d-%02d", date.Year(), int(date.Month())))
+		if err != nil {
+			return domain.PeriodData{}, err
+		}
+
+		for id, amount := range budgets {
+			categoryBudgets[id] = categoryBudgets[id].Add(amount)
+		}
+
+		period.Months = append(period.Months, domain.MonthSummary{
+			Year:           month.Year,
+			Month:          month.Month,
+			Budget:         month.Budget,
+			TotalSpendings: month.TotalSpendings,
+			MoneyLeft:      month.MoneyLeft,
+			TotalIncome:    month.TotalIncome,
+			NetCashFlow:    month.NetCashFlow,
+		})
+		period.Budget = period.Budget.Add(month.BaseBudget)
+		period.TotalSpendings = period.TotalSpendings.Add(month.TotalSpendings)
+		period.TotalIncome = period.TotalIncome.Add(month.TotalIncome)
+		expenses = append(expenses, month.Expenses...)
+
+		carriedOver = carryOver(settings, month.MoneyLeft)
+	}
+
+	period.MoneyLeft = period.Budget.Sub(period.TotalSpendings)
+	period.NetCashFlow = period.TotalIncome.Sub(period.TotalSpendings)
+	period.SavingsRate = calculateSavingsRate(period.TotalIncome, period.TotalSpendings)
+	period.Categories = calculateCategoryData(expenses, categories, categoryBudgets)
+
+	return period, nil
+}
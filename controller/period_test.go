@@ -0,0 +1,102 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestCreatePeriodData(t *testing.T) {
+	// A default budget of 100.00, 30.00 of it for food, with the leftover
+	// carried over. October leaves 20.00 to the period, which spans the
+	// end of the year and holds month-only expenses.
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	check(t, c.UpdateDefaultBudget("100"))
+	check(t, c.UpdateDefaultCategoryBudget("Food", "30"))
+	check(t, c.UpdateRolloverSettings(domain.RolloverSettings{Mode: domain.RolloverFull}))
+	for _, expense := range []domain.Expense{
+		{Name: "rent", Date: "2022-10-10", Amount: 8000},
+		{Name: "market", Date: "2022-11-05", Amount: 6000, Category: "Food"},
+		{Name: "gifts", Date: "2022-12", Amount: 15000},
+		{Name: "market", Date: "2023-01", Amount: 2000, Category: "Food"},
+		{Name: "refund", Date: "2023-01-15", Amount: 5000, Kind: domain.KindIncome},
+		{Name: "rent", Date: "2023-02-10", Amount: 9000},
+	} {
+		check(t, c.AddExpense(expense))
+	}
+
+	period, err := c.CreatePeriodData(2022, time.November, 3)
+	check(t, err)
+
+	want := []domain.MonthSummary{
+		{Year: 2022, Month: time.November, Budget: 12000, TotalSpendings: 6000, MoneyLeft: 6000, NetCashFlow: -6000},
+		{Year: 2022, Month: time.December, Budget: 16000, TotalSpendings: 15000, MoneyLeft: 1000, NetCashFlow: -15000},
+		{Year: 2023, Month: time.January, Budget: 11000, TotalSpendings: 2000, MoneyLeft: 9000, TotalIncome: 5000, NetCashFlow: 3000},
+	}
+	if len(period.Months) != len(want) {
+		t.Fatalf("got %d months, want %d", len(period.Months), len(want))
+	}
+	for i, month := range period.Months {
+		if month != want[i] {
+			t.Errorf("got month %+v, want %+v", month, want[i])
+		}
+	}
+
+	if period.Budget != 32000 || period.TotalSpendings != 23000 || period.MoneyLeft != 9000 {
+		t.Errorf("got budget %s, spendings %s and %s left, want 320.00, 230.00 and 90.00",
+			period.Budget, period.TotalSpendings, period.MoneyLeft)
+	}
+	if period.TotalIncome != 5000 || period.NetCashFlow != -18000 {
+		t.Errorf("got income %s and net %s, want 50.00 and -180.00", period.TotalIncome, period.NetCashFlow)
+	}
+
+	food := domain.CategoryData{}
+	for _, category := range period.Categories {
+		if category.Category == "Food" {
+			food = category
+		}
+	}
+	if food.Budget != 9000 || food.Spent != 8000 || food.Remaining != 1000 {
+		t.Errorf("got food %+v, want 90.00 budget and 80.00 spent", food)
+	}
+}
+
+func TestCreatePeriodDataMonthOnly(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.UpdateDefaultBudget("100"))
+	check(t, c.AddExpense(domain.Expense{Name: "rent", Date: "2023-04", Amount: 9000}))
+	check(t, c.AddExpense(domain.Expense{Name: "tax", Date: "2023-06", Amount: 3000}))
+	check(t, c.AddExpense(domain.Expense{Name: "rent", Date: "2023-07", Amount: 9000}))
+
+	period, err := c.CreatePeriodData(2023, time.April, 3)
+	check(t, err)
+
+	spent := []domain.Money{}
+	for _, month := range period.Months {
+		spent = append(spent, month.TotalSpendings)
+	}
+	if len(spent) != 3 || spent[0] != 9000 || spent[1] != 0 || spent[2] != 3000 {
+		t.Errorf("got spendings %v for the quarter, want 90.00, 0.00 and 30.00", spent)
+	}
+	if period.Budget != 30000 || period.MoneyLeft != 18000 {
+		t.Errorf("got budget %s and %s left, want 300.00 and 180.00", period.Budget, period.MoneyLeft)
+	}
+
+	year, err := c.CreateYearData(2023)
+	check(t, err)
+	if len(year.Months) != 12 || year.TotalSpendings != 21000 {
+		t.Errorf("got %d months spending %s, want 12 spending 210.00", len(year.Months), year.TotalSpendings)
+	}
+
+	for _, test := range []struct {
+		start  time.Month
+		months int
+	}{{0, 3}, {13, 3}, {time.January, 0}} {
+		if _, err := c.CreatePeriodData(2023, test.start, test.months); err == nil {
+			t.Errorf("got a period of %d months from month %d", test.months, test.start)
+		}
+	}
+}
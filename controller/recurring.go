@@ -0,0 +1,276 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// dateLayout is the layout of full dates (YYYY-MM-DD).
+const dateLayout = "2006-01-02"
+
+// GetRecurringRules returns every recurring rule.
+func (c *Controller) GetRecurringRules() ([]domain.RecurringRule, error) {
+	return c.db.GetRecurringRules()
+}
+
+// AddRecurringRule adds RecurringRule to database if valid.
+// Its due occurrences are created on the next materialization.
+func (c *Controller) AddRecurringRule(rule domain.RecurringRule) error {
+	rule, err := c.validateRecurringRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rule.MaterializedUntil = ""
+
+	return c.db.InsertRecurringRule(rule)
+}
+
+// UpdateRecurringRule updates an existing RecurringRule if valid. Resuming a
+// paused rule skips the occurrences of the paused period. With
+// updateUpcoming, expenses already generated for after today are changed
+// to the new name, amount and category as well.
+func (c *Controller) UpdateRecurringRule(rule domain.RecurringRule, updateUpcoming bool) error {
+	existing, err := c.findRecurringRule(rule.Id)
+	if err != nil {
+		return err
+	}
+
+	rule, err = c.validateRecurringRule(rule)
+	if err != nil {
+		return err
+	}
+
+	today := c.now().Format(dateLayout)
+	rule.MaterializedUntil = existing.MaterializedUntil
+
+	if existing.Paused && !rule.Paused && rule.MaterializedUntil < today {
+		rule.MaterializedUntil = today
+	}
+
+	if err := c.db.UpdateRecurringRule(rule); err != nil {
+		return err
+	}
+
+	if !updateUpcoming {
+		return nil
+	}
+
+	return c.db.UpdateRuleExpensesAfter(rule.Id, today, domain.Expense{
+		Name:       rule.Name,
+		Amount:     rule.Amount,
+		CategoryId: rule.CategoryId,
+	})
+}
+
+// RemoveRecurringRule removes RecurringRule from database if valid id.
+// Expenses it already generated are kept.
+func (c *Controller) RemoveRecurringRule(id int) error {
+	return c.db.DeleteRecurringRule(id)
+}
+
+// MaterializeRecurring turns every due occurrence of the active rules into
+// an expense. Occurrences are due up to the end of the current month so the
+// month view is complete. Running it again never duplicates expenses.
+func (c *Controller) MaterializeRecurring() error {
+	rules, err := c.db.GetRecurringRules()
+	if err != nil {
+		return err
+	}
+
+	now := c.now()
+	until := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+	untilDate := until.Format(dateLayout)
+
+	for _, rule := range rules {
+		if rule.Paused || rule.MaterializedUntil >= untilDate {
+			continue
+		}
+
+		dates, err := dueOccurrences(rule, until)
+		if err != nil {
+			return fmt.Errorf("Could not materialize rule %q: %w", rule.Name, err)
+		}
+
+		expenses := []domain.Expense{}
+		for _, date := range dates {
+			expenses = append(expenses, domain.Expense{
+				Name:       rule.Name,
+				Date:       date,
+				Amount:     rule.Amount,
+				CategoryId: rule.CategoryId,
+				RuleId:     rule.Id,
+			})
+		}
+
+		if err := c.db.InsertRecurringOccurrences(rule.Id, expenses, untilDate); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// findRecurringRule returns the rule with the given id.
+func (c *Controller) findRecurringRule(id int) (domain.RecurringRule, error) {
+	rules, err := c.db.GetRecurringRules()
+	if err != nil {
+		return domain.RecurringRule{}, err
+	}
+
+	for _, rule := range rules {
+		if rule.Id == id {
+			return rule, nil
+		}
+	}
+
+	return domain.RecurringRule{}, fmt.Errorf("Could not find recurring rule with id %d", id)
+}
+
+// validateRecurringRule checks and normalizes a rule and resolves its category.
+func (c *Controller) validateRecurringRule(rule domain.RecurringRule) (domain.RecurringRule, error) {
+	if rule.Name == "" {
+		return rule, errors.New("Name should not be empty.")
+	}
+
+	switch rule.Interval {
+	case domain.Daily, domain.Weekly, domain.Monthly, domain.Yearly:
+	default:
+		return rule, errors.New("Interval should be daily, weekly, monthly or yearly.")
+	}
+
+	if rule.Amount <= 0 {
+		return rule, errors.New("Amount should be positive.")
+	}
+
+	if rule.Every == 0 {
+		rule.Every = 1
+	}
+
+	if rule.Every < 0 {
+		return rule, errors.New("Every should be a positive number.")
+	}
+
+	if !isNumberBetween(rule.DayOfMonth, 0, 31) {
+		return rule, errors.New("Day of month should be from 1 to 31, or 0 for the day of the start date.")
+	}
+
+	if rule.Count < 0 {
+		return rule, errors.New("Count should not be negative.")
+	}
+
+	startDate, err := formatFullDate(rule.StartDate)
+	if err != nil {
+		return rule, fmt.Errorf("Start date: %w", err)
+	}
+	rule.StartDate = startDate
+
+	if rule.EndDate != "" {
+		endDate, err := formatFullDate(rule.EndDate)
+		if err != nil {
+			return rule, fmt.Errorf("End date: %w", err)
+		}
+		if endDate < rule.StartDate {
+			return rule, errors.New("End date should not be before start date.")
+		}
+		rule.EndDate = endDate
+	}
+
+	if rule.CategoryId == 0 {
+		rule.CategoryId, rule.Category, err = c.resolveCategoryName(rule.Category)
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	return rule, nil
+}
+
+// formatFullDate validates and formats a date that must include the day.
+func formatFullDate(dateString string) (string, error) {
+	date, err := formatDate(dateString)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := time.Parse(dateLayout, date); err != nil {
+		return "", errors.New("Date should be YYYY-MM-DD.")
+	}
+
+	return date, nil
+}
+
+// dueOccurrences returns the dates (YYYY-MM-DD) of the occurrences of a rule
+// after its MaterializedUntil date and up to until.
+func dueOccurrences(rule domain.RecurringRule, until time.Time) ([]string, error) {
+	start, err := time.Parse(dateLayout, rule.StartDate)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := []string{}
+	count := 0
+
+	for i := 0; rule.Count == 0 || count < rule.Count; i++ {
+		occurrence := occurrenceDate(rule, start, i)
+		date := occurrence.Format(dateLayout)
+
+		if occurrence.After(until) || (rule.EndDate != "" && date > rule.EndDate) {
+			break
+		}
+
+		// A day of month before the start day skips the first month,
+		// which is not one of the Count occurrences.
+		if occurrence.Before(start) {
+			continue
+		}
+		count++
+
+		if date <= rule.MaterializedUntil {
+			continue
+		}
+
+		dates = append(dates, date)
+	}
+
+	return dates, nil
+}
+
+// occurrenceDate returns the date of the i-th occurrence of a rule.
+// Monthly and yearly days past the end of a month are moved
+// to its last day (31 becomes 30 in April, 28 or 29 in February).
+func occurrenceDate(rule domain.RecurringRule, start time.Time, i int) time.Time {
+	step := i * rule.Every
+
+	day := start.Day()
+	if rule.DayOfMonth != 0 {
+		day = rule.DayOfMonth
+	}
+
+	switch rule.Interval {
+	case domain.Daily:
+		return start.AddDate(0, 0, step)
+	case domain.Weekly:
+		return start.AddDate(0, 0, 7*step)
+	case domain.Yearly:
+		return clampedDate(start.Year()+step, start.Month(), day)
+	default:
+		return clampedDate(start.Year(), start.Month()+time.Month(step), day)
+	}
+}
+
+// clampedDate returns the date of day in year and month (which may overflow
+// into the next years), using the last day of the month if day is too big.
+func clampedDate(year int, month time.Month, day int) time.Time {
+	firstOfMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestDueOccurrences(t *testing.T) {
+	until := time.Date(2023, time.December, 31, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		rule domain.RecurringRule
+		want []string
+	}{
+		{
+			name: "count skips the month before the start day",
+			rule: domain.RecurringRule{Interval: domain.Monthly, Every: 1, DayOfMonth: 5, StartDate: "2023-01-20", Count: 3},
+			want: []string{"2023-02-05", "2023-03-05", "2023-04-05"},
+		},
+		{
+			name: "count includes materialized occurrences",
+			rule: domain.RecurringRule{Interval: domain.Monthly, Every: 1, DayOfMonth: 5, StartDate: "2023-01-20", Count: 3, MaterializedUntil: "2023-02-28"},
+			want: []string{"2023-03-05", "2023-04-05"},
+		},
+		{
+			name: "day of month after the start day",
+			rule: domain.RecurringRule{Interval: domain.Monthly, Every: 2, DayOfMonth: 31, StartDate: "2023-01-10", Count: 3},
+			want: []string{"2023-01-31", "2023-03-31", "2023-05-31"},
+		},
+		{
+			name: "end date",
+			rule: domain.RecurringRule{Interval: domain.Weekly, Every: 1, StartDate: "2023-03-01", EndDate: "2023-03-20"},
+			want: []string{"2023-03-01", "2023-03-08", "2023-03-15"},
+		},
+		{
+			name: "yearly on a later day",
+			rule: domain.RecurringRule{Interval: domain.Yearly, Every: 1, DayOfMonth: 1, StartDate: "2021-06-15", Count: 2},
+			want: []string{"2022-06-01", "2023-06-01"},
+		},
+	}
+
+	for _, test := range tests {
+		got, err := dueOccurrences(test.rule, until)
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: got %v, %v, want %v", test.name, got, err, test.want)
+		}
+	}
+}
+
+func TestValidateRecurringRule(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	valid := domain.RecurringRule{Name: "rent", Amount: 90000, Interval: domain.Monthly, StartDate: "2023-01-15"}
+
+	tests := []struct {
+		name   string
+		change func(rule *domain.RecurringRule)
+		fails  bool
+	}{
+		{"valid", func(rule *domain.RecurringRule) {}, false},
+		{"start day", func(rule *domain.RecurringRule) { rule.DayOfMonth = 0 }, false},
+		{"last day", func(rule *domain.RecurringRule) { rule.DayOfMonth = 31 }, false},
+		{"day too large", func(rule *domain.RecurringRule) { rule.DayOfMonth = 32 }, true},
+		{"negative day", func(rule *domain.RecurringRule) { rule.DayOfMonth = -1 }, true},
+		{"zero amount", func(rule *domain.RecurringRule) { rule.Amount = 0 }, true},
+		{"negative amount", func(rule *domain.RecurringRule) { rule.Amount = -100 }, true},
+		{"no name", func(rule *domain.RecurringRule) { rule.Name = "" }, true},
+	}
+
+	for _, test := range tests {
+		rule := valid
+		test.change(&rule)
+		rule, err := c.validateRecurringRule(rule)
+		if (err != nil) != test.fails {
+			t.Errorf("%s: got error %v, want failing %v", test.name, err, test.fails)
+		}
+		if err == nil && rule.Every != 1 {
+			t.Errorf("%s: got every %d, want 1 by default", test.name, rule.Every)
+		}
+	}
+}
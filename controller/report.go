@@ -0,0 +1,148 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/alx-b/expensetracker/atomicfile"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/report"
+)
+
+// CreateMonthReport returns the data of a month, the data of the month
+// before it and the spending of every category in both.
+func (c *Controller) CreateMonthReport(year int, month time.Month) (domain.MonthReport, error) {
+	if !isNumberBetween(int(month), 1, 12) {
+		return domain.MonthReport{}, errors.New("Month should be from 1 to 12.")
+	}
+
+	current, err := c.CreateMonthData(year, month)
+	if err != nil {
+		return domain.MonthReport{}, err
+	}
+
+	before := time.Date(year, month-1, 1, 0, 0, 0, 0, time.UTC)
+	previous, err := c.CreateMonthData(before.Year(), before.Month())
+	if err != nil {
+		return domain.MonthReport{}, err
+	}
+
+	categories, err := c.db.GetCategories()
+	if err != nil {
+		return domain.MonthReport{}, err
+	}
+
+	parents := map[int]int{}
+	for _, category := range categories {
+		parents[category.Id] = category.ParentId
+	}
+
+	previousSpent := map[int]domain.Money{}
+	for _, category := range previous.Categories {
+		previousSpent[category.CategoryId] = category.Spent
+	}
+
+	monthReport := domain.MonthReport{
+		Month:      current,
+		Previous:   previous,
+		Categories: []domain.CategoryComparison{},
+	}
+
+	seen := map[int]bool{}
+	for _, category := range current.Categories {
+		seen[category.CategoryId] = true
+		monthReport.Categories = append(monthReport.Categories, domain.CategoryComparison{
+			CategoryId:    category.CategoryId,
+			Category:      category.Category,
+			ParentId:      parents[category.CategoryId],
+			Budget:        category.Budget,
+			Spent:         category.Spent,
+			Remaining:     category.Remaining,
+			PreviousSpent: previousSpent[category.CategoryId],
+		})
+	}
+
+	for _, category := range previous.Categories {
+		if seen[category.CategoryId] || category.Spent == 0 {
+			continue
+		}
+		monthReport.Categories = append(monthReport.Categories, domain.CategoryComparison{
+			CategoryId:    category.CategoryId,
+			Category:      category.Category,
+			ParentId:      parents[category.CategoryId],
+			PreviousSpent: category.Spent,
+		})
+	}
+
+	monthReport.Categories = nestCategories(monthReport.Categories)
+
+	return monthReport, nil
+}
+
+// nestCategories orders categories so that each one comes right after
+// its parent, keeping their order otherwise.
+func nestCategories(categories []domain.CategoryComparison) []domain.CategoryComparison {
+	present := map[int]bool{}
+	for _, category := range categories {
+		present[category.CategoryId] = true
+	}
+
+	children := map[int][]domain.CategoryComparison{}
+	for _, category := range categories {
+		parent := category.ParentId
+		if parent == 0 || !present[parent] || category.CategoryId == 0 {
+			parent = -1
+		}
+		children[parent] = append(children[parent], category)
+	}
+
+	nested := []domain.CategoryComparison{}
+	seen := map[int]bool{}
+
+	var add func(parent int)
+	add = func(parent int) {
+		for _, category := range children[parent] {
+			if seen[category.CategoryId] {
+				continue
+			}
+			seen[category.CategoryId] = true
+			nested = append(nested, category)
+			add(category.CategoryId)
+		}
+	}
+	add(-1)
+
+	for _, category := range categories {
+		if !seen[category.CategoryId] {
+			nested = append(nested, category)
+		}
+	}
+
+	return nested
+}
+
+// WriteMonthReport writes the report of a month to the file at path, as
+// HTML or PDF as its extension tells.
+func (c *Controller) WriteMonthReport(year int, month time.Month, path string) error {
+	path = strings.TrimSpace(path)
+	if path == "" {
+		return errors.New("File path should not be empty.")
+	}
+
+	format, ok := report.FormatOf(path)
+	if !ok {
+		return fmt.Errorf("Report file should end in %s.", strings.Join(report.Extensions, ", "))
+	}
+
+	monthReport, err := c.CreateMonthReport(year, month)
+	if err != nil {
+		return err
+	}
+
+	return atomicfile.Write(path, func(w io.Writer) error {
+		return report.Write(w, monthReport, format)
+	})
+}
@@ -0,0 +1,39 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+func TestNestCategories(t *testing.T) {
+	categories := []domain.CategoryComparison{
+		{CategoryId: 4, Category: "Bakery", ParentId: 1},
+		{CategoryId: 5, Category: "Orphan", ParentId: 9},
+		{CategoryId: 1, Category: "Food"},
+		{Category: "Uncategorized"},
+		{CategoryId: 6, Category: "Cycle A", ParentId: 7},
+		{CategoryId: 2, Category: "Rent"},
+		{CategoryId: 7, Category: "Cycle B", ParentId: 6},
+		{CategoryId: 3, Category: "Restaurant", ParentId: 1},
+		{CategoryId: 8, Category: "Croissants", ParentId: 4},
+		{CategoryId: 10, Category: "Orphan child", ParentId: 5},
+	}
+
+	got := []string{}
+	for _, category := range nestCategories(categories) {
+		got = append(got, category.Category)
+	}
+
+	want := []string{
+		"Orphan", "Orphan child",
+		"Food", "Bakery", "Croissants", "Restaurant",
+		"Uncategorized",
+		"Rent",
+		"Cycle A", "Cycle B",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
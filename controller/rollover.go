@@ -0,0 +1,149 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+const (
+	rolloverModeKey = "rollover_mode"
+	rolloverCapKey  = "rollover_cap"
+)
+
+// GetRolloverSettings returns how leftovers carry into the next month,
+// RolloverNone when never set.
+func (c *Controller) GetRolloverSettings() (domain.RolloverSettings, error) {
+	settings := domain.RolloverSettings{Mode: domain.RolloverNone}
+
+	mode, ok, err := c.db.GetSetting(rolloverModeKey)
+	if err != nil {
+		return settings, err
+	}
+	if ok {
+		settings.Mode = domain.RolloverMode(mode)
+	}
+
+	limit, ok, err := c.db.GetSetting(rolloverCapKey)
+	if err != nil {
+		return settings, err
+	}
+	if ok {
+		settings.Cap, err = domain.ParseMoney(limit)
+		if err != nil {
+			return settings, err
+		}
+	}
+
+	return settings, nil
+}
+
+// UpdateRolloverSettings changes how leftovers carry into the next month.
+func (c *Controller) UpdateRolloverSettings(settings domain.RolloverSettings) error {
+	switch settings.Mode {
+	case domain.RolloverNone, domain.RolloverFull, domain.RolloverNegative:
+	case domain.RolloverCapped:
+		if settings.Cap < 0 {
+			return errors.New("Rollover cap should not be negative.")
+		}
+	default:
+		return errors.New("Rollover should be none, full, capped or only-negative.")
+	}
+
+	if err := c.db.UpdateSetting(rolloverModeKey, string(settings.Mode)); err != nil {
+		return err
+	}
+
+	return c.db.UpdateSetting(rolloverCapKey, settings.Cap.String())
+}
+
+// calculateCarriedOver returns the leftover carried into a month, walking
+// the chain of months from the month of the oldest expense. Each month
+// carries its own leftover, itself including what it received. Expenses of
+// the whole chain are read at once and totalled per month.
+func (c *Controller) calculateCarriedOver(year int, month time.Month) (domain.Money, error) {
+	settings, err := c.GetRolloverSettings()
+	if err != nil {
+		return 0, err
+	}
+
+	if settings.Mode == domain.RolloverNone {
+		return 0, nil
+	}
+
+	first, ok, err := c.db.GetFirstExpenseDate()
+	if err != nil || !ok {
+		return 0, err
+	}
+
+	if len(first) > 7 {
+		first = first[:7]
+	}
+
+	start, err := time.Parse("2006-01", first)
+	if err != nil {
+		return 0, fmt.Errorf("Could not read date of oldest expense: %w", err)
+	}
+
+	target := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if !start.Before(target) {
+		return 0, nil
+	}
+
+	expenses, err := c.db.GetExpensesInRange(start.Format(dateLayout), target.AddDate(0, 0, -1).Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+
+	spent := map[string]domain.Money{}
+	for _, expense := range expenses {
+		if len(expense.Date) >= 7 && expense.IsSpending() {
+			spent[expense.Date[:7]] = spent[expense.Date[:7]].Add(expense.Amount)
+		}
+	}
+
+	defaultBudget, err := c.db.GetDefaultBudget()
+	if err != nil {
+		return 0, err
+	}
+
+	carried := domain.Money(0)
+
+	for current := start; current.Before(target); current = current.AddDate(0, 1, 0) {
+		yearMonth := current.Format("2006-01")
+
+		budget, ok, err := c.db.GetBudgetWithYearMonth(yearMonth)
+		if err != nil {
+			return 0, err
+		}
+		if !ok {
+			budget = defaultBudget
+		}
+
+		leftover := budget.Add(carried).Sub(spent[yearMonth])
+		carried = carryOver(settings, leftover)
+	}
+
+	return carried, nil
+}
+
+// carryOver returns the part of a leftover carried into the next month.
+func carryOver(settings domain.RolloverSettings, leftover domain.Money) domain.Money {
+	switch settings.Mode {
+	case domain.RolloverFull:
+		return leftover
+	case domain.RolloverCapped:
+		if leftover > settings.Cap {
+			return settings.Cap
+		}
+		return leftover
+	case domain.RolloverNegative:
+		if leftover < 0 {
+			return leftover
+		}
+	}
+
+	return 0
+}
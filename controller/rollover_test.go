@@ -0,0 +1,68 @@
+package controller
+
+import (
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestCalculateCarriedOver(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings domain.RolloverSettings
+		year     int
+		month    time.Month
+		want     domain.Money
+	}{
+		{"none", domain.RolloverSettings{Mode: domain.RolloverNone}, 2023, time.February, 0},
+		{"before first expense", domain.RolloverSettings{Mode: domain.RolloverFull}, 2022, time.November, 0},
+		{"first month", domain.RolloverSettings{Mode: domain.RolloverFull}, 2022, time.December, 0},
+		{"across year", domain.RolloverSettings{Mode: domain.RolloverFull}, 2023, time.January, 20000},
+		{"across months", domain.RolloverSettings{Mode: domain.RolloverFull}, 2023, time.February, -10000},
+		{"negative leftover", domain.RolloverSettings{Mode: domain.RolloverFull}, 2023, time.March, -40000},
+		{"empty months", domain.RolloverSettings{Mode: domain.RolloverFull}, 2023, time.May, 160000},
+		{"capped", domain.RolloverSettings{Mode: domain.RolloverCapped, Cap: 5000}, 2023, time.January, 5000},
+		{"capped negative", domain.RolloverSettings{Mode: domain.RolloverCapped, Cap: 5000}, 2023, time.March, -55000},
+		{"negative", domain.RolloverSettings{Mode: domain.RolloverNegative}, 2023, time.January, 0},
+		{"negative carried", domain.RolloverSettings{Mode: domain.RolloverNegative}, 2023, time.March, -60000},
+		{"negative recovered", domain.RolloverSettings{Mode: domain.RolloverNegative}, 2023, time.April, 0},
+	}
+
+	// Budgets of 1000.00, 800.00 in January and 1200.00 in February.
+	// Spending: 800.00 in December, 1100.00 in January and 1500.00
+	// in February, nothing in March and April.
+	c := CreateController(memory.CreateStorage())
+	check(t, c.UpdateDefaultBudget("1000"))
+	check(t, c.InsertBudgetMonth("800", "2023-01"))
+	check(t, c.InsertBudgetMonth("1200", "2023-02"))
+	check(t, c.AddExpense(domain.Expense{Name: "rent", Date: "2022-12-05", Amount: 80000}))
+	check(t, c.AddExpense(domain.Expense{Name: "rent", Date: "2023-01-05", Amount: 80000}))
+	check(t, c.AddExpense(domain.Expense{Name: "heating", Date: "2023-01-31", Amount: 30000}))
+	check(t, c.AddExpense(domain.Expense{Name: "rent", Date: "2023-02-01", Amount: 150000}))
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			check(t, c.UpdateRolloverSettings(test.settings))
+
+			got, err := c.calculateCarriedOver(test.year, test.month)
+			check(t, err)
+			if got != test.want {
+				t.Errorf("carried %s into %d-%02d, want %s", got, test.year, test.month, test.want)
+			}
+		})
+	}
+}
+
+func TestCalculateCarriedOverWithoutExpenses(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.UpdateDefaultBudget("1000"))
+	check(t, c.UpdateRolloverSettings(domain.RolloverSettings{Mode: domain.RolloverFull}))
+
+	got, err := c.calculateCarriedOver(2023, time.March)
+	check(t, err)
+	if got != 0 {
+		t.Errorf("carried %s without expenses, want 0.00", got)
+	}
+}
@@ -0,0 +1,419 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetPayees returns every payee.
+func (c *Controller) GetPayees() ([]domain.Payee, error) {
+	return c.db.GetPayees()
+}
+
+// GetCategorizationRules returns every categorization rule
+// in the order they are tried.
+func (c *Controller) GetCategorizationRules() ([]domain.CategorizationRule, error) {
+	return c.db.GetCategorizationRules()
+}
+
+// AddCategorizationRule adds CategorizationRule to database if valid,
+// tried after every existing rule.
+func (c *Controller) AddCategorizationRule(rule domain.CategorizationRule) error {
+	rule, err := c.validateCategorizationRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rules, err := c.db.GetCategorizationRules()
+	if err != nil {
+		return err
+	}
+
+	rule.Priority = 1
+	if len(rules) > 0 {
+		rule.Priority = rules[len(rules)-1].Priority + 1
+	}
+
+	return c.db.InsertCategorizationRule(rule)
+}
+
+// UpdateCategorizationRule updates an existing CategorizationRule in
+// database if valid, keeping its priority.
+func (c *Controller) UpdateCategorizationRule(rule domain.CategorizationRule) error {
+	existing, err := c.findCategorizationRule(rule.Id)
+	if err != nil {
+		return err
+	}
+
+	rule, err = c.validateCategorizationRule(rule)
+	if err != nil {
+		return err
+	}
+
+	rule.Priority = existing.Priority
+
+	return c.db.UpdateCategorizationRule(rule)
+}
+
+// RemoveCategorizationRule removes CategorizationRule from database if valid id.
+func (c *Controller) RemoveCategorizationRule(id int) error {
+	return c.db.DeleteCategorizationRule(id)
+}
+
+// MoveCategorizationRule moves a rule offset places in the order rules
+// are tried, negative offsets moving it towards the first rule.
+func (c *Controller) MoveCategorizationRule(id, offset int) error {
+	rules, err := c.db.GetCategorizationRules()
+	if err != nil {
+		return err
+	}
+
+	from := -1
+	for i := range rules {
+		if rules[i].Id == id {
+			from = i
+		}
+	}
+
+	if from == -1 {
+		return errors.New("Categorization rule does not exist.")
+	}
+
+	to := from + offset
+	if to < 0 {
+		to = 0
+	}
+	if to > len(rules)-1 {
+		to = len(rules) - 1
+	}
+
+	rule := rules[from]
+	rules = append(rules[:from], rules[from+1:]...)
+	rules = append(rules[:to], append([]domain.CategorizationRule{rule}, rules[to:]...)...)
+
+	// Renumber every rule so priorities stay unique and contiguous.
+	for i := range rules {
+		if rules[i].Priority == i+1 {
+			continue
+		}
+		rules[i].Priority = i + 1
+		if err := c.db.UpdateCategorizationRule(rules[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// PreviewReapplyRules returns every past expense the categorization rules
+// would change the category, payee or tags of, as it is and as it would be.
+// Rules override the category and payee of the expenses they match.
+func (c *Controller) PreviewReapplyRules() ([]domain.RuleChange, error) {
+	rules, err := c.db.GetCategorizationRules()
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, err := c.db.GetExpenses()
+	if err != nil {
+		return nil, err
+	}
+
+	changes := []domain.RuleChange{}
+
+	for _, expense := range expenses {
+		after := ruleFields(expense, expense, applyRules(expense, rules, true))
+		if sameRuleFields(expense, after) {
+			continue
+		}
+
+		changes = append(changes, domain.RuleChange{Before: expense, After: after})
+	}
+
+	return changes, nil
+}
+
+// ApplyRuleChanges gives the expenses of changes returned by
+// PreviewReapplyRules their new category, payee and tags, as a single
+// change to undo. Expenses are read again and only these fields change,
+// tags being added, so edits made since the preview are kept.
+func (c *Controller) ApplyRuleChanges(changes []domain.RuleChange) error {
+	befores := []domain.Expense{}
+	afters := []domain.Expense{}
+
+	var err error
+	for _, change := range changes {
+		var before domain.Expense
+		before, err = c.findExpense(change.Before.Id)
+		if err != nil {
+			break
+		}
+
+		after := ruleFields(before, change.Before, change.After)
+		if sameRuleFields(before, after) {
+			continue
+		}
+
+		if err = c.updateExpense(before, after); err != nil {
+			break
+		}
+
+		befores = append(befores, before)
+		afters = append(afters, after)
+	}
+
+	if len(afters) > 0 {
+		c.record(
+			fmt.Sprintf("re-apply rules to %d expenses", len(afters)),
+			func() error {
+				for i := len(befores) - 1; i >= 0; i-- {
+					if err := c.updateExpense(afters[i], befores[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+			func() error {
+				for i := range afters {
+					if err := c.updateExpense(befores[i], afters[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			},
+		)
+	}
+
+	return err
+}
+
+// ruleFields returns expense with the changes rules made from before to
+// after: the category and payee when they changed and the tags added.
+func ruleFields(expense, before, after domain.Expense) domain.Expense {
+	if after.CategoryId != before.CategoryId {
+		expense.CategoryId = after.CategoryId
+		expense.Category = after.Category
+	}
+
+	if after.PayeeId != before.PayeeId {
+		expense.PayeeId = after.PayeeId
+		expense.Payee = after.Payee
+	}
+
+	tags := addedTags(before.Tags, after.Tags)
+	if len(tags) > 0 {
+		expense.Tags = domain.NormalizeTags(append(append([]string{}, expense.Tags...), tags...))
+	}
+
+	return expense
+}
+
+// sameRuleFields reports whether two expenses have the same category,
+// payee and tags.
+func sameRuleFields(a, b domain.Expense) bool {
+	return a.CategoryId == b.CategoryId &&
+		a.PayeeId == b.PayeeId &&
+		strings.Join(domain.NormalizeTags(a.Tags), ",") == strings.Join(domain.NormalizeTags(b.Tags), ",")
+}
+
+// addedTags returns the tags in after that are not in before.
+func addedTags(before, after []string) []string {
+	had := map[string]bool{}
+	for _, tag := range domain.NormalizeTags(before) {
+		had[tag] = true
+	}
+
+	added := []string{}
+	for _, tag := range domain.NormalizeTags(after) {
+		if !had[tag] {
+			added = append(added, tag)
+		}
+	}
+
+	return added
+}
+
+// autoCategorize fills the category, payee and tags of an expense
+// from the first matching categorization rule, leaving the category
+// and payee alone when they are already set.
+func (c *Controller) autoCategorize(expense domain.Expense) (domain.Expense, error) {
+	rules, err := c.db.GetCategorizationRules()
+	if err != nil {
+		return expense, err
+	}
+
+	return applyRules(expense, rules, false), nil
+}
+
+// applyRules applies the first rule matching an expense to it. Its category
+// and payee are only replaced when overwrite is true or they are not set.
+// Transfers and split lines are never categorized by rules.
+func applyRules(expense domain.Expense, rules []domain.CategorizationRule, overwrite bool) domain.Expense {
+	if expense.IsTransfer() {
+		return expense
+	}
+
+	for _, rule := range rules {
+		if !ruleMatches(rule, expense) {
+			continue
+		}
+
+		hasCategory := expense.CategoryId != 0 || domain.NormalizeCategoryName(expense.Category) != ""
+		if rule.CategoryId != 0 && len(expense.Splits) == 0 && (overwrite || !hasCategory) {
+			expense.CategoryId = rule.CategoryId
+			expense.Category = rule.Category
+		}
+
+		hasPayee := expense.PayeeId != 0 || strings.TrimSpace(expense.Payee) != ""
+		if rule.PayeeId != 0 && (overwrite || !hasPayee) {
+			expense.PayeeId = rule.PayeeId
+			expense.Payee = rule.Payee
+		}
+
+		expense.Tags = domain.NormalizeTags(append(append([]string{}, expense.Tags...), rule.Tags...))
+
+		return expense
+	}
+
+	return expense
+}
+
+// ruleMatches reports whether the name and amount of an expense match a rule.
+// Exact and prefix matches ignore case and surrounding spaces.
+func ruleMatches(rule domain.CategorizationRule, expense domain.Expense) bool {
+	if expense.Amount < rule.MinAmount || (rule.MaxAmount != 0 && expense.Amount > rule.MaxAmount) {
+		return false
+	}
+
+	name := strings.ToLower(strings.TrimSpace(expense.Name))
+	pattern := strings.ToLower(strings.TrimSpace(rule.Pattern))
+
+	switch rule.MatchType {
+	case domain.MatchExact:
+		return name == pattern
+	case domain.MatchPrefix:
+		return strings.HasPrefix(name, pattern)
+	case domain.MatchRegex:
+		matched, err := regexp.MatchString(rule.Pattern, expense.Name)
+		return err == nil && matched
+	}
+
+	return false
+}
+
+// findCategorizationRule returns the rule with the given id.
+func (c *Controller) findCategorizationRule(id int) (domain.CategorizationRule, error) {
+	rules, err := c.db.GetCategorizationRules()
+	if err != nil {
+		return domain.CategorizationRule{}, err
+	}
+
+	for _, rule := range rules {
+		if rule.Id == id {
+			return rule, nil
+		}
+	}
+
+	return domain.CategorizationRule{}, errors.New("Categorization rule does not exist.")
+}
+
+// validateCategorizationRule checks its pattern and amount range and
+// resolves its category and payee, creating them if missing.
+func (c *Controller) validateCategorizationRule(rule domain.CategorizationRule) (domain.CategorizationRule, error) {
+	if rule.MatchType == "" {
+		rule.MatchType = domain.MatchExact
+	}
+
+	switch rule.MatchType {
+	case domain.MatchExact, domain.MatchPrefix:
+		rule.Pattern = strings.TrimSpace(rule.Pattern)
+	case domain.MatchRegex:
+		if _, err := regexp.Compile(rule.Pattern); err != nil {
+			return rule, errors.New("Pattern should be a valid regular expression.")
+		}
+	default:
+		return rule, errors.New("Match should be exact, prefix or regex.")
+	}
+
+	if rule.Pattern == "" {
+		return rule, errors.New("Pattern should not be empty.")
+	}
+
+	if rule.MinAmount < 0 || rule.MaxAmount < 0 {
+		return rule, errors.New("Amount range should not be negative.")
+	}
+
+	if rule.MaxAmount != 0 && rule.MaxAmount < rule.MinAmount {
+		return rule, errors.New("Maximum amount should not be below minimum amount.")
+	}
+
+	var err error
+
+	if rule.CategoryId == 0 {
+		rule.CategoryId, rule.Category, err = c.resolveCategoryName(rule.Category)
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	if rule.PayeeId == 0 {
+		rule.PayeeId, rule.Payee, err = c.resolvePayeeName(rule.Payee)
+		if err != nil {
+			return rule, err
+		}
+	}
+
+	rule.Tags = domain.NormalizeTags(rule.Tags)
+
+	if rule.CategoryId == 0 && rule.PayeeId == 0 && len(rule.Tags) == 0 {
+		return rule, errors.New("Rule should set a category, a payee or tags.")
+	}
+
+	return rule, nil
+}
+
+// resolvePayee sets the PayeeId of an expense from its Payee name,
+// creating the payee if it does not exist yet.
+func (c *Controller) resolvePayee(expense domain.Expense) (domain.Expense, error) {
+	if expense.PayeeId != 0 {
+		return expense, nil
+	}
+
+	id, name, err := c.resolvePayeeName(expense.Payee)
+	if err != nil {
+		return expense, err
+	}
+
+	expense.PayeeId = id
+	expense.Payee = name
+
+	return expense, nil
+}
+
+// resolvePayeeName returns the id and stored name of the payee named name,
+// creating it if missing. An empty name resolves to no payee (id 0).
+func (c *Controller) resolvePayeeName(name string) (int, string, error) {
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return 0, "", nil
+	}
+
+	payee, found, err := c.db.GetPayeeWithName(name)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !found {
+		payee.Name = name
+		payee.Id, err = c.db.InsertPayee(payee)
+		if err != nil {
+			return 0, "", err
+		}
+	}
+
+	return payee.Id, payee.Name, nil
+}
@@ -0,0 +1,110 @@
+package controller
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+func TestApplyRuleChanges(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddCategory(domain.Category{Name: "Food"}))
+	check(t, c.AddExpense(domain.Expense{Name: "bakery", Date: "2023-03-01", Amount: 350}))
+
+	check(t, c.AddCategorizationRule(domain.CategorizationRule{MatchType: domain.MatchPrefix, Pattern: "bak", Category: "Food"}))
+
+	changes, err := c.PreviewReapplyRules()
+	check(t, err)
+	if len(changes) != 1 || changes[0].After.Category != "Food" {
+		t.Fatalf("got changes %+v, want bakery moved to Food", changes)
+	}
+
+	edited := changes[0].Before
+	edited.Amount = 400
+	check(t, c.UpdateExpense(edited))
+
+	check(t, c.ApplyRuleChanges(changes))
+
+	expense, err := c.findExpense(edited.Id)
+	check(t, err)
+	if expense.Category != "Food" || expense.Amount != 400 {
+		t.Errorf("got %+v, want the edited amount in Food", expense)
+	}
+
+	action, err := c.Undo()
+	check(t, err)
+	if action.Description != "re-apply rules to 1 expenses" {
+		t.Errorf("undid %q", action.Description)
+	}
+
+	expense, err = c.findExpense(edited.Id)
+	check(t, err)
+	if expense.CategoryId != 0 || expense.Amount != 400 {
+		t.Errorf("got %+v after undo, want no category and the edited amount", expense)
+	}
+
+	_, err = c.Redo()
+	check(t, err)
+
+	expense, err = c.findExpense(edited.Id)
+	check(t, err)
+	if expense.Category != "Food" {
+		t.Errorf("got %+v after redo, want Food", expense)
+	}
+}
+
+func TestApplyRuleChangesPayeeAndTags(t *testing.T) {
+	c := CreateController(memory.CreateStorage())
+	check(t, c.AddExpense(domain.Expense{Name: "bakery", Date: "2023-03-01", Amount: 350, Tags: []string{"food"}}))
+	check(t, c.AddExpense(domain.Expense{Name: "market", Date: "2023-03-02", Amount: 1200}))
+
+	check(t, c.AddCategorizationRule(domain.CategorizationRule{MatchType: domain.MatchExact, Pattern: "bakery", Payee: "Boulangerie"}))
+	check(t, c.AddCategorizationRule(domain.CategorizationRule{MatchType: domain.MatchExact, Pattern: "market", Tags: []string{"groceries"}}))
+
+	changes, err := c.PreviewReapplyRules()
+	check(t, err)
+	if len(changes) != 2 {
+		t.Fatalf("got changes %+v, want the payee of bakery and the tags of market", changes)
+	}
+
+	check(t, c.ApplyRuleChanges(changes))
+
+	bakery, err := c.findExpense(changes[0].Before.Id)
+	check(t, err)
+	if bakery.Payee != "Boulangerie" || !reflect.DeepEqual(bakery.Tags, []string{"food"}) {
+		t.Errorf("got %+v, want Boulangerie as payee and its tags kept", bakery)
+	}
+
+	market, err := c.findExpense(changes[1].Before.Id)
+	check(t, err)
+	if market.Payee != "" || !reflect.DeepEqual(market.Tags, []string{"groceries"}) {
+		t.Errorf("got %+v, want the groceries tag", market)
+	}
+
+	changes, err = c.PreviewReapplyRules()
+	check(t, err)
+	if len(changes) != 0 {
+		t.Errorf("got changes %+v after applying them, want none", changes)
+	}
+
+	_, err = c.Undo()
+	check(t, err)
+
+	bakery, err = c.findExpense(bakery.Id)
+	check(t, err)
+	market, err = c.findExpense(market.Id)
+	check(t, err)
+	if bakery.PayeeId != 0 || len(market.Tags) != 0 {
+		t.Errorf("got %+v and %+v after undo, want no payee and no tags", bakery, market)
+	}
+}
+
+// check fails the test on errors.
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+const trashRetentionKey = "trash_retention_days"
+
+// defaultTrashRetention is the number of days expenses stay in the trash
+// when never set.
+const defaultTrashRetention = 30
+
+// GetTrash returns the expenses in the trash, the latest deleted first.
+func (c *Controller) GetTrash() ([]domain.Expense, error) {
+	return c.db.GetDeletedExpenses()
+}
+
+// RestoreExpense takes an expense out of the trash.
+func (c *Controller) RestoreExpense(id int) error {
+	expense, err := c.findExpense(id)
+	if err != nil {
+		return err
+	}
+
+	if err := c.restoreExpense(id); err != nil {
+		return err
+	}
+
+	c.record(
+		fmt.Sprintf("restore %q", expense.Name),
+		func() error { return c.deleteExpense(id) },
+		func() error { return c.restoreExpense(id) },
+	)
+
+	return nil
+}
+
+// PurgeExpense permanently deletes an expense in the trash,
+// along with its attachments. It cannot be undone.
+func (c *Controller) PurgeExpense(id int) error {
+	return c.purgeExpense(id)
+}
+
+// PurgeExpiredTrash permanently deletes the expenses in the trash
+// for longer than the retention period and returns how many.
+func (c *Controller) PurgeExpiredTrash() (int, error) {
+	days, err := c.GetTrashRetention()
+	if err != nil || days == 0 {
+		return 0, err
+	}
+
+	before := c.now().UTC().AddDate(0, 0, -days).Format(time.RFC3339)
+
+	expenses, err := c.db.GetDeletedExpenses()
+	if err != nil {
+		return 0, err
+	}
+
+	purged := 0
+
+	for _, expense := range expenses {
+		if expense.DeletedAt >= before {
+			continue
+		}
+		if err := c.purgeExpense(expense.Id); err != nil {
+			return purged, err
+		}
+		purged++
+	}
+
+	return purged, nil
+}
+
+// GetTrashRetention returns the number of days expenses stay in the trash
+// before being purged, 0 meaning forever.
+func (c *Controller) GetTrashRetention() (int, error) {
+	value, ok, err := c.db.GetSetting(trashRetentionKey)
+	if err != nil || !ok {
+		return defaultTrashRetention, err
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil {
+		return defaultTrashRetention, fmt.Errorf("Could not read trash retention: %w", err)
+	}
+
+	return days, nil
+}
+
+// UpdateTrashRetention changes the number of days expenses stay in the
+// trash before being purged, 0 meaning forever.
+func (c *Controller) UpdateTrashRetention(days int) error {
+	if days < 0 {
+		return errors.New("Trash retention should not be negative.")
+	}
+
+	return c.db.UpdateSetting(trashRetentionKey, strconv.Itoa(days))
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetAccounts returns every account by name.
+func (db *DB) GetAccounts() ([]domain.Account, error) {
+	rows, err := db.db.Query("SELECT id, name, type, opening_balance FROM accounts ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.Account{}
+
+	for rows.Next() {
+		account := domain.Account{}
+		err := rows.Scan(
+			&account.Id,
+			&account.Name,
+			&account.Type,
+			&account.OpeningBalance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		list = append(list, account)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// InsertAccount inserts a given account into accounts table.
+func (db DB) InsertAccount(account domain.Account) error {
+	result, err := db.db.Exec(
+		"INSERT INTO accounts (name, type, opening_balance) VALUES (?,?,?)",
+		account.Name,
+		account.Type,
+		account.OpeningBalance,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	_, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateAccount updates an existing account by its Id.
+func (db DB) UpdateAccount(account domain.Account) error {
+	result, err := db.db.Exec(
+		"UPDATE accounts SET name=?, type=?, opening_balance=? WHERE id=?",
+		account.Name,
+		account.Type,
+		account.OpeningBalance,
+		account.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find account with id %d", account.Id)
+	}
+
+	return nil
+}
+
+// DeleteAccount deletes an account by its Id. Its expenses are kept
+// without account, and transfers to it are kept as plain expenses.
+func (db DB) DeleteAccount(id int) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	statements := []string{
+		"UPDATE expenses SET account_id=NULL WHERE account_id=?",
+		"UPDATE expenses SET to_account_id=NULL, kind='expense' WHERE to_account_id=?",
+		"DELETE FROM accounts WHERE id=?",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, id); err != nil {
+			return fmt.Errorf("Could not delete from table: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetAccountTransactions returns every expense, income and transfer of an
+// account, oldest first.
+func (db *DB) GetAccountTransactions(accountId int) ([]domain.Expense, error) {
+	return db.queryExpenses(
+		selectExpenses+"WHERE "+notDeleted+" AND (e.account_id=? OR e.to_account_id=?) ORDER BY e.day, e.id",
+		accountId,
+		accountId,
+	)
+}
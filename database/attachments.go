@@ -0,0 +1,181 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/alx-b/expensetracker/atomicfile"
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetAttachments returns the attachments of an expense by its Id.
+func (db *DB) GetAttachments(expenseId int) ([]domain.Attachment, error) {
+	rows, err := db.db.Query(
+		`SELECT id, expense_id, name, file, hash, mime_type, size, added_at
+FROM attachments WHERE expense_id=? ORDER BY id`,
+		expenseId,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.Attachment{}
+
+	for rows.Next() {
+		attachment := domain.Attachment{}
+		err := rows.Scan(
+			&attachment.Id,
+			&attachment.ExpenseId,
+			&attachment.Name,
+			&attachment.File,
+			&attachment.Hash,
+			&attachment.MimeType,
+			&attachment.Size,
+			&attachment.AddedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		list = append(list, attachment)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// InsertAttachment stores the content of an attachment under its File name,
+// unless a file with the same content is already stored, and inserts its
+// metadata into attachments table.
+func (db DB) InsertAttachment(attachment domain.Attachment, content []byte) error {
+	if err := db.writeAttachmentFile(attachment.File, content); err != nil {
+		return err
+	}
+
+	_, err := db.db.Exec(
+		`INSERT INTO attachments (expense_id, name, file, hash, mime_type, size, added_at)
+VALUES (?,?,?,?,?,?,?)`,
+		attachment.ExpenseId,
+		attachment.Name,
+		attachment.File,
+		attachment.Hash,
+		attachment.MimeType,
+		attachment.Size,
+		attachment.AddedAt,
+	)
+	if err != nil {
+		// Do not leave behind a file nothing refers to.
+		db.collectAttachmentFiles([]string{attachment.File})
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteAttachment deletes an attachment from attachments table by its Id
+// and removes its file when no other attachment refers to it.
+func (db DB) DeleteAttachment(id int) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	files, err := attachmentFiles(tx, "SELECT file FROM attachments WHERE id=?", id)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.Exec("DELETE FROM attachments WHERE id=?", id); err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Could not commit transaction: %w", err)
+	}
+
+	return db.collectAttachmentFiles(files)
+}
+
+// AttachmentPath returns the location of a stored attachment file.
+func (db DB) AttachmentPath(file string) string {
+	return filepath.Join(db.attachmentsDir, filepath.Base(file))
+}
+
+// writeAttachmentFile writes content to the attachments directory under
+// file, through a temporary file so a partial write is never visible.
+func (db DB) writeAttachmentFile(file string, content []byte) error {
+	path := db.AttachmentPath(file)
+
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(db.attachmentsDir, 0o755); err != nil {
+		return fmt.Errorf("Could not create attachments directory: %w", err)
+	}
+
+	if err := atomicfile.WriteBytes(path, content); err != nil {
+		return fmt.Errorf("Could not store attachment file: %w", err)
+	}
+
+	return nil
+}
+
+// attachmentFiles returns the files of the attachments selected by query.
+func attachmentFiles(tx *sql.Tx, query string, args ...any) ([]string, error) {
+	rows, err := tx.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	files := []string{}
+
+	for rows.Next() {
+		file := ""
+		if err := rows.Scan(&file); err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		files = append(files, file)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return files, nil
+}
+
+// collectAttachmentFiles removes the given files when no attachment
+// refers to them anymore.
+func (db DB) collectAttachmentFiles(files []string) error {
+	for _, file := range files {
+		count := 0
+		err := db.db.QueryRow("SELECT COUNT(*) FROM attachments WHERE file=?", file).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("Could not query database: %w", err)
+		}
+
+		if count > 0 {
+			continue
+		}
+
+		err = os.Remove(db.AttachmentPath(file))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Could not remove attachment file: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+func TestAttachmentFiles(t *testing.T) {
+	db, err := CreateDB(filepath.Join(t.TempDir(), "db.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	ids, err := db.InsertExpenses([]domain.Expense{
+		{Name: "lunch", Date: "2023-02-01", Amount: 1250, Kind: domain.KindExpense},
+		{Name: "dinner", Date: "2023-02-02", Amount: 2000, Kind: domain.KindExpense},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	attachment := domain.Attachment{Name: "receipt.png", File: "abc.png", Hash: "abc", MimeType: "image/png", Size: 3}
+	for _, id := range append(ids, ids[0]) {
+		attachment.ExpenseId = id
+		if err := db.InsertAttachment(attachment, []byte("png")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := db.AttachmentPath("abc.png")
+	exists := func() bool {
+		_, err := os.Stat(path)
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("got %d stored files, want one for the same content", len(entries))
+	}
+
+	lunch, err := db.GetAttachments(ids[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.DeleteAttachment(lunch[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if !exists() {
+		t.Fatal("removed a file other attachments use")
+	}
+
+	if err := db.DeleteExpense(ids[0], "2023-02-05T10:00:00Z"); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.PurgeExpense(ids[0]); err != nil {
+		t.Fatal(err)
+	}
+	if !exists() {
+		t.Fatal("purged a file another expense uses")
+	}
+
+	if err := db.DiscardExpenses(ids[1:]); err != nil {
+		t.Fatal(err)
+	}
+	if exists() {
+		t.Error("kept a file no attachment uses")
+	}
+
+	// Files already gone are not an error.
+	if err := db.collectAttachmentFiles([]string{"abc.png", "missing.png"}); err != nil {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,108 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// InsertAuditEntries appends entries to audit_log table,
+// all of them or none.
+func (db DB) InsertAuditEntries(entries []domain.AuditEntry) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	for _, entry := range entries {
+		_, err := tx.Exec(
+			`INSERT INTO audit_log (timestamp, user, action, entity, entity_key, before, after)
+VALUES (?,?,?,?,?,?,?)`,
+			entry.Timestamp,
+			entry.User,
+			entry.Action,
+			entry.Entity,
+			entry.Key,
+			entry.Before,
+			entry.After,
+		)
+		if err != nil {
+			return fmt.Errorf("Could not insert into table: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("Could not commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+// GetAuditEntries returns the entries of audit_log table
+// matching a query, the latest first.
+func (db *DB) GetAuditEntries(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	conditions := []string{}
+	args := []any{}
+
+	for _, filter := range []struct {
+		condition string
+		value     string
+	}{
+		{"entity=?", string(query.Entity)},
+		{"entity_key=?", query.Key},
+		{"user=?", query.User},
+		{"timestamp>=?", query.From},
+		{"timestamp<=?", query.To},
+	} {
+		if filter.value != "" {
+			conditions = append(conditions, filter.condition)
+			args = append(args, filter.value)
+		}
+	}
+
+	statement := "SELECT id, timestamp, user, action, entity, entity_key, before, after FROM audit_log"
+	if len(conditions) > 0 {
+		statement += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	statement += " ORDER BY id DESC"
+	if query.Limit > 0 {
+		statement += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := db.db.Query(statement, args...)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	entries := []domain.AuditEntry{}
+
+	for rows.Next() {
+		entry := domain.AuditEntry{}
+		err := rows.Scan(
+			&entry.Id,
+			&entry.Timestamp,
+			&entry.User,
+			&entry.Action,
+			&entry.Entity,
+			&entry.Key,
+			&entry.Before,
+			&entry.After,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return entries, nil
+}
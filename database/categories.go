@@ -0,0 +1,211 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// scanCategory scans a row of id, name, parent_id, color, icon, archived.
+func scanCategory(row interface{ Scan(...any) error }) (domain.Category, error) {
+	category := domain.Category{}
+	parentId := sql.NullInt64{}
+
+	err := row.Scan(
+		&category.Id,
+		&category.Name,
+		&parentId,
+		&category.Color,
+		&category.Icon,
+		&category.Archived,
+	)
+	if err != nil {
+		return domain.Category{}, err
+	}
+
+	category.ParentId = int(parentId.Int64)
+
+	return category, nil
+}
+
+// GetCategories returns every category, archived ones included, by name.
+func (db *DB) GetCategories() ([]domain.Category, error) {
+	rows, err := db.db.Query("SELECT id, name, parent_id, color, icon, archived FROM categories ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.Category{}
+
+	for rows.Next() {
+		category, err := scanCategory(rows)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		list = append(list, category)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetCategoryWithName returns the category matching name case-insensitively
+// and whether it exists.
+func (db *DB) GetCategoryWithName(name string) (domain.Category, bool, error) {
+	row := db.db.QueryRow("SELECT id, name, parent_id, color, icon, archived FROM categories WHERE name=?", name)
+
+	category, err := scanCategory(row)
+	if err == sql.ErrNoRows {
+		return domain.Category{}, false, nil
+	}
+	if err != nil {
+		return domain.Category{}, false, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	return category, true, nil
+}
+
+// InsertCategory inserts a category and returns its new id.
+func (db DB) InsertCategory(category domain.Category) (int, error) {
+	result, err := db.db.Exec(
+		"INSERT INTO categories (name, parent_id, color, icon, archived) VALUES (?,?,?,?,?)",
+		category.Name,
+		nullableId(category.ParentId),
+		category.Color,
+		category.Icon,
+		category.Archived,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("Could not retrieve last inserted id: %w", err)
+	}
+
+	return int(id), nil
+}
+
+// UpdateCategory updates an existing category by its Id.
+func (db DB) UpdateCategory(category domain.Category) error {
+	result, err := db.db.Exec(
+		"UPDATE categories SET name=?, parent_id=?, color=?, icon=?, archived=? WHERE id=?",
+		category.Name,
+		nullableId(category.ParentId),
+		category.Color,
+		category.Icon,
+		category.Archived,
+		category.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find category with id %d", category.Id)
+	}
+
+	return nil
+}
+
+// DeleteCategory deletes a category and its budgets by its Id. Its expenses
+// and rules lose their category and its children become top level categories.
+func (db DB) DeleteCategory(id int) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	statements := []string{
+		"UPDATE expenses SET category_id=NULL WHERE category_id=?",
+		"UPDATE expense_splits SET category_id=NULL WHERE category_id=?",
+		"UPDATE categorization_rules SET category_id=NULL WHERE category_id=?",
+		"UPDATE recurring_rules SET category_id=NULL WHERE category_id=?",
+		"DELETE FROM category_budgets WHERE category_id=?",
+		"UPDATE categories SET parent_id=NULL WHERE parent_id=?",
+		"DELETE FROM categories WHERE id=?",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, id); err != nil {
+			return fmt.Errorf("Could not delete from table: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// GetCategoryBudgets returns the budget amount of every category that has
+// one for a specific month and year (YYYY-MM) or "default", by category id.
+func (db *DB) GetCategoryBudgets(date string) (map[int]domain.Money, error) {
+	rows, err := db.db.Query("SELECT category_id, amount FROM category_budgets WHERE date=?", date)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	budgets := map[int]domain.Money{}
+
+	for rows.Next() {
+		var (
+			categoryId int
+			amount     domain.Money
+		)
+		if err := rows.Scan(&categoryId, &amount); err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		budgets[categoryId] = amount
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return budgets, nil
+}
+
+// InsertCategoryBudget inserts or replaces the budget amount of a category
+// for a specific month and year (YYYY-MM) or "default".
+func (db DB) InsertCategoryBudget(categoryId int, amount domain.Money, date string) error {
+	result, err := db.db.Exec(
+		"INSERT OR REPLACE INTO category_budgets (category_id, amount, date) VALUES (?,?,?)",
+		categoryId,
+		amount,
+		date,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	_, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+	}
+
+	return nil
+}
+
+// DeleteCategoryBudget deletes the budget amount of a category for a specific
+// month and year (YYYY-MM) or "default".
+func (db DB) DeleteCategoryBudget(categoryId int, date string) error {
+	if _, err := db.db.Exec("DELETE FROM category_budgets WHERE category_id=? AND date=?", categoryId, date); err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	return nil
+}
@@ -2,7 +2,12 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
 
 	_ "modernc.org/sqlite"
 
@@ -11,95 +16,110 @@ import (
 )
 
 type DB struct {
-	db *sql.DB
+	db             *sql.DB
+	attachmentsDir string
 }
 
-// CreateDB opens sqlite database connection and returns pointer to DB struct.
-func CreateDB() *DB {
-	db, err := sql.Open("sqlite", "./db.sqlite3")
-	if err != nil {
-		logger.Error(fmt.Errorf("Could not open database: %w", err).Error())
+// attachmentsDirSuffix is appended to the name of the database, without
+// extension, to name the directory next to it where attachment files are
+// stored, so ledgers in the same directory never share attachments.
+const attachmentsDirSuffix = "-attachments"
+
+// CreateDB opens the sqlite database at path, creating it and its directory
+// if missing, applies pending schema migrations and returns pointer to DB
+// struct. It refuses to open a database migrated by a newer program version.
+func CreateDB(path string) (*DB, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("Could not create database directory: %w", err)
 	}
 
-	if createExpensesTable(db); err != nil {
-		logger.Error(err.Error())
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("Could not open database: %w", err)
 	}
 
-	if createBudgetTable(db); err != nil {
-		logger.Error(err.Error())
+	applied, err := runMigrations(db, false)
+	for _, description := range applied {
+		logger.Info("Applied migration " + description)
 	}
-
-	if addDefaultMonthlyBudget(db); err != nil {
-		logger.Error(err.Error())
+	if err != nil {
+		db.Close()
+		return nil, err
 	}
 
-	return &DB{db: db}
+	return &DB{
+		db:             db,
+		attachmentsDir: attachmentsDir(path),
+	}, nil
 }
 
-// createExpensesTable takes in a database connection and
-// creates the expenses table if it doesn't exist.
-func createExpensesTable(db *sql.DB) error {
-	result, err := db.Exec(
-		`CREATE TABLE IF NOT EXISTS expenses (
-id INTEGER PRIMARY KEY,
-name TEXT,
-category TEXT,
-date TEXT,
-amount TEXT
-)`,
-	)
-	if err != nil {
-		return fmt.Errorf("Could not create table: %w", err)
-	}
+// legacyDatabaseName and legacyAttachmentsDirName name the database and
+// the attachments directory of older versions, always side by side.
+const (
+	legacyDatabaseName       = "db.sqlite3"
+	legacyAttachmentsDirName = "attachments"
+)
 
-	_, err = result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+// attachmentsDir returns the directory of the attachment files
+// of the database at path. A database created by older versions keeps
+// its attachments directory.
+func attachmentsDir(path string) string {
+	if filepath.Base(path) == legacyDatabaseName {
+		legacy := filepath.Join(filepath.Dir(path), legacyAttachmentsDirName)
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return legacy
+		}
 	}
 
-	return nil
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return filepath.Join(filepath.Dir(path), name+attachmentsDirSuffix)
 }
 
-// createBudgetTable takes in a database connection and
-// creates the budget table if it doesn't exist.
-func createBudgetTable(db *sql.DB) error {
-	result, err := db.Exec(
-		`CREATE TABLE IF NOT EXISTS budget (
-id INTEGER PRIMARY KEY,
-date TEXT UNIQUE,
-amount TEXT
-)`,
-	)
-	if err != nil {
-		return fmt.Errorf("Could not create table: %w", err)
+// DryRunMigrations returns the migrations CreateDB would apply to the
+// database at path without changing it. Every migration is run and rolled back.
+// A missing database is not created, every migration being pending.
+func DryRunMigrations(path string) ([]string, error) {
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		path = ":memory:"
 	}
 
-	_, err = result.LastInsertId()
+	db, err := sql.Open("sqlite", path)
 	if err != nil {
-		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+		return nil, fmt.Errorf("Could not open database: %w", err)
 	}
 
-	return nil
+	defer db.Close()
+
+	return runMigrations(db, true)
 }
 
-// addDefaultMonthlyBudget takes in a database connection and inserts a
-// monthly default budget amount of 0.00 using "default" instead of a date.
-func addDefaultMonthlyBudget(db *sql.DB) error {
-	result, err := db.Exec(
-		"INSERT OR IGNORE INTO budget (date, amount) VALUES (?,?)",
-		"default",
-		"0.00",
-	)
-	if err != nil {
-		return fmt.Errorf("Could not insert into table: %w", err)
+// nullableId returns nil for an unset id (0) so it is stored as NULL.
+func nullableId(id int) any {
+	if id == 0 {
+		return nil
 	}
+	return id
+}
 
-	_, err = result.LastInsertId()
-	if err != nil {
-		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+// nullableText returns nil for an empty text so that it is stored as NULL.
+func nullableText(text string) any {
+	if text == "" {
+		return nil
 	}
+	return text
+}
 
-	return nil
+// joinTags encodes tags into the comma separated form they are stored in.
+func joinTags(tags []string) string {
+	return strings.Join(tags, ",")
+}
+
+// splitTags decodes tags stored by joinTags.
+func splitTags(tags string) []string {
+	if tags == "" {
+		return []string{}
+	}
+	return strings.Split(tags, ",")
 }
 
 // Close closes the database connection.
@@ -107,11 +127,30 @@ func (db *DB) Close() error {
 	return db.db.Close()
 }
 
-// GetWithMonthYear returns expenses of a specific month and year (YYYY-MM).
-func (db *DB) GetExpensesWithYearMonth(yearMonth string) []domain.Expense {
-	rows, err := db.db.Query("SELECT id, name, date, amount, category FROM expenses WHERE date LIKE ?", yearMonth)
+// notDeleted is the condition leaving out expenses in the trash.
+const notDeleted = "e.deleted_at IS NULL"
+
+// selectExpenses is the query selecting every column scanned by queryExpenses,
+// to be completed by a WHERE clause on the expenses table aliased e.
+// Queries of expenses in use include notDeleted in their clause.
+const selectExpenses = `SELECT e.id, e.name, e.date, e.amount, COALESCE(c.name, ''), COALESCE(e.category_id, 0),
+COALESCE(e.rule_id, 0), e.kind, COALESCE(a.name, ''), COALESCE(e.account_id, 0),
+COALESCE(t.name, ''), COALESCE(e.to_account_id, 0),
+(SELECT COUNT(*) FROM attachments f WHERE f.expense_id = e.id),
+COALESCE(p.name, ''), COALESCE(e.payee_id, 0), e.tags, COALESCE(e.deleted_at, ''),
+COALESCE(e.import_id, '')
+FROM expenses e
+LEFT JOIN categories c ON c.id = e.category_id
+LEFT JOIN accounts a ON a.id = e.account_id
+LEFT JOIN accounts t ON t.id = e.to_account_id
+LEFT JOIN payees p ON p.id = e.payee_id
+`
+
+// queryExpenses runs a query built on selectExpenses and returns its expenses.
+func (db *DB) queryExpenses(query string, args ...any) ([]domain.Expense, error) {
+	rows, err := db.db.Query(query, args...)
 	if err != nil {
-		logger.Error(fmt.Errorf("Could not query database: %w", err).Error())
+		return nil, fmt.Errorf("Could not query database: %w", err)
 	}
 
 	defer rows.Close()
@@ -120,41 +159,133 @@ func (db *DB) GetExpensesWithYearMonth(yearMonth string) []domain.Expense {
 
 	for rows.Next() {
 		expense := domain.Expense{}
-		rows.Scan(
+		tags := ""
+		err := rows.Scan(
 			&expense.Id,
 			&expense.Name,
 			&expense.Date,
 			&expense.Amount,
 			&expense.Category,
+			&expense.CategoryId,
+			&expense.RuleId,
+			&expense.Kind,
+			&expense.Account,
+			&expense.AccountId,
+			&expense.ToAccount,
+			&expense.ToAccountId,
+			&expense.Attachments,
+			&expense.Payee,
+			&expense.PayeeId,
+			&tags,
+			&expense.DeletedAt,
+			&expense.ImportId,
 		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		expense.Tags = splitTags(tags)
 		list = append(list, expense)
 	}
 
-	return list
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	rows.Close()
+
+	if err := db.attachSplits(list); err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
 
-// GetDefaultBudget returns the default monthly budget amount.
-func (db *DB) GetDefaultBudget() string {
-	rows, err := db.db.Query("SELECT amount FROM budget WHERE date='default'")
-	if err != nil {
-		logger.Error(fmt.Errorf("Could not query database: %w", err).Error())
+// GetExpenses returns every expense not in the trash ordered by date.
+func (db *DB) GetExpenses() ([]domain.Expense, error) {
+	return db.queryExpenses(selectExpenses + "WHERE " + notDeleted + " ORDER BY e.day, e.id")
+}
+
+// GetExpenseWithId returns the expense with an id, even in the trash,
+// and whether it exists.
+func (db *DB) GetExpenseWithId(id int) (domain.Expense, bool, error) {
+	list, err := db.queryExpenses(selectExpenses+"WHERE e.id=?", id)
+	if err != nil || len(list) == 0 {
+		return domain.Expense{}, false, err
 	}
 
-	defer rows.Close()
+	return list[0], true, nil
+}
 
-	amount := ""
+// GetExpenseWithImportId returns the expense imported from a statement
+// transaction, even in the trash, and whether it exists.
+func (db *DB) GetExpenseWithImportId(importId string) (domain.Expense, bool, error) {
+	list, err := db.queryExpenses(selectExpenses+"WHERE e.import_id=?", importId)
+	if err != nil || len(list) == 0 {
+		return domain.Expense{}, false, err
+	}
 
-	for rows.Next() {
-		rows.Scan(
-			&amount,
-		)
+	return list[0], true, nil
+}
+
+// IsImportIdPurged reports whether an expense imported from a statement
+// transaction was purged from the trash.
+func (db *DB) IsImportIdPurged(importId string) (bool, error) {
+	count := 0
+
+	if err := db.db.QueryRow("SELECT COUNT(*) FROM purged_import_ids WHERE import_id=?", importId).Scan(&count); err != nil {
+		return false, fmt.Errorf("Could not query database: %w", err)
 	}
 
-	return amount
+	return count > 0, nil
+}
+
+// GetFirstExpenseDate returns the date of the oldest expense not in the
+// trash and whether there is any expense.
+func (db *DB) GetFirstExpenseDate() (string, bool, error) {
+	date := sql.NullString{}
+
+	if err := db.db.QueryRow("SELECT MIN(day) FROM expenses WHERE deleted_at IS NULL").Scan(&date); err != nil {
+		return "", false, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	return date.String, date.Valid, nil
+}
+
+// GetExpensesInRange returns the expenses not in the trash dated from one
+// day to another (YYYY-MM-DD, both included) ordered by date. Month-only expenses count
+// as dated on the first day of their month (see domain.RangeDate).
+func (db *DB) GetExpensesInRange(from, to string) ([]domain.Expense, error) {
+	return db.queryExpenses(
+		selectExpenses+"WHERE "+notDeleted+" AND e.day BETWEEN ? AND ? ORDER BY e.day, e.id",
+		domain.RangeDate(from),
+		domain.RangeDate(to),
+	)
+}
+
+// GetDefaultBudget returns the default monthly budget amount.
+func (db *DB) GetDefaultBudget() (domain.Money, error) {
+	amount, _, err := db.getBudget("default")
+	return amount, err
+}
+
+// getBudget returns the budget amount stored under date
+// and whether such a row exists.
+func (db *DB) getBudget(date string) (domain.Money, bool, error) {
+	amount := domain.Money(0)
+
+	err := db.db.QueryRow("SELECT amount FROM budget WHERE date=?", date).Scan(&amount)
+	if err == sql.ErrNoRows {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	return amount, true, nil
 }
 
 // UpdateDefaultBudget updates the default monthly budget amount.
-func (db DB) UpdateDefaultBudget(amount string) error {
+func (db DB) UpdateDefaultBudget(amount domain.Money) error {
 	result, err := db.db.Exec("UPDATE budget SET amount=? WHERE date='default'", amount)
 	if err != nil {
 		return fmt.Errorf("Could not update table: %w", err)
@@ -169,7 +300,7 @@ func (db DB) UpdateDefaultBudget(amount string) error {
 }
 
 // InsertBudget inserts budget amount for a specific month and year (YYYY-MM).
-func (db DB) InsertBudget(amount, date string) error {
+func (db DB) InsertBudget(amount domain.Money, date string) error {
 	result, err := db.db.Exec("INSERT OR REPLACE INTO budget (amount, date) VALUES (?,?)", amount, date)
 	if err != nil {
 		return fmt.Errorf("Could not insert into table: %w", err)
@@ -183,59 +314,138 @@ func (db DB) InsertBudget(amount, date string) error {
 	return nil
 }
 
-// GetBudgetWithYearMonth returns budget amount
-// of a specific month and year (YYYY-MM).
-func (db *DB) GetBudgetWithYearMonth(date string) string {
-	rows, err := db.db.Query("SELECT amount FROM budget WHERE date=?", date)
+// DeleteBudget deletes the budget of a specific month and year (YYYY-MM),
+// the month falling back to the default budget.
+func (db DB) DeleteBudget(date string) error {
+	if _, err := db.db.Exec("DELETE FROM budget WHERE date=? AND date!='default'", date); err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	return nil
+}
+
+// GetBudgetWithYearMonth returns budget amount of a specific month and
+// year (YYYY-MM) and whether a budget was set for that month.
+func (db *DB) GetBudgetWithYearMonth(date string) (domain.Money, bool, error) {
+	return db.getBudget(date)
+}
+
+// InsertExpense inserts a given expense with its split lines into
+// expenses table and returns its id.
+func (db DB) InsertExpense(expense domain.Expense) (int, error) {
+	ids, err := db.InsertExpenses([]domain.Expense{expense})
 	if err != nil {
-		logger.Error(fmt.Errorf("Could not query database: %w", err).Error())
+		return 0, err
 	}
 
-	defer rows.Close()
+	return ids[0], nil
+}
 
-	amount := ""
+// InsertExpenses inserts expenses with their split lines into expenses
+// table in a single transaction, all of them or none, and returns their ids.
+func (db DB) InsertExpenses(expenses []domain.Expense) ([]int, error) {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("Could not begin transaction: %w", err)
+	}
 
-	for rows.Next() {
-		rows.Scan(
-			&amount,
-		)
+	defer tx.Rollback()
+
+	ids := []int{}
+	for _, expense := range expenses {
+		id, err := insertExpense(tx, expense)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("Could not commit transaction: %w", err)
 	}
 
-	return amount
+	return ids, nil
 }
 
-// InsertExpense inserts a given expense into expenses table.
-func (db DB) InsertExpense(expense domain.Expense) error {
-	result, err := db.db.Exec(
-		"INSERT INTO expenses (name, date, amount, category) VALUES (?,?,?,?)",
+// insertExpense inserts an expense with its split lines within tx
+// and returns its id.
+func insertExpense(tx *sql.Tx, expense domain.Expense) (int, error) {
+	result, err := tx.Exec(
+		`INSERT INTO expenses (name, date, day, amount, category_id, rule_id, kind, account_id, to_account_id, payee_id, tags, import_id)
+VALUES (?,?,?,?,?,?,?,?,?,?,?,?)`,
 		expense.Name,
 		expense.Date,
+		domain.RangeDate(expense.Date),
 		expense.Amount,
-		expense.Category,
+		nullableId(expense.CategoryId),
+		nullableId(expense.RuleId),
+		expense.Kind,
+		nullableId(expense.AccountId),
+		nullableId(expense.ToAccountId),
+		nullableId(expense.PayeeId),
+		joinTags(expense.Tags),
+		nullableText(expense.ImportId),
 	)
 	if err != nil {
-		return fmt.Errorf("Could not insert into table: %w", err)
+		return 0, fmt.Errorf("Could not insert into table: %w", err)
 	}
 
-	_, err = result.LastInsertId()
+	id, err := result.LastInsertId()
 	if err != nil {
-		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+		return 0, fmt.Errorf("Could not retrieve last inserted id: %w", err)
 	}
 
-	return nil
+	if err := insertSplits(tx, id, expense.Splits); err != nil {
+		return 0, err
+	}
+
+	return int(id), nil
 }
 
-// DeleteExpense deletes an expense from expenses table by its Id.
-func (db DB) DeleteExpense(id int) error {
-	result, err := db.db.Exec("DELETE FROM expenses WHERE id=?", id)
+// UpdateExpense updates an existing expense in expenses table by its Id,
+// replacing its split lines. Its import id is kept.
+func (db DB) UpdateExpense(expense domain.Expense) error {
+	tx, err := db.db.Begin()
 	if err != nil {
-		return fmt.Errorf("Could not delete from table: %w", err)
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"UPDATE expenses SET name=?, date=?, day=?, amount=?, category_id=?, kind=?, account_id=?, to_account_id=?, payee_id=?, tags=? WHERE id=?",
+		expense.Name,
+		expense.Date,
+		domain.RangeDate(expense.Date),
+		expense.Amount,
+		nullableId(expense.CategoryId),
+		expense.Kind,
+		nullableId(expense.AccountId),
+		nullableId(expense.ToAccountId),
+		nullableId(expense.PayeeId),
+		joinTags(expense.Tags),
+		expense.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
 	}
 
-	_, err = result.RowsAffected()
+	affected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
 	}
 
-	return nil
+	if affected == 0 {
+		return fmt.Errorf("Could not find expense with id %d", expense.Id)
+	}
+
+	if err := deleteSplits(tx, expense.Id); err != nil {
+		return err
+	}
+
+	if err := insertSplits(tx, int64(expense.Id), expense.Splits); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
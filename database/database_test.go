@@ -0,0 +1,20 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.Storage {
+		db, err := CreateDB(filepath.Join(t.TempDir(), "db.sqlite3"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+		return db
+	})
+}
@@ -0,0 +1,741 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// ErrDatabaseTooNew is returned when the database was migrated by a newer
+// version of the program than the one running.
+var ErrDatabaseTooNew = errors.New("Database schema is newer than this program supports")
+
+// migration is a single ordered change to the database schema.
+type migration struct {
+	version     int
+	description string
+	migrate     func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Never edit or reorder
+// an existing entry, only append new ones with the next version number.
+var migrations = []migration{
+	{
+		version:     1,
+		description: "create expenses and budget tables",
+		migrate:     createInitialTables,
+	},
+	{
+		version:     2,
+		description: "store amounts as integer minor units",
+		migrate:     migrateAmountsToMinorUnits,
+	},
+	{
+		version:     3,
+		description: "move expense categories into a categories table",
+		migrate:     createCategoriesTable,
+	},
+	{
+		version:     4,
+		description: "add per category budgets",
+		migrate:     createCategoryBudgetsTable,
+	},
+	{
+		version:     5,
+		description: "add recurring expense rules",
+		migrate:     createRecurringRulesTable,
+	},
+	{
+		version:     6,
+		description: "add transaction kind to expenses",
+		migrate:     addExpenseKindColumn,
+	},
+	{
+		version:     7,
+		description: "add accounts",
+		migrate:     createAccountsTable,
+	},
+	{
+		version:     8,
+		description: "add expense split lines",
+		migrate:     createExpenseSplitsTable,
+	},
+	{
+		version:     9,
+		description: "add expense attachments",
+		migrate:     createAttachmentsTable,
+	},
+	{
+		version:     10,
+		description: "add payees, tags and categorization rules",
+		migrate:     createCategorizationTables,
+	},
+	{
+		version:     11,
+		description: "add settings",
+		migrate:     createSettingsTable,
+	},
+	{
+		version:     12,
+		description: "add indexed day column to expenses",
+		migrate:     addExpenseDayColumn,
+	},
+	{
+		version:     13,
+		description: "add soft deletion of expenses",
+		migrate:     addExpenseDeletedAtColumn,
+	},
+	{
+		version:     14,
+		description: "add audit log",
+		migrate:     createAuditLogTable,
+	},
+	{
+		version:     15,
+		description: "add import ids to expenses",
+		migrate:     addExpenseImportIdColumn,
+	},
+	{
+		version:     16,
+		description: "unlink recurring rules from deleted categories",
+		migrate:     unlinkDeletedRecurringCategories,
+	},
+	{
+		version:     17,
+		description: "keep import ids of purged expenses",
+		migrate:     createPurgedImportIdsTable,
+	},
+}
+
+// latestVersion returns the schema version this program expects.
+func latestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// createSchemaVersionTable takes in a transaction and
+// creates the schema_version table if it doesn't exist.
+func createSchemaVersionTable(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`CREATE TABLE IF NOT EXISTS schema_version (
+version INTEGER PRIMARY KEY,
+description TEXT,
+applied_at TEXT
+)`,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not create table: %w", err)
+	}
+
+	return nil
+}
+
+// getSchemaVersion returns the version of the last applied migration,
+// 0 if none was applied yet.
+func getSchemaVersion(tx *sql.Tx) (int, error) {
+	version := 0
+
+	err := tx.QueryRow("SELECT COALESCE(MAX(version), 0) FROM schema_version").Scan(&version)
+	if err != nil {
+		return 0, fmt.Errorf("Could not query schema version: %w", err)
+	}
+
+	return version, nil
+}
+
+// runMigrations applies every pending migration in order and returns the
+// descriptions of the migrations that were (or would be) applied.
+// Each migration is committed in its own transaction. With dryRun all of
+// them run in a single transaction which is rolled back at the end.
+func runMigrations(db *sql.DB, dryRun bool) ([]string, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	// tx is replaced after every commit, roll back whichever is current.
+	defer func() { tx.Rollback() }()
+
+	if err := createSchemaVersionTable(tx); err != nil {
+		return nil, err
+	}
+
+	current, err := getSchemaVersion(tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if current > latestVersion() {
+		return nil, fmt.Errorf("%w (database: %d, program: %d)", ErrDatabaseTooNew, current, latestVersion())
+	}
+
+	applied := []string{}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+
+		if err := applyMigration(tx, m); err != nil {
+			return applied, err
+		}
+
+		applied = append(applied, fmt.Sprintf("%d: %s", m.version, m.description))
+
+		if dryRun {
+			continue
+		}
+
+		if err := tx.Commit(); err != nil {
+			return applied, fmt.Errorf("Could not commit migration %d: %w", m.version, err)
+		}
+
+		tx, err = db.Begin()
+		if err != nil {
+			return applied, fmt.Errorf("Could not begin transaction: %w", err)
+		}
+	}
+
+	if dryRun {
+		return applied, nil
+	}
+
+	return applied, tx.Commit()
+}
+
+// applyMigration runs a single migration and records its version
+// inside the given transaction.
+func applyMigration(tx *sql.Tx, m migration) error {
+	if err := m.migrate(tx); err != nil {
+		return fmt.Errorf("Could not apply migration %d (%s): %w", m.version, m.description, err)
+	}
+
+	_, err := tx.Exec(
+		"INSERT INTO schema_version (version, description, applied_at) VALUES (?,?,?)",
+		m.version,
+		m.description,
+		time.Now().UTC().Format(time.RFC3339),
+	)
+	if err != nil {
+		return fmt.Errorf("Could not record migration %d: %w", m.version, err)
+	}
+
+	return nil
+}
+
+// createInitialTables creates the expenses and budget tables as they were
+// before versioned migrations existed, with a default budget of 0.00.
+// Tables of databases created back then are left untouched.
+func createInitialTables(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE IF NOT EXISTS expenses (
+id INTEGER PRIMARY KEY,
+name TEXT,
+category TEXT,
+date TEXT,
+amount TEXT
+)`,
+		`CREATE TABLE IF NOT EXISTS budget (
+id INTEGER PRIMARY KEY,
+date TEXT UNIQUE,
+amount TEXT
+)`,
+		`INSERT OR IGNORE INTO budget (date, amount) VALUES ('default', '0.00')`,
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateAmountsToMinorUnits converts the amount columns of the expenses and
+// budget tables from TEXT holding a decimal to integer minor units.
+func migrateAmountsToMinorUnits(tx *sql.Tx) error {
+	for _, table := range []string{"expenses", "budget"} {
+		columnType, err := getColumnType(tx, table, "amount")
+		if err != nil {
+			return err
+		}
+
+		if columnType == "INTEGER" {
+			continue
+		}
+
+		if err := convertAmountColumn(tx, table); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// getColumnType returns the declared type of a column in a table.
+func getColumnType(tx *sql.Tx, table, column string) (string, error) {
+	rows, err := tx.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return "", fmt.Errorf("Could not query table info: %w", err)
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id         int
+			name       string
+			columnType string
+			notNull    int
+			defaultVal sql.NullString
+			primaryKey int
+		)
+		if err := rows.Scan(&id, &name, &columnType, &notNull, &defaultVal, &primaryKey); err != nil {
+			return "", fmt.Errorf("Could not scan table info: %w", err)
+		}
+		if name == column {
+			return columnType, nil
+		}
+	}
+
+	return "", rows.Err()
+}
+
+// convertAmountColumn rewrites every decimal amount of a table
+// as integer minor units.
+func convertAmountColumn(tx *sql.Tx, table string) error {
+	columns := map[string]string{
+		"expenses": "id INTEGER PRIMARY KEY, name TEXT, category TEXT, date TEXT, amount INTEGER",
+		"budget":   "id INTEGER PRIMARY KEY, date TEXT UNIQUE, amount INTEGER",
+	}
+	names := map[string]string{
+		"expenses": "id, name, category, date",
+		"budget":   "id, date",
+	}
+
+	statements := []string{
+		fmt.Sprintf("CREATE TABLE %s_new (%s)", table, columns[table]),
+		fmt.Sprintf(
+			"INSERT INTO %[1]s_new (%[2]s, amount) SELECT %[2]s, CAST(ROUND(CAST(amount AS REAL) * %[3]d) AS INTEGER) FROM %[1]s",
+			table, names[table], domain.MinorUnits,
+		),
+		fmt.Sprintf("DROP TABLE %s", table),
+		fmt.Sprintf("ALTER TABLE %[1]s_new RENAME TO %[1]s", table),
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate table %s: %w", table, err)
+		}
+	}
+
+	return nil
+}
+
+// createCategoriesTable creates the categories table, fills it with the
+// normalized category names of existing expenses and replaces the text
+// category column of expenses with a category_id reference.
+// Names differing only by case or spacing end up in the same category.
+func createCategoriesTable(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`CREATE TABLE categories (
+id INTEGER PRIMARY KEY,
+name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+parent_id INTEGER REFERENCES categories(id),
+color TEXT NOT NULL DEFAULT '',
+icon TEXT NOT NULL DEFAULT '',
+archived INTEGER NOT NULL DEFAULT 0
+)`,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not create table: %w", err)
+	}
+
+	rows, err := tx.Query("SELECT id, COALESCE(category, '') FROM expenses ORDER BY id")
+	if err != nil {
+		return fmt.Errorf("Could not query database: %w", err)
+	}
+
+	// Ordered by id so the first spelling of a name becomes the category name.
+	type expenseCategory struct {
+		expenseId int
+		name      string
+	}
+	expenseCategories := []expenseCategory{}
+	for rows.Next() {
+		var (
+			id       int
+			category string
+		)
+		if err := rows.Scan(&id, &category); err != nil {
+			rows.Close()
+			return fmt.Errorf("Could not scan row: %w", err)
+		}
+		expenseCategories = append(expenseCategories, expenseCategory{id, domain.NormalizeCategoryName(category)})
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	statements := []string{
+		`CREATE TABLE expenses_new (
+id INTEGER PRIMARY KEY,
+name TEXT,
+date TEXT,
+amount INTEGER,
+category_id INTEGER REFERENCES categories(id)
+)`,
+		"INSERT INTO expenses_new (id, name, date, amount) SELECT id, name, date, amount FROM expenses",
+		"DROP TABLE expenses",
+		"ALTER TABLE expenses_new RENAME TO expenses",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate table expenses: %w", err)
+		}
+	}
+
+	categoryIds := map[string]int64{}
+
+	for _, expense := range expenseCategories {
+		if expense.name == "" {
+			continue
+		}
+
+		key := strings.ToLower(expense.name)
+		categoryId, ok := categoryIds[key]
+		if !ok {
+			result, err := tx.Exec("INSERT INTO categories (name) VALUES (?)", expense.name)
+			if err != nil {
+				return fmt.Errorf("Could not insert into table: %w", err)
+			}
+			categoryId, err = result.LastInsertId()
+			if err != nil {
+				return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+			}
+			categoryIds[key] = categoryId
+		}
+
+		_, err := tx.Exec("UPDATE expenses SET category_id=? WHERE id=?", categoryId, expense.expenseId)
+		if err != nil {
+			return fmt.Errorf("Could not update table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createCategoryBudgetsTable creates the category_budgets table holding one
+// amount per category and month (YYYY-MM) or "default".
+func createCategoryBudgetsTable(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`CREATE TABLE category_budgets (
+id INTEGER PRIMARY KEY,
+category_id INTEGER NOT NULL REFERENCES categories(id),
+date TEXT NOT NULL,
+amount INTEGER NOT NULL,
+UNIQUE (category_id, date)
+)`,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not create table: %w", err)
+	}
+
+	return nil
+}
+
+// createRecurringRulesTable creates the recurring_rules table and links
+// expenses generated from a rule back to it.
+func createRecurringRulesTable(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE recurring_rules (
+id INTEGER PRIMARY KEY,
+name TEXT NOT NULL,
+amount INTEGER NOT NULL,
+category_id INTEGER REFERENCES categories(id),
+interval TEXT NOT NULL,
+every INTEGER NOT NULL DEFAULT 1,
+day_of_month INTEGER NOT NULL DEFAULT 0,
+start_date TEXT NOT NULL,
+end_date TEXT NOT NULL DEFAULT '',
+count INTEGER NOT NULL DEFAULT 0,
+paused INTEGER NOT NULL DEFAULT 0,
+materialized_until TEXT NOT NULL DEFAULT ''
+)`,
+		"ALTER TABLE expenses ADD COLUMN rule_id INTEGER REFERENCES recurring_rules(id)",
+		"CREATE UNIQUE INDEX expenses_rule_date ON expenses (rule_id, date) WHERE rule_id IS NOT NULL",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate recurring rules: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addExpenseKindColumn adds the kind column to expenses,
+// every existing row being an expense.
+func addExpenseKindColumn(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE expenses ADD COLUMN kind TEXT NOT NULL DEFAULT 'expense'")
+	if err != nil {
+		return fmt.Errorf("Could not alter table: %w", err)
+	}
+
+	return nil
+}
+
+// createAccountsTable creates the accounts table with a "Main" account
+// holding every existing expense, and links expenses to accounts.
+func createAccountsTable(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE accounts (
+id INTEGER PRIMARY KEY,
+name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+type TEXT NOT NULL,
+opening_balance INTEGER NOT NULL DEFAULT 0
+)`,
+		"ALTER TABLE expenses ADD COLUMN account_id INTEGER REFERENCES accounts(id)",
+		"ALTER TABLE expenses ADD COLUMN to_account_id INTEGER REFERENCES accounts(id)",
+		"INSERT INTO accounts (id, name, type) VALUES (1, 'Main', 'checking')",
+		"UPDATE expenses SET account_id=1",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate accounts: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createExpenseSplitsTable creates the expense_splits table holding the
+// split lines of expenses spread over several categories.
+func createExpenseSplitsTable(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE expense_splits (
+id INTEGER PRIMARY KEY,
+expense_id INTEGER NOT NULL REFERENCES expenses(id),
+category_id INTEGER REFERENCES categories(id),
+amount INTEGER NOT NULL,
+note TEXT NOT NULL DEFAULT ''
+)`,
+		"CREATE INDEX expense_splits_expense ON expense_splits (expense_id)",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate expense splits: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createAttachmentsTable creates the attachments table holding the
+// metadata of documents attached to expenses.
+func createAttachmentsTable(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE attachments (
+id INTEGER PRIMARY KEY,
+expense_id INTEGER NOT NULL REFERENCES expenses(id),
+name TEXT NOT NULL,
+file TEXT NOT NULL,
+hash TEXT NOT NULL,
+mime_type TEXT NOT NULL,
+size INTEGER NOT NULL,
+added_at TEXT NOT NULL
+)`,
+		"CREATE INDEX attachments_expense ON attachments (expense_id)",
+		"CREATE INDEX attachments_file ON attachments (file)",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate attachments: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createCategorizationTables creates the payees and categorization_rules
+// tables and adds payee and tags to expenses.
+func createCategorizationTables(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE payees (
+id INTEGER PRIMARY KEY,
+name TEXT NOT NULL UNIQUE COLLATE NOCASE
+)`,
+		"ALTER TABLE expenses ADD COLUMN payee_id INTEGER REFERENCES payees(id)",
+		"ALTER TABLE expenses ADD COLUMN tags TEXT NOT NULL DEFAULT ''",
+		`CREATE TABLE categorization_rules (
+id INTEGER PRIMARY KEY,
+priority INTEGER NOT NULL,
+match_type TEXT NOT NULL,
+pattern TEXT NOT NULL,
+min_amount INTEGER NOT NULL DEFAULT 0,
+max_amount INTEGER NOT NULL DEFAULT 0,
+category_id INTEGER REFERENCES categories(id),
+payee_id INTEGER REFERENCES payees(id),
+tags TEXT NOT NULL DEFAULT ''
+)`,
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate categorization rules: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// createSettingsTable creates the settings table of key value pairs.
+func createSettingsTable(tx *sql.Tx) error {
+	_, err := tx.Exec(
+		`CREATE TABLE settings (
+key TEXT PRIMARY KEY,
+value TEXT NOT NULL
+)`,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not create table: %w", err)
+	}
+
+	return nil
+}
+
+// addExpenseDayColumn adds the day column holding the date of every
+// expense in the normalized form of domain.RangeDate, with an index
+// for range queries.
+func addExpenseDayColumn(tx *sql.Tx) error {
+	if _, err := tx.Exec("ALTER TABLE expenses ADD COLUMN day TEXT NOT NULL DEFAULT ''"); err != nil {
+		return fmt.Errorf("Could not alter table: %w", err)
+	}
+
+	rows, err := tx.Query("SELECT id, date FROM expenses")
+	if err != nil {
+		return fmt.Errorf("Could not query database: %w", err)
+	}
+
+	days := map[int]string{}
+	ids := []int{}
+
+	for rows.Next() {
+		id, date := 0, ""
+		if err := rows.Scan(&id, &date); err != nil {
+			rows.Close()
+			return fmt.Errorf("Could not scan row: %w", err)
+		}
+		days[id] = domain.RangeDate(date)
+		ids = append(ids, id)
+	}
+
+	err = rows.Err()
+	rows.Close()
+	if err != nil {
+		return fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	for _, id := range ids {
+		if _, err := tx.Exec("UPDATE expenses SET day=? WHERE id=?", days[id], id); err != nil {
+			return fmt.Errorf("Could not update table: %w", err)
+		}
+	}
+
+	if _, err := tx.Exec("CREATE INDEX expenses_day ON expenses (day)"); err != nil {
+		return fmt.Errorf("Could not create index: %w", err)
+	}
+
+	return nil
+}
+
+// addExpenseDeletedAtColumn adds the deleted_at column holding when an
+// expense was moved to the trash, NULL for expenses in use.
+func addExpenseDeletedAtColumn(tx *sql.Tx) error {
+	_, err := tx.Exec("ALTER TABLE expenses ADD COLUMN deleted_at TEXT")
+	if err != nil {
+		return fmt.Errorf("Could not alter table: %w", err)
+	}
+
+	return nil
+}
+
+// createAuditLogTable creates the audit_log table, with triggers refusing
+// to update or delete its rows so that it is append-only.
+func createAuditLogTable(tx *sql.Tx) error {
+	statements := []string{
+		`CREATE TABLE audit_log (
+id INTEGER PRIMARY KEY,
+timestamp TEXT NOT NULL,
+user TEXT NOT NULL DEFAULT '',
+action TEXT NOT NULL,
+entity TEXT NOT NULL,
+entity_key TEXT NOT NULL,
+before TEXT NOT NULL DEFAULT '',
+after TEXT NOT NULL DEFAULT ''
+)`,
+		"CREATE INDEX audit_log_entity ON audit_log (entity, entity_key)",
+		`CREATE TRIGGER audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`,
+		`CREATE TRIGGER audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log is append-only'); END`,
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate audit log: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// addExpenseImportIdColumn adds the import_id column identifying the
+// statement transaction an expense was imported from, NULL for the others,
+// unique so a transaction is never imported twice.
+func addExpenseImportIdColumn(tx *sql.Tx) error {
+	statements := []string{
+		"ALTER TABLE expenses ADD COLUMN import_id TEXT",
+		"CREATE UNIQUE INDEX expenses_import_id ON expenses (import_id) WHERE import_id IS NOT NULL",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement); err != nil {
+			return fmt.Errorf("Could not migrate import ids: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// unlinkDeletedRecurringCategories clears the category of the recurring
+// rules whose category was deleted, which older versions left in place.
+func unlinkDeletedRecurringCategories(tx *sql.Tx) error {
+	_, err := tx.Exec("UPDATE recurring_rules SET category_id=NULL WHERE category_id IS NOT NULL AND category_id NOT IN (SELECT id FROM categories)")
+	if err != nil {
+		return fmt.Errorf("Could not unlink recurring rules: %w", err)
+	}
+
+	return nil
+}
+
+// createPurgedImportIdsTable creates the table keeping the import ids of
+// the expenses purged from the trash, so they are never imported again.
+func createPurgedImportIdsTable(tx *sql.Tx) error {
+	if _, err := tx.Exec("CREATE TABLE purged_import_ids (import_id TEXT PRIMARY KEY)"); err != nil {
+		return fmt.Errorf("Could not migrate purged import ids: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,253 @@
+package database
+
+import (
+	"bytes"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// createBaselineDB writes a database as created before versioned
+// migrations existed, amounts being decimal text, and returns its path.
+func createBaselineDB(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	statements := []string{
+		"CREATE TABLE expenses (id INTEGER PRIMARY KEY, name TEXT, category TEXT, date TEXT, amount TEXT)",
+		"CREATE TABLE budget (id INTEGER PRIMARY KEY, date TEXT UNIQUE, amount TEXT)",
+		`INSERT INTO expenses (name, category, date, amount) VALUES
+('coffee', 'Food', '2023-02-01', '2.50'),
+('lunch', ' food ', '2023-02-15', '19.99'),
+('rent', '', '2023-02', '900'),
+('bus', 'Transport', '2023-03-02', '0.10')`,
+		"INSERT INTO budget (date, amount) VALUES ('default', '100.00'), ('2023-02', '1234.56')",
+	}
+	for _, statement := range statements {
+		if _, err := db.Exec(statement); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return path
+}
+
+// migrateTo applies the migrations up to version in a single transaction.
+func migrateTo(t *testing.T, db *sql.DB, version int) {
+	t.Helper()
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+
+	if err := createSchemaVersionTable(tx); err != nil {
+		t.Fatal(err)
+	}
+	current, err := getSchemaVersion(tx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, m := range migrations {
+		if m.version > current && m.version <= version {
+			if err := applyMigration(tx, m); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// queryStrings returns the first column of the rows of a query as text.
+func queryStrings(t *testing.T, db *sql.DB, query string) []string {
+	t.Helper()
+	rows, err := db.Query(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+
+	values := []string{}
+	for rows.Next() {
+		value := ""
+		if err := rows.Scan(&value); err != nil {
+			t.Fatal(err)
+		}
+		values = append(values, value)
+	}
+
+	return values
+}
+
+// expectStrings fails unless got holds want in order.
+func expectStrings(t *testing.T, what string, got []string, want ...string) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Errorf("got %s %q, want %q", what, got, want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("got %s %q, want %q", what, got, want)
+			return
+		}
+	}
+}
+
+func TestMigrateBaselineSteps(t *testing.T) {
+	db, err := sql.Open("sqlite", createBaselineDB(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	migrateTo(t, db, 2)
+	expectStrings(t, "amount types", queryStrings(t, db, "SELECT DISTINCT typeof(amount) FROM expenses"), "integer")
+	expectStrings(t, "expense amounts", queryStrings(t, db, "SELECT amount FROM expenses ORDER BY id"), "250", "1999", "90000", "10")
+	expectStrings(t, "budget amounts", queryStrings(t, db, "SELECT amount FROM budget ORDER BY id"), "10000", "123456")
+
+	migrateTo(t, db, 3)
+	expectStrings(t, "categories", queryStrings(t, db, "SELECT name FROM categories ORDER BY id"), "Food", "Transport")
+	expectStrings(t, "expense categories",
+		queryStrings(t, db, "SELECT COALESCE(c.name, '') FROM expenses e LEFT JOIN categories c ON c.id=e.category_id ORDER BY e.id"),
+		"Food", "Food", "", "Transport",
+	)
+
+	migrateTo(t, db, 12)
+	expectStrings(t, "days", queryStrings(t, db, "SELECT day FROM expenses ORDER BY id"),
+		"2023-02-01", "2023-02-15", domain.RangeDate("2023-02"), "2023-03-02",
+	)
+	expectStrings(t, "amounts", queryStrings(t, db, "SELECT amount FROM expenses ORDER BY id"), "250", "1999", "90000", "10")
+}
+
+func TestMigrateBaseline(t *testing.T) {
+	path := createBaselineDB(t)
+
+	db, err := CreateDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	expenses, err := db.GetExpensesInRange("2023-02-01", "2023-02-28")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]domain.Expense{}
+	for _, expense := range expenses {
+		got[expense.Name] = expense
+	}
+	for name, want := range map[string]domain.Expense{
+		"coffee": {Date: "2023-02-01", Amount: 250, Category: "Food", Kind: domain.KindExpense},
+		"lunch":  {Date: "2023-02-15", Amount: 1999, Category: "Food", Kind: domain.KindExpense},
+		"rent":   {Date: "2023-02", Amount: 90000, Kind: domain.KindExpense},
+	} {
+		expense := got[name]
+		if expense.Date != want.Date || expense.Amount != want.Amount || expense.Category != want.Category || expense.Kind != want.Kind {
+			t.Errorf("got %s %+v, want %+v", name, expense, want)
+		}
+	}
+	if len(expenses) != 3 {
+		t.Errorf("got %d expenses in February, want 3", len(expenses))
+	}
+
+	if budget, err := db.GetDefaultBudget(); err != nil || budget != 10000 {
+		t.Errorf("got default budget %s (%v), want 100.00", budget, err)
+	}
+	if budget, ok, err := db.GetBudgetWithYearMonth("2023-02"); err != nil || !ok || budget != 123456 {
+		t.Errorf("got budget %s (%v, %v), want 1234.56", budget, ok, err)
+	}
+}
+
+func TestDryRunMigrations(t *testing.T) {
+	path := createBaselineDB(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := DryRunMigrations(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != latestVersion() {
+		t.Errorf("got %d migrations to apply, want %d", len(applied), latestVersion())
+	}
+
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("dry run changed the database")
+	}
+
+	missing := filepath.Join(t.TempDir(), "missing.sqlite3")
+	if applied, err := DryRunMigrations(missing); err != nil || len(applied) != latestVersion() {
+		t.Errorf("got %d migrations (%v) for a missing database, want %d", len(applied), err, latestVersion())
+	}
+	if _, err := os.Stat(missing); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("dry run created the database: %v", err)
+	}
+}
+
+func TestRunMigrations(t *testing.T) {
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "db.sqlite3"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	applied, err := runMigrations(db, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(applied) != latestVersion() || applied[0] != "1: "+migrations[0].description {
+		t.Errorf("got migrations %q, want all %d", applied, latestVersion())
+	}
+
+	if applied, err := runMigrations(db, false); err != nil || len(applied) != 0 {
+		t.Errorf("got migrations %q (%v) applied twice", applied, err)
+	}
+
+	if _, err := db.Exec("INSERT INTO schema_version (version, description, applied_at) VALUES (?, 'future', '')", latestVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := runMigrations(db, false); !errors.Is(err, ErrDatabaseTooNew) {
+		t.Errorf("got error %v, want ErrDatabaseTooNew", err)
+	}
+}
+
+func TestDatabaseTooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "db.sqlite3")
+	db, err := CreateDB(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.db.Exec("INSERT INTO schema_version (version, description, applied_at) VALUES (?, 'future', '')", latestVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if _, err := CreateDB(path); !errors.Is(err, ErrDatabaseTooNew) {
+		t.Errorf("got error %v opening a newer database, want ErrDatabaseTooNew", err)
+	}
+	if _, err := DryRunMigrations(path); !errors.Is(err, ErrDatabaseTooNew) {
+		t.Errorf("got error %v in a dry run, want ErrDatabaseTooNew", err)
+	}
+}
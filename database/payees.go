@@ -0,0 +1,65 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetPayees returns every payee by name.
+func (db *DB) GetPayees() ([]domain.Payee, error) {
+	rows, err := db.db.Query("SELECT id, name FROM payees ORDER BY name")
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.Payee{}
+
+	for rows.Next() {
+		payee := domain.Payee{}
+		if err := rows.Scan(&payee.Id, &payee.Name); err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		list = append(list, payee)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// GetPayeeWithName returns the payee matching name case-insensitively
+// and whether it exists.
+func (db *DB) GetPayeeWithName(name string) (domain.Payee, bool, error) {
+	payee := domain.Payee{}
+
+	err := db.db.QueryRow("SELECT id, name FROM payees WHERE name=?", name).Scan(&payee.Id, &payee.Name)
+	if err == sql.ErrNoRows {
+		return domain.Payee{}, false, nil
+	}
+	if err != nil {
+		return domain.Payee{}, false, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	return payee, true, nil
+}
+
+// InsertPayee inserts a payee and returns its new id.
+func (db DB) InsertPayee(payee domain.Payee) (int, error) {
+	result, err := db.db.Exec("INSERT INTO payees (name) VALUES (?)", payee.Name)
+	if err != nil {
+		return 0, fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("Could not retrieve last inserted id: %w", err)
+	}
+
+	return int(id), nil
+}
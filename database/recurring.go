@@ -0,0 +1,210 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetRecurringRules returns every recurring rule by name.
+func (db *DB) GetRecurringRules() ([]domain.RecurringRule, error) {
+	rows, err := db.db.Query(
+		`SELECT r.id, r.name, r.amount, COALESCE(c.name, ''), COALESCE(r.category_id, 0), r.interval, r.every,
+r.day_of_month, r.start_date, r.end_date, r.count, r.paused, r.materialized_until
+FROM recurring_rules r LEFT JOIN categories c ON c.id = r.category_id
+ORDER BY r.name`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.RecurringRule{}
+
+	for rows.Next() {
+		rule := domain.RecurringRule{}
+		err := rows.Scan(
+			&rule.Id,
+			&rule.Name,
+			&rule.Amount,
+			&rule.Category,
+			&rule.CategoryId,
+			&rule.Interval,
+			&rule.Every,
+			&rule.DayOfMonth,
+			&rule.StartDate,
+			&rule.EndDate,
+			&rule.Count,
+			&rule.Paused,
+			&rule.MaterializedUntil,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		list = append(list, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// InsertRecurringRule inserts a given rule into recurring_rules table.
+func (db DB) InsertRecurringRule(rule domain.RecurringRule) error {
+	result, err := db.db.Exec(
+		`INSERT INTO recurring_rules (name, amount, category_id, interval, every, day_of_month,
+start_date, end_date, count, paused, materialized_until) VALUES (?,?,?,?,?,?,?,?,?,?,?)`,
+		rule.Name,
+		rule.Amount,
+		nullableId(rule.CategoryId),
+		rule.Interval,
+		rule.Every,
+		rule.DayOfMonth,
+		rule.StartDate,
+		rule.EndDate,
+		rule.Count,
+		rule.Paused,
+		rule.MaterializedUntil,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	_, err = result.LastInsertId()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve last inserted id: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateRecurringRule updates an existing rule by its Id.
+func (db DB) UpdateRecurringRule(rule domain.RecurringRule) error {
+	result, err := db.db.Exec(
+		`UPDATE recurring_rules SET name=?, amount=?, category_id=?, interval=?, every=?, day_of_month=?,
+start_date=?, end_date=?, count=?, paused=?, materialized_until=? WHERE id=?`,
+		rule.Name,
+		rule.Amount,
+		nullableId(rule.CategoryId),
+		rule.Interval,
+		rule.Every,
+		rule.DayOfMonth,
+		rule.StartDate,
+		rule.EndDate,
+		rule.Count,
+		rule.Paused,
+		rule.MaterializedUntil,
+		rule.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find recurring rule with id %d", rule.Id)
+	}
+
+	return nil
+}
+
+// DeleteRecurringRule deletes a rule by its Id.
+// Expenses it generated are kept but no longer linked to it.
+func (db DB) DeleteRecurringRule(id int) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	statements := []string{
+		"UPDATE expenses SET rule_id=NULL WHERE rule_id=?",
+		"DELETE FROM recurring_rules WHERE id=?",
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, id); err != nil {
+			return fmt.Errorf("Could not delete from table: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// InsertRecurringOccurrences inserts the expenses generated from a rule and
+// moves its materialized_until date in a single transaction, so running it
+// twice for the same dates never duplicates expenses.
+func (db DB) InsertRecurringOccurrences(ruleId int, expenses []domain.Expense, materializedUntil string) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	for _, expense := range expenses {
+		_, err := tx.Exec(
+			"INSERT OR IGNORE INTO expenses (name, date, day, amount, category_id, rule_id, kind) VALUES (?,?,?,?,?,?,?)",
+			expense.Name,
+			expense.Date,
+			domain.RangeDate(expense.Date),
+			expense.Amount,
+			nullableId(expense.CategoryId),
+			ruleId,
+			domain.KindExpense,
+		)
+		if err != nil {
+			return fmt.Errorf("Could not insert into table: %w", err)
+		}
+	}
+
+	_, err = tx.Exec("UPDATE recurring_rules SET materialized_until=? WHERE id=?", materializedUntil, ruleId)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// UpdateRuleExpensesAfter updates name, amount and category of the expenses
+// generated from a rule dated after date (YYYY-MM-DD). Their split lines
+// are dropped as they no longer add up to the new amount.
+func (db DB) UpdateRuleExpensesAfter(ruleId int, date string, expense domain.Expense) error {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.Exec(
+		"DELETE FROM expense_splits WHERE expense_id IN (SELECT id FROM expenses WHERE rule_id=? AND day>?)",
+		ruleId,
+		date,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	_, err = tx.Exec(
+		"UPDATE expenses SET name=?, amount=?, category_id=? WHERE rule_id=? AND day>?",
+		expense.Name,
+		expense.Amount,
+		nullableId(expense.CategoryId),
+		ruleId,
+		date,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	return tx.Commit()
+}
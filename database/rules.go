@@ -0,0 +1,117 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetCategorizationRules returns every categorization rule
+// in the order they are tried.
+func (db *DB) GetCategorizationRules() ([]domain.CategorizationRule, error) {
+	rows, err := db.db.Query(
+		`SELECT r.id, r.priority, r.match_type, r.pattern, r.min_amount, r.max_amount,
+COALESCE(c.name, ''), COALESCE(r.category_id, 0), COALESCE(p.name, ''), COALESCE(r.payee_id, 0), r.tags
+FROM categorization_rules r
+LEFT JOIN categories c ON c.id = r.category_id
+LEFT JOIN payees p ON p.id = r.payee_id
+ORDER BY r.priority, r.id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	defer rows.Close()
+
+	list := []domain.CategorizationRule{}
+
+	for rows.Next() {
+		rule := domain.CategorizationRule{}
+		tags := ""
+		err := rows.Scan(
+			&rule.Id,
+			&rule.Priority,
+			&rule.MatchType,
+			&rule.Pattern,
+			&rule.MinAmount,
+			&rule.MaxAmount,
+			&rule.Category,
+			&rule.CategoryId,
+			&rule.Payee,
+			&rule.PayeeId,
+			&tags,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("Could not scan row: %w", err)
+		}
+		rule.Tags = splitTags(tags)
+		list = append(list, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("Could not iterate rows: %w", err)
+	}
+
+	return list, nil
+}
+
+// InsertCategorizationRule inserts a given rule into categorization_rules table.
+func (db DB) InsertCategorizationRule(rule domain.CategorizationRule) error {
+	_, err := db.db.Exec(
+		`INSERT INTO categorization_rules (priority, match_type, pattern, min_amount, max_amount, category_id, payee_id, tags)
+VALUES (?,?,?,?,?,?,?,?)`,
+		rule.Priority,
+		rule.MatchType,
+		rule.Pattern,
+		rule.MinAmount,
+		rule.MaxAmount,
+		nullableId(rule.CategoryId),
+		nullableId(rule.PayeeId),
+		joinTags(rule.Tags),
+	)
+	if err != nil {
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateCategorizationRule updates an existing rule by its Id.
+func (db DB) UpdateCategorizationRule(rule domain.CategorizationRule) error {
+	result, err := db.db.Exec(
+		`UPDATE categorization_rules SET priority=?, match_type=?, pattern=?, min_amount=?, max_amount=?,
+category_id=?, payee_id=?, tags=? WHERE id=?`,
+		rule.Priority,
+		rule.MatchType,
+		rule.Pattern,
+		rule.MinAmount,
+		rule.MaxAmount,
+		nullableId(rule.CategoryId),
+		nullableId(rule.PayeeId),
+		joinTags(rule.Tags),
+		rule.Id,
+	)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find categorization rule with id %d", rule.Id)
+	}
+
+	return nil
+}
+
+// DeleteCategorizationRule deletes a rule by its Id.
+func (db DB) DeleteCategorizationRule(id int) error {
+	if _, err := db.db.Exec("DELETE FROM categorization_rules WHERE id=?", id); err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	return nil
+}
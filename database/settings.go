@@ -0,0 +1,31 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GetSetting returns the value stored under key and whether it is set.
+func (db *DB) GetSetting(key string) (string, bool, error) {
+	value := ""
+
+	err := db.db.QueryRow("SELECT value FROM settings WHERE key=?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, fmt.Errorf("Could not query database: %w", err)
+	}
+
+	return value, true, nil
+}
+
+// UpdateSetting stores value under key, replacing any previous value.
+func (db DB) UpdateSetting(key, value string) error {
+	_, err := db.db.Exec("INSERT OR REPLACE INTO settings (key, value) VALUES (?,?)", key, value)
+	if err != nil {
+		return fmt.Errorf("Could not insert into table: %w", err)
+	}
+
+	return nil
+}
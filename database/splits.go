@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// splitsChunkSize bounds the number of expense ids bound to one query.
+const splitsChunkSize = 500
+
+// insertSplits inserts the split lines of an expense.
+func insertSplits(tx *sql.Tx, expenseId int64, splits []domain.Split) error {
+	for _, split := range splits {
+		_, err := tx.Exec(
+			"INSERT INTO expense_splits (expense_id, category_id, amount, note) VALUES (?,?,?,?)",
+			expenseId,
+			nullableId(split.CategoryId),
+			split.Amount,
+			split.Note,
+		)
+		if err != nil {
+			return fmt.Errorf("Could not insert into table: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// deleteSplits deletes every split line of an expense.
+func deleteSplits(tx *sql.Tx, expenseId int) error {
+	if _, err := tx.Exec("DELETE FROM expense_splits WHERE expense_id=?", expenseId); err != nil {
+		return fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	return nil
+}
+
+// attachSplits fills the Splits of every expense of the list.
+func (db *DB) attachSplits(list []domain.Expense) error {
+	indexes := map[int]int{}
+	ids := []any{}
+	for i, expense := range list {
+		indexes[expense.Id] = i
+		ids = append(ids, expense.Id)
+	}
+
+	for start := 0; start < len(ids); start += splitsChunkSize {
+		end := start + splitsChunkSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		placeholders := strings.TrimSuffix(strings.Repeat("?,", end-start), ",")
+		rows, err := db.db.Query(
+			`SELECT s.id, s.expense_id, COALESCE(c.name, ''), COALESCE(s.category_id, 0), s.amount, s.note
+FROM expense_splits s
+LEFT JOIN categories c ON c.id = s.category_id
+WHERE s.expense_id IN (`+placeholders+`) ORDER BY s.id`,
+			ids[start:end]...,
+		)
+		if err != nil {
+			return fmt.Errorf("Could not query database: %w", err)
+		}
+
+		for rows.Next() {
+			split := domain.Split{}
+			expenseId := 0
+			err := rows.Scan(&split.Id, &expenseId, &split.Category, &split.CategoryId, &split.Amount, &split.Note)
+			if err != nil {
+				rows.Close()
+				return fmt.Errorf("Could not scan row: %w", err)
+			}
+			i := indexes[expenseId]
+			list[i].Splits = append(list[i].Splits, split)
+		}
+
+		err = rows.Err()
+		rows.Close()
+		if err != nil {
+			return fmt.Errorf("Could not iterate rows: %w", err)
+		}
+	}
+
+	return nil
+}
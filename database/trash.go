@@ -0,0 +1,147 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// DeleteExpense moves an expense to the trash by its Id,
+// deletedAt being when (RFC 3339 in UTC).
+func (db DB) DeleteExpense(id int, deletedAt string) error {
+	result, err := db.db.Exec("UPDATE expenses SET deleted_at=? WHERE id=? AND deleted_at IS NULL", deletedAt, id)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find expense with id %d", id)
+	}
+
+	return nil
+}
+
+// GetDeletedExpenses returns the expenses in the trash,
+// the latest deleted first.
+func (db *DB) GetDeletedExpenses() ([]domain.Expense, error) {
+	return db.queryExpenses(selectExpenses + "WHERE e.deleted_at IS NOT NULL ORDER BY e.deleted_at DESC, e.id DESC")
+}
+
+// RestoreExpense takes an expense out of the trash by its Id.
+func (db DB) RestoreExpense(id int) error {
+	result, err := db.db.Exec("UPDATE expenses SET deleted_at=NULL WHERE id=? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return fmt.Errorf("Could not update table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if affected == 0 {
+		return fmt.Errorf("Could not find expense with id %d in the trash", id)
+	}
+
+	return nil
+}
+
+// PurgeExpense permanently deletes an expense in the trash by its Id,
+// along with its split lines and attachments.
+func (db DB) PurgeExpense(id int) error {
+	purged, err := db.purgeExpenses(true, "WHERE id=? AND deleted_at IS NOT NULL", id)
+	if err != nil {
+		return err
+	}
+
+	if purged == 0 {
+		return fmt.Errorf("Could not find expense with id %d in the trash", id)
+	}
+
+	return nil
+}
+
+// DiscardExpenses permanently deletes expenses by their Id as if they
+// were never inserted, for changes rolled back. Unlike purged ones,
+// their import ids can be imported again.
+func (db DB) DiscardExpenses(ids []int) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	args := []any{}
+	for _, id := range ids {
+		args = append(args, id)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(ids)), ",")
+
+	discarded, err := db.purgeExpenses(false, "WHERE id IN ("+placeholders+")", args...)
+	if err != nil {
+		return err
+	}
+
+	if discarded != len(ids) {
+		return fmt.Errorf("Could not find all %d expenses to discard", len(ids))
+	}
+
+	return nil
+}
+
+// purgeExpenses permanently deletes the expenses selected by a WHERE clause,
+// their split lines and their attachments, and returns how many.
+// Their import ids are kept when keepImportIds so they are never imported
+// again, and attachment files no other expense refers to are removed.
+func (db DB) purgeExpenses(keepImportIds bool, where string, args ...any) (int, error) {
+	tx, err := db.db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("Could not begin transaction: %w", err)
+	}
+
+	defer tx.Rollback()
+
+	selectIds := "SELECT id FROM expenses " + where
+
+	files, err := attachmentFiles(tx, "SELECT file FROM attachments WHERE expense_id IN ("+selectIds+")", args...)
+	if err != nil {
+		return 0, err
+	}
+
+	statements := []string{
+		"DELETE FROM expense_splits WHERE expense_id IN (" + selectIds + ")",
+		"DELETE FROM attachments WHERE expense_id IN (" + selectIds + ")",
+	}
+	if keepImportIds {
+		statements = append([]string{
+			"INSERT OR IGNORE INTO purged_import_ids (import_id) SELECT import_id FROM expenses " + where + " AND import_id IS NOT NULL",
+		}, statements...)
+	}
+
+	for _, statement := range statements {
+		if _, err := tx.Exec(statement, args...); err != nil {
+			return 0, fmt.Errorf("Could not delete from table: %w", err)
+		}
+	}
+
+	result, err := tx.Exec("DELETE FROM expenses "+where, args...)
+	if err != nil {
+		return 0, fmt.Errorf("Could not delete from table: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("Could not retrieve number of row affected: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("Could not commit transaction: %w", err)
+	}
+
+	return int(affected), db.collectAttachmentFiles(files)
+}
@@ -1,40 +1,623 @@
 package domain
 
-import "time"
+import (
+	"strings"
+	"time"
+)
 
 // STRUCTS
+
+// Kind tells whether a transaction is money going out or coming in.
+type Kind string
+
+const (
+	KindExpense  Kind = "expense"
+	KindIncome   Kind = "income"
+	KindTransfer Kind = "transfer"
+)
+
+// Expense is a transaction: money going out of AccountId, money coming in
+// with KindIncome, or money moved from AccountId to ToAccountId with
+// KindTransfer. AccountId 0 is the implicit pot without account.
+// Splits, when any, spread the Amount of an expense over several
+// categories and add up to it. Attachments is the number of documents
+// attached to it. Payee is who was paid, Tags free labels. ImportId
+// identifies the statement transaction it was imported from, if any.
 type Expense struct {
+	Id          int
+	Name        string
+	Date        string
+	Amount      Money
+	Category    string
+	CategoryId  int
+	RuleId      int
+	Kind        Kind
+	AccountId   int
+	Account     string
+	ToAccountId int
+	ToAccount   string
+	Splits      []Split
+	Attachments int
+	Payee       string
+	PayeeId     int
+	Tags        []string
+	ImportId    string
+	// DeletedAt is when the expense was moved to the trash, empty if not.
+	DeletedAt string
+}
+
+// Split is the share of an expense going to one category.
+type Split struct {
+	Id         int
+	Category   string
+	CategoryId int
+	Amount     Money
+	Note       string
+}
+
+// IsIncome reports whether the transaction is money coming in.
+func (e Expense) IsIncome() bool {
+	return e.Kind == KindIncome
+}
+
+// IsTransfer reports whether the transaction moves money between accounts.
+func (e Expense) IsTransfer() bool {
+	return e.Kind == KindTransfer
+}
+
+// IsSpending reports whether the transaction is money going out.
+func (e Expense) IsSpending() bool {
+	return !e.IsIncome() && !e.IsTransfer()
+}
+
+// CategoryAmounts returns the amount going to each category: its split
+// lines, or its whole amount to its own category when it is not split.
+func (e Expense) CategoryAmounts() []Split {
+	if len(e.Splits) > 0 {
+		return e.Splits
+	}
+
+	return []Split{{Category: e.Category, CategoryId: e.CategoryId, Amount: e.Amount}}
+}
+
+// Payee is a merchant or person money is paid to.
+type Payee struct {
+	Id   int
+	Name string
+}
+
+// MatchType is how a categorization rule compares its pattern
+// to the name of an expense.
+type MatchType string
+
+const (
+	MatchExact  MatchType = "exact"
+	MatchPrefix MatchType = "prefix"
+	MatchRegex  MatchType = "regex"
+)
+
+// CategorizationRule fills the category, payee and tags of expenses whose
+// name matches Pattern and whose amount lies between MinAmount and
+// MaxAmount (0 for no bound). Rules are tried by ascending Priority and
+// the first matching one applies.
+type CategorizationRule struct {
+	Id         int
+	Priority   int
+	MatchType  MatchType
+	Pattern    string
+	MinAmount  Money
+	MaxAmount  Money
+	Category   string
+	CategoryId int
+	Payee      string
+	PayeeId    int
+	Tags       []string
+}
+
+// RuleChange is an expense as it is and as it would be
+// after applying the categorization rules to it.
+type RuleChange struct {
+	Before Expense
+	After  Expense
+}
+
+// Attachment is a document, like a receipt, kept with an expense. Its
+// content is stored once per Hash (sha256) under File, named after it.
+type Attachment struct {
+	Id        int
+	ExpenseId int
+	Name      string
+	File      string
+	Hash      string
+	MimeType  string
+	Size      int64
+	AddedAt   string
+}
+
+// DateOrder is the order of the year, month and day of imported dates,
+// whatever separates them.
+type DateOrder string
+
+const (
+	DateYMD DateOrder = "YYYY-MM-DD"
+	DateDMY DateOrder = "DD-MM-YYYY"
+	DateMDY DateOrder = "MM-DD-YYYY"
+)
+
+// ExportFormat is a format expenses and budgets are exported in.
+type ExportFormat string
+
+const (
+	ExportCSV       ExportFormat = "csv"
+	ExportJSON      ExportFormat = "json"
+	ExportLedger    ExportFormat = "ledger"
+	ExportBeancount ExportFormat = "beancount"
+)
+
+// ExportOptions tells what to export: the expenses from one date to
+// another and the budgets of the months between, in Format. Currency is
+// the commodity of amounts, needed by beancount and optional otherwise.
+type ExportOptions struct {
+	From     string
+	To       string
+	Format   ExportFormat
+	Currency string
+}
+
+// ExportData is the expenses, income and transfers of a date range, out
+// of the trash, and the budgets of the months it covers, along with every
+// account and category they refer to.
+type ExportData struct {
+	From       string
+	To         string
+	Expenses   []Expense
+	Budgets    []MonthBudget
+	Accounts   []Account
+	Categories []Category
+}
+
+// MonthBudget is the base budget of a month (YYYY-MM) and the budget of
+// its categories by id, the default budgets filling those not set.
+type MonthBudget struct {
+	Month      string
+	Budget     Money
+	Categories map[int]Money
+}
+
+// ImportProfile tells how to read the statements of a bank. Columns of
+// CSV statements are named by their header or numbered from 1,
+// CategoryColumn being optional. Delimiter, Encoding and DecimalSeparator
+// are detected when empty. CSV statements list money going out as negative
+// amounts unless ExpensesPositive. Imported transactions go to AccountId.
+type ImportProfile struct {
+	Name             string
+	Delimiter        string
+	Encoding         string
+	HasHeader        bool
+	DateColumn       string
+	NameColumn       string
+	AmountColumn     string
+	CategoryColumn   string
+	DateOrder        DateOrder
+	DecimalSeparator string
+	ExpensesPositive bool
+	AccountId        int
+}
+
+// ImportRow is a transaction of a statement about to be imported. Line is
+// where it is in the file and Error why it cannot be imported. Duplicate
+// is an existing expense it likely is, Id 0 when none. Skip leaves it out
+// of the import.
+type ImportRow struct {
+	Line      int
+	Expense   Expense
+	Error     string
+	Duplicate Expense
+	Skip      bool
+}
+
+// ImportPreview is a statement read with a profile, with the delimiter
+// and encoding it was read with and the header of its columns.
+type ImportPreview struct {
+	Delimiter string
+	Encoding  string
+	Header    []string
+	Rows      []ImportRow
+}
+
+// AccountType is the kind of place money is kept in.
+type AccountType string
+
+const (
+	Checking   AccountType = "checking"
+	Savings    AccountType = "savings"
+	CreditCard AccountType = "credit card"
+	Cash       AccountType = "cash"
+)
+
+// Account is a place money is kept in, like a bank account or a wallet.
+type Account struct {
+	Id             int
+	Name           string
+	Type           AccountType
+	OpeningBalance Money
+}
+
+// BalanceEntry is a transaction of an account with the change it made
+// to the account and the balance right after it.
+type BalanceEntry struct {
+	Expense Expense
+	Change  Money
+	Balance Money
+}
+
+// AccountData is an account with every transaction and its current balance.
+type AccountData struct {
+	Account Account
+	Entries []BalanceEntry
+	Balance Money
+}
+
+// Category groups expenses. ParentId is 0 for top level categories.
+type Category struct {
 	Id       int
 	Name     string
-	Date     string
-	Amount   float64
-	Category string
+	ParentId int
+	Color    string
+	Icon     string
+	Archived bool
+}
+
+// Interval is the unit of time between two occurrences of a recurring rule.
+type Interval string
+
+const (
+	Daily   Interval = "daily"
+	Weekly  Interval = "weekly"
+	Monthly Interval = "monthly"
+	Yearly  Interval = "yearly"
+)
+
+// RecurringRule describes an expense repeating every Every intervals from
+// StartDate (YYYY-MM-DD). DayOfMonth overrides the day of monthly and yearly
+// occurrences (0 keeps the start day). EndDate ("" for none) and Count
+// (0 for unlimited) bound the occurrences. MaterializedUntil is the last
+// date up to which occurrences were turned into expenses.
+type RecurringRule struct {
+	Id                int
+	Name              string
+	Amount            Money
+	Category          string
+	CategoryId        int
+	Interval          Interval
+	Every             int
+	DayOfMonth        int
+	StartDate         string
+	EndDate           string
+	Count             int
+	Paused            bool
+	MaterializedUntil string
+}
+
+// RolloverMode tells how the leftover of a month carries into the next.
+type RolloverMode string
+
+const (
+	// RolloverNone never carries leftovers.
+	RolloverNone RolloverMode = "none"
+	// RolloverFull carries savings and overspending.
+	RolloverFull RolloverMode = "full"
+	// RolloverCapped carries overspending and savings up to a cap.
+	RolloverCapped RolloverMode = "capped"
+	// RolloverNegative only carries overspending.
+	RolloverNegative RolloverMode = "only-negative"
+)
+
+// RolloverSettings is the rollover mode with the cap of RolloverCapped.
+type RolloverSettings struct {
+	Mode RolloverMode
+	Cap  Money
 }
 
+// MonthData is the data of a month. Budget is the base budget of the month
+// plus the leftover carried over from the previous months.
 type MonthData struct {
 	Year           int
 	Month          time.Month
 	Expenses       []Expense
-	Budget         float64
-	TotalSpendings float64
-	MoneyLeft      float64
+	Budget         Money
+	BaseBudget     Money
+	CarriedOver    Money
+	TotalSpendings Money
+	MoneyLeft      Money
+	Categories     []CategoryData
+	TotalIncome    Money
+	NetCashFlow    Money
+	SavingsRate    float64
+}
+
+// MonthSummary is the totals of a month of a period.
+type MonthSummary struct {
+	Year           int
+	Month          time.Month
+	Budget         Money
+	TotalSpendings Money
+	MoneyLeft      Money
+	TotalIncome    Money
+	NetCashFlow    Money
+}
+
+// PeriodData is the data of consecutive months starting at StartMonth of
+// Year, like a quarter or a year. Budget is the sum of the base budgets
+// plus the leftover carried into its first month.
+type PeriodData struct {
+	Year           int
+	StartMonth     time.Month
+	Months         []MonthSummary
+	Budget         Money
+	TotalSpendings Money
+	MoneyLeft      Money
+	TotalIncome    Money
+	NetCashFlow    Money
+	SavingsRate    float64
+	Categories     []CategoryData
+}
+
+// CategoryData is the budget, spending and remaining money of a category
+// for a month. Spent includes the spending of its child categories.
+// CategoryId 0 gathers expenses without category.
+type CategoryData struct {
+	CategoryId int
+	Category   string
+	Budget     Money
+	Spent      Money
+	Remaining  Money
+}
+
+// MonthReport is the data of a month and of the month before it, to
+// compare them. Categories lists the budget and spending of every
+// category having any in either month, each one right after its parent.
+type MonthReport struct {
+	Month      MonthData
+	Previous   MonthData
+	Categories []CategoryComparison
+}
+
+// CategoryComparison is the budget and spending of a category for a month
+// and its spending the month before. The spending of a category with a
+// ParentId is also counted in its parent.
+type CategoryComparison struct {
+	CategoryId    int
+	Category      string
+	ParentId      int
+	Budget        Money
+	Spent         Money
+	Remaining     Money
+	PreviousSpent Money
+}
+
+// Action is a change recorded in the undo history. Id grows with every
+// recorded change, telling apart two changes with the same Description.
+type Action struct {
+	Id          int
+	Description string
+}
+
+// AuditAction is the kind of change an audit entry records.
+type AuditAction string
+
+const (
+	AuditInsert  AuditAction = "insert"
+	AuditUpdate  AuditAction = "update"
+	AuditDelete  AuditAction = "delete"
+	AuditRestore AuditAction = "restore"
+	AuditPurge   AuditAction = "purge"
+)
+
+// AuditEntity is the kind of record an audit entry is about.
+type AuditEntity string
+
+const (
+	// AuditExpense entries are keyed by the id of the expense.
+	AuditExpense AuditEntity = "expense"
+	// AuditBudget entries are keyed by month (YYYY-MM) or "default".
+	AuditBudget AuditEntity = "budget"
+	// AuditCategoryBudget entries are keyed like AuditBudget.
+	AuditCategoryBudget AuditEntity = "category budget"
+)
+
+// AuditEntry is a change recorded in the append-only audit log. Before
+// and After are the record as JSON, empty when it did not exist.
+// Timestamp is RFC 3339 in UTC and User is empty when unknown.
+type AuditEntry struct {
+	Id        int
+	Timestamp string
+	User      string
+	Action    AuditAction
+	Entity    AuditEntity
+	Key       string
+	Before    string
+	After     string
+}
+
+// AuditQuery selects audit entries, empty fields matching any entry.
+// From and To are RFC 3339 timestamps, both included, and Limit is the
+// most entries returned, the latest first.
+type AuditQuery struct {
+	Entity AuditEntity
+	Key    string
+	User   string
+	From   string
+	To     string
+	Limit  int
 }
 
 // INTERFACES
 type Storage interface {
-	GetExpensesWithYearMonth(string) []Expense
-	InsertExpense(Expense) error
-	GetDefaultBudget() string
-	GetBudgetWithYearMonth(string) string
-	InsertBudget(string, string) error
-	UpdateDefaultBudget(string) error
-	DeleteExpense(int) error
+	GetExpensesInRange(string, string) ([]Expense, error)
+	GetExpenseWithId(int) (Expense, bool, error)
+	GetExpenseWithImportId(string) (Expense, bool, error)
+	IsImportIdPurged(string) (bool, error)
+	InsertExpense(Expense) (int, error)
+	InsertExpenses([]Expense) ([]int, error)
+	UpdateExpense(Expense) error
+	GetDefaultBudget() (Money, error)
+	GetBudgetWithYearMonth(string) (Money, bool, error)
+	InsertBudget(Money, string) error
+	DeleteBudget(string) error
+	UpdateDefaultBudget(Money) error
+	DeleteExpense(int, string) error
+	GetDeletedExpenses() ([]Expense, error)
+	RestoreExpense(int) error
+	PurgeExpense(int) error
+	DiscardExpenses([]int) error
+	InsertAuditEntries([]AuditEntry) error
+	GetAuditEntries(AuditQuery) ([]AuditEntry, error)
+	GetCategories() ([]Category, error)
+	GetCategoryWithName(string) (Category, bool, error)
+	InsertCategory(Category) (int, error)
+	UpdateCategory(Category) error
+	DeleteCategory(int) error
+	GetCategoryBudgets(string) (map[int]Money, error)
+	InsertCategoryBudget(int, Money, string) error
+	DeleteCategoryBudget(int, string) error
+	GetRecurringRules() ([]RecurringRule, error)
+	InsertRecurringRule(RecurringRule) error
+	UpdateRecurringRule(RecurringRule) error
+	DeleteRecurringRule(int) error
+	InsertRecurringOccurrences(int, []Expense, string) error
+	UpdateRuleExpensesAfter(int, string, Expense) error
+	GetAccounts() ([]Account, error)
+	InsertAccount(Account) error
+	UpdateAccount(Account) error
+	DeleteAccount(int) error
+	GetAccountTransactions(int) ([]Expense, error)
+	GetAttachments(int) ([]Attachment, error)
+	InsertAttachment(Attachment, []byte) error
+	DeleteAttachment(int) error
+	AttachmentPath(string) string
+	GetExpenses() ([]Expense, error)
+	GetPayees() ([]Payee, error)
+	GetPayeeWithName(string) (Payee, bool, error)
+	InsertPayee(Payee) (int, error)
+	GetCategorizationRules() ([]CategorizationRule, error)
+	InsertCategorizationRule(CategorizationRule) error
+	UpdateCategorizationRule(CategorizationRule) error
+	DeleteCategorizationRule(int) error
+	GetFirstExpenseDate() (string, bool, error)
+	GetSetting(string) (string, bool, error)
+	UpdateSetting(string, string) error
 }
 
 type API interface {
-	CreateMonthData(int, time.Month) MonthData
+	CreateMonthData(int, time.Month) (MonthData, error)
+	CreateYearData(int) (PeriodData, error)
+	CreatePeriodData(int, time.Month, int) (PeriodData, error)
+	GetExpensesInRange(string, string) ([]Expense, error)
 	AddExpense(Expense) error
+	UpdateExpense(Expense) error
 	RemoveExpense(int) error
 	InsertBudgetMonth(string, string) error
+	InsertBudgetMonthAndDefault(string, string) error
 	UpdateDefaultBudget(string) error
+	GetCategories() ([]Category, error)
+	AddCategory(Category) error
+	UpdateCategory(Category) error
+	RemoveCategory(int) error
+	InsertCategoryBudgetMonth(string, string, string) error
+	InsertCategoryBudgetMonthAndDefault(string, string, string) error
+	UpdateDefaultCategoryBudget(string, string) error
+	GetRecurringRules() ([]RecurringRule, error)
+	AddRecurringRule(RecurringRule) error
+	UpdateRecurringRule(RecurringRule, bool) error
+	RemoveRecurringRule(int) error
+	MaterializeRecurring() error
+	GetAccounts() ([]Account, error)
+	AddAccount(Account) error
+	UpdateAccount(Account) error
+	RemoveAccount(int) error
+	CreateAccountData(int) (AccountData, error)
+	GetAttachments(int) ([]Attachment, error)
+	AttachFile(int, string) error
+	RemoveAttachment(int) error
+	AttachmentPath(Attachment) string
+	GetPayees() ([]Payee, error)
+	GetCategorizationRules() ([]CategorizationRule, error)
+	AddCategorizationRule(CategorizationRule) error
+	UpdateCategorizationRule(CategorizationRule) error
+	RemoveCategorizationRule(int) error
+	MoveCategorizationRule(int, int) error
+	PreviewReapplyRules() ([]RuleChange, error)
+	ApplyRuleChanges([]RuleChange) error
+	GetRolloverSettings() (RolloverSettings, error)
+	UpdateRolloverSettings(RolloverSettings) error
+	GetTrash() ([]Expense, error)
+	RestoreExpense(int) error
+	PurgeExpense(int) error
+	PurgeExpiredTrash() (int, error)
+	GetTrashRetention() (int, error)
+	UpdateTrashRetention(int) error
+	GetAuditLog(AuditQuery) ([]AuditEntry, error)
+	GetExpenseHistory(int) ([]AuditEntry, error)
+	GetBudgetHistory(string) ([]AuditEntry, error)
+	Undo() (Action, error)
+	Redo() (Action, error)
+	LastAction() (Action, bool)
+	PreviewImport(string, ImportProfile) (ImportPreview, error)
+	ImportExpenses([]ImportRow) (int, error)
+	GetImportProfiles() ([]ImportProfile, error)
+	SaveImportProfile(ImportProfile) error
+	RemoveImportProfile(string) error
+	CreateExportData(string, string) (ExportData, error)
+	Export(string, ExportOptions) (int, error)
+	CreateMonthReport(int, time.Month) (MonthReport, error)
+	WriteMonthReport(int, time.Month, string) error
+}
+
+// FUNCTIONS
+
+// RangeDate returns the day (YYYY-MM-DD) a date (YYYY-MM-DD or YYYY-MM)
+// counts as in range queries. Month-only dates count as the first day of
+// their month, so they are part of a range only when it includes that day.
+func RangeDate(date string) string {
+	parts := strings.Split(date, "-")
+	if len(parts) < 2 {
+		return date
+	}
+
+	for len(parts[0]) < 4 {
+		parts[0] = "0" + parts[0]
+	}
+
+	if len(parts) == 2 {
+		parts = append(parts, "01")
+	}
+
+	return strings.Join(parts[:3], "-")
+}
+
+// NormalizeCategoryName trims a category name and collapses inner spaces
+// so that "food " and "food" end up in the same category.
+func NormalizeCategoryName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// NormalizeTags splits tags on commas, trims and lowercases them, dropping
+// empty and duplicated ones while keeping their order.
+func NormalizeTags(tags []string) []string {
+	seen := map[string]bool{}
+	list := []string{}
+
+	for _, entry := range tags {
+		for _, tag := range strings.Split(entry, ",") {
+			tag = strings.ToLower(strings.Join(strings.Fields(tag), " "))
+			if tag == "" || seen[tag] {
+				continue
+			}
+			seen[tag] = true
+			list = append(list, tag)
+		}
+	}
+
+	return list
 }
@@ -0,0 +1,172 @@
+package domain
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Money is an exact amount of money stored as integer minor units (cents).
+type Money int64
+
+// MinorUnits is the number of minor units in one major unit.
+const MinorUnits = 100
+
+// ParseMoney parses a decimal string (12, 12.3, 12.34, -0.50, 12,34)
+// into Money without going through floating point.
+func ParseMoney(amount string) (Money, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" {
+		return 0, errors.New("Amount should not be empty.")
+	}
+
+	negative := false
+	if amount[0] == '-' || amount[0] == '+' {
+		negative = amount[0] == '-'
+		amount = amount[1:]
+	}
+
+	amount = strings.Replace(amount, ",", ".", 1)
+	whole, fraction, hasFraction := strings.Cut(amount, ".")
+
+	if whole == "" && (!hasFraction || fraction == "") {
+		return 0, fmt.Errorf("Could not parse amount %q.", amount)
+	}
+
+	if len(fraction) > 2 {
+		return 0, errors.New("Amount should have at most 2 decimals.")
+	}
+
+	for _, r := range whole + fraction {
+		if r < '0' || r > '9' {
+			return 0, fmt.Errorf("Could not parse amount %q.", amount)
+		}
+	}
+
+	units := int64(0)
+	if whole != "" {
+		parsed, err := strconv.ParseInt(whole, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Could not parse amount: %w", err)
+		}
+		units = parsed
+	}
+
+	cents := int64(0)
+	if fraction != "" {
+		for len(fraction) < 2 {
+			fraction += "0"
+		}
+		parsed, err := strconv.ParseInt(fraction, 10, 64)
+		if err != nil {
+			return 0, fmt.Errorf("Could not parse amount: %w", err)
+		}
+		cents = parsed
+	}
+
+	if units > (math.MaxInt64-cents)/MinorUnits {
+		return 0, errors.New("Amount is too large.")
+	}
+
+	money := Money(units*MinorUnits + cents)
+	if negative {
+		money = -money
+	}
+
+	return money, nil
+}
+
+// MustParseMoney is like ParseMoney but panics on invalid input.
+// Only meant for constants in code.
+func MustParseMoney(amount string) Money {
+	money, err := ParseMoney(amount)
+	if err != nil {
+		panic(err)
+	}
+	return money
+}
+
+// String formats Money with two decimals (-12.34).
+func (m Money) String() string {
+	sign := ""
+	value := int64(m)
+	if value < 0 {
+		sign = "-"
+		value = -value
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, value/MinorUnits, value%MinorUnits)
+}
+
+// Add returns the sum of m and other.
+func (m Money) Add(other Money) Money {
+	return m + other
+}
+
+// Sub returns the difference of m and other.
+func (m Money) Sub(other Money) Money {
+	return m - other
+}
+
+// Sum returns the total of all given amounts.
+func Sum(amounts ...Money) Money {
+	total := Money(0)
+	for _, amount := range amounts {
+		total += amount
+	}
+	return total
+}
+
+// Allocate splits m into parts proportional to ratios so that the parts
+// always add up exactly to m. Leftover minor units go to the first parts.
+// Ratios should not be negative and should not all be 0.
+func (m Money) Allocate(ratios ...int) ([]Money, error) {
+	parts := make([]Money, len(ratios))
+
+	totalRatio := 0
+	for _, ratio := range ratios {
+		if ratio < 0 {
+			return nil, errors.New("Ratios should not be negative.")
+		}
+		totalRatio += ratio
+	}
+
+	if totalRatio == 0 {
+		return nil, errors.New("Ratios should not all be 0.")
+	}
+
+	remainder := m
+	for i, ratio := range ratios {
+		parts[i] = m * Money(ratio) / Money(totalRatio)
+		remainder -= parts[i]
+	}
+
+	step := Money(1)
+	if remainder < 0 {
+		step = -1
+	}
+
+	for i := 0; remainder != 0; i = (i + 1) % len(parts) {
+		if ratios[i] == 0 {
+			continue
+		}
+		parts[i] += step
+		remainder -= step
+	}
+
+	return parts, nil
+}
+
+// Split splits m into n equal parts adding up exactly to m.
+func (m Money) Split(n int) ([]Money, error) {
+	if n < 1 {
+		return nil, errors.New("Amount should be split in at least one part.")
+	}
+
+	ratios := make([]int, n)
+	for i := range ratios {
+		ratios[i] = 1
+	}
+	return m.Allocate(ratios...)
+}
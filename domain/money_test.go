@@ -0,0 +1,112 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		amount string
+		want   Money
+		fails  bool
+	}{
+		{amount: "12", want: 1200},
+		{amount: "12.3", want: 1230},
+		{amount: "12.34", want: 1234},
+		{amount: "12,34", want: 1234},
+		{amount: "-0.50", want: -50},
+		{amount: "+7", want: 700},
+		{amount: ".5", want: 50},
+		{amount: " 3. ", want: 300},
+		{amount: "92233720368547758", want: 9223372036854775800},
+		{amount: "-92233720368547758.07", want: -9223372036854775807},
+		{amount: "92233720368547758.08", fails: true},
+		{amount: "92233720368547759", fails: true},
+		{amount: "99999999999999999999", fails: true},
+		{amount: "", fails: true},
+		{amount: "-", fails: true},
+		{amount: ".", fails: true},
+		{amount: "1.234", fails: true},
+		{amount: "1.2.3", fails: true},
+		{amount: "12a", fails: true},
+		{amount: "--1", fails: true},
+	}
+
+	for _, test := range tests {
+		got, err := ParseMoney(test.amount)
+		if test.fails {
+			if err == nil {
+				t.Errorf("ParseMoney(%q) = %v, want an error", test.amount, got)
+			}
+			continue
+		}
+		if err != nil || got != test.want {
+			t.Errorf("ParseMoney(%q) = %v, %v, want %v", test.amount, got, err, test.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := map[Money]string{
+		0:     "0.00",
+		5:     "0.05",
+		-5:    "-0.05",
+		1234:  "12.34",
+		-1200: "-12.00",
+	}
+
+	for money, want := range tests {
+		if got := money.String(); got != want {
+			t.Errorf("Money(%d).String() = %q, want %q", int64(money), got, want)
+		}
+	}
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		amount Money
+		ratios []int
+		want   []Money
+		fails  bool
+	}{
+		{amount: 100, ratios: []int{1, 1, 1}, want: []Money{34, 33, 33}},
+		{amount: 101, ratios: []int{1, 1}, want: []Money{51, 50}},
+		{amount: 5, ratios: []int{3, 7}, want: []Money{2, 3}},
+		{amount: -100, ratios: []int{1, 1, 1}, want: []Money{-34, -33, -33}},
+		{amount: 100, ratios: []int{0, 1, 1}, want: []Money{0, 50, 50}},
+		{amount: 1, ratios: []int{0, 1, 1}, want: []Money{0, 1, 0}},
+		{amount: 0, ratios: []int{2, 1}, want: []Money{0, 0}},
+		{amount: 100, ratios: []int{1}, want: []Money{100}},
+		{amount: 100, ratios: []int{0, 0}, fails: true},
+		{amount: 100, ratios: []int{1, -1}, fails: true},
+		{amount: 100, ratios: []int{}, fails: true},
+	}
+
+	for _, test := range tests {
+		got, err := test.amount.Allocate(test.ratios...)
+		if test.fails {
+			if err == nil {
+				t.Errorf("%v.Allocate(%v) = %v, want an error", test.amount, test.ratios, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v.Allocate(%v) = %v, %v, want %v", test.amount, test.ratios, got, err, test.want)
+		}
+		if Sum(got...) != test.amount {
+			t.Errorf("%v.Allocate(%v) adds up to %v", test.amount, test.ratios, Sum(got...))
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	got, err := Money(1000).Split(3)
+	if err != nil || !reflect.DeepEqual(got, []Money{334, 333, 333}) {
+		t.Errorf("Split(3) = %v, %v", got, err)
+	}
+
+	if _, err := Money(1000).Split(0); err == nil {
+		t.Error("Split(0) should fail")
+	}
+}
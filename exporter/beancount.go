@@ -0,0 +1,158 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// writeBeancount writes the expenses as beancount transactions, after
+// opening every account they use, and the budgets as custom directives,
+// the ones of categories in the format of Fava.
+func writeBeancount(w io.Writer, data domain.ExportData, currency string) error {
+	n := newNames(data)
+	opened := map[string]bool{}
+	body := strings.Builder{}
+
+	open := func(account string) string {
+		opened[account] = true
+		return account
+	}
+
+	for _, budget := range data.Budgets {
+		date := budget.Month + "-01"
+		fmt.Fprintf(&body, "%s custom \"monthly-budget\" %s %s\n", date, budget.Budget, currency)
+		for _, category := range n.sortedBudgets(budget) {
+			account := open(n.beancountCategory("Expenses", category.CategoryId))
+			fmt.Fprintf(&body, "%s custom \"budget\" %s \"monthly\" %s %s\n", date, account, category.Amount, currency)
+		}
+	}
+
+	for _, expense := range data.Expenses {
+		fmt.Fprintf(&body, "\n%s * %s %s", domain.RangeDate(expense.Date), beancountString(expense.Payee), beancountString(expense.Name))
+		for _, tag := range expense.Tags {
+			if tag = beancountTag(tag); tag != "" {
+				body.WriteString(" #" + tag)
+			}
+		}
+		body.WriteString("\n")
+
+		account := open(n.beancountAccount(expense.AccountId))
+		postings := []ledgerPosting{}
+
+		switch expense.Kind {
+		case domain.KindTransfer:
+			postings = append(postings,
+				ledgerPosting{account: open(n.beancountAccount(expense.ToAccountId)), amount: expense.Amount},
+				ledgerPosting{account: account, amount: -expense.Amount},
+			)
+		case domain.KindIncome:
+			postings = append(postings,
+				ledgerPosting{account: account, amount: expense.Amount},
+				ledgerPosting{account: open(n.beancountCategory("Income", expense.CategoryId)), amount: -expense.Amount},
+			)
+		default:
+			for _, split := range expense.CategoryAmounts() {
+				postings = append(postings, ledgerPosting{account: open(n.beancountCategory("Expenses", split.CategoryId)), amount: split.Amount})
+			}
+			postings = append(postings, ledgerPosting{account: account, amount: -expense.Amount})
+		}
+
+		for _, p := range postings {
+			fmt.Fprintf(&body, "  %s  %s %s\n", p.account, p.amount, currency)
+		}
+	}
+
+	accounts := []string{}
+	for account := range opened {
+		accounts = append(accounts, account)
+	}
+	sort.Strings(accounts)
+
+	openDate := domain.RangeDate(data.From)
+	if len(openDate) >= 8 {
+		openDate = openDate[:8] + "01"
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprintf(writer, "; expenses from %s to %s\n", data.From, data.To)
+	fmt.Fprintf(writer, "option \"operating_currency\" %s\n\n", beancountString(currency))
+	for _, account := range accounts {
+		fmt.Fprintf(writer, "%s open %s\n", openDate, account)
+	}
+	fmt.Fprintf(writer, "\n%s", body.String())
+
+	return writer.Flush()
+}
+
+// beancountString returns text quoted as a beancount string.
+func beancountString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", " ").Replace(text) + `"`
+}
+
+// beancountTag returns tag with the characters beancount tags cannot hold
+// replaced by dashes.
+func beancountTag(tag string) string {
+	return strings.Trim(strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("-_/.", r)) {
+			return r
+		}
+		return '-'
+	}, tag), "-")
+}
+
+// beancountComponent returns name as a component of a beancount account:
+// starting with a capital letter or a digit and holding only letters,
+// digits and single dashes.
+func beancountComponent(name string) string {
+	component := strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), "-")
+
+	first := []rune(component)
+	if len(first) == 0 {
+		return "X"
+	}
+
+	first[0] = unicode.ToUpper(first[0])
+	if !unicode.IsUpper(first[0]) && !unicode.IsDigit(first[0]) {
+		return "X" + string(first)
+	}
+
+	return string(first)
+}
+
+// beancountAccount returns the beancount account of an account: under
+// Liabilities for credit cards, Assets otherwise.
+func (n names) beancountAccount(id int) string {
+	account, ok := n.accounts[id]
+	if !ok {
+		return "Assets:Unassigned"
+	}
+
+	top := "Assets"
+	if account.Type == domain.CreditCard {
+		top = "Liabilities"
+	}
+
+	return top + ":" + beancountComponent(account.Name)
+}
+
+// beancountCategory returns the beancount account of a category under top.
+func (n names) beancountCategory(top string, id int) string {
+	path := n.categoryPath(id)
+	if len(path) == 0 {
+		return top + ":Uncategorized"
+	}
+
+	for _, name := range path {
+		top += ":" + beancountComponent(name)
+	}
+
+	return top
+}
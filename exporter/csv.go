@@ -0,0 +1,76 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// csvHeader is the first line of exported CSV files.
+var csvHeader = []string{"record", "date", "name", "kind", "amount", "category", "account", "to_account", "payee", "tags", "note", "import_id"}
+
+// writeCSV writes a line per expense, followed by a line per split of it,
+// and a line per budget of a month and of its categories. Amounts are
+// positive, their kind telling which way money went.
+func writeCSV(w io.Writer, data domain.ExportData) error {
+	n := newNames(data)
+	writer := csv.NewWriter(w)
+
+	if err := writer.Write(csvHeader); err != nil {
+		return err
+	}
+
+	for _, expense := range data.Expenses {
+		err := writer.Write([]string{
+			"expense",
+			expense.Date,
+			expense.Name,
+			string(expense.Kind),
+			expense.Amount.String(),
+			strings.Join(n.categoryPath(expense.CategoryId), ":"),
+			n.account(expense.AccountId),
+			n.account(expense.ToAccountId),
+			expense.Payee,
+			strings.Join(expense.Tags, ","),
+			"",
+			expense.ImportId,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, split := range expense.Splits {
+			err := writer.Write([]string{
+				"split",
+				expense.Date,
+				expense.Name,
+				string(expense.Kind),
+				split.Amount.String(),
+				strings.Join(n.categoryPath(split.CategoryId), ":"),
+				"", "", "", "",
+				split.Note,
+				"",
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, budget := range data.Budgets {
+		if err := writer.Write([]string{"budget", budget.Month, "", "", budget.Budget.String(), "", "", "", "", "", "", ""}); err != nil {
+			return err
+		}
+
+		for _, category := range n.sortedBudgets(budget) {
+			if err := writer.Write([]string{"category budget", budget.Month, "", "", category.Amount.String(), category.Category, "", "", "", "", "", ""}); err != nil {
+				return err
+			}
+		}
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
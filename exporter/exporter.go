@@ -0,0 +1,122 @@
+// Package exporter writes expenses and budgets in formats other tools read.
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Formats lists the formats Write writes.
+var Formats = []domain.ExportFormat{domain.ExportCSV, domain.ExportJSON, domain.ExportLedger, domain.ExportBeancount}
+
+// extensions maps the extensions of exported files to their format.
+var extensions = map[string]domain.ExportFormat{
+	".csv":       domain.ExportCSV,
+	".json":      domain.ExportJSON,
+	".ledger":    domain.ExportLedger,
+	".journal":   domain.ExportLedger,
+	".hledger":   domain.ExportLedger,
+	".j":         domain.ExportLedger,
+	".beancount": domain.ExportBeancount,
+	".bean":      domain.ExportBeancount,
+}
+
+// FormatOf returns the format of the file at path told by its extension.
+func FormatOf(path string) (domain.ExportFormat, bool) {
+	format, ok := extensions[strings.ToLower(filepath.Ext(path))]
+	return format, ok
+}
+
+// Write writes data to w in format, amounts in currency when set.
+// Beancount needs a currency.
+func Write(w io.Writer, data domain.ExportData, format domain.ExportFormat, currency string) error {
+	var err error
+
+	switch format {
+	case domain.ExportCSV:
+		err = writeCSV(w, data)
+	case domain.ExportJSON:
+		err = writeJSON(w, data, currency)
+	case domain.ExportLedger:
+		err = writeLedger(w, data, currency)
+	case domain.ExportBeancount:
+		if currency == "" {
+			return errors.New("Currency should be set for beancount.")
+		}
+		err = writeBeancount(w, data, currency)
+	default:
+		return fmt.Errorf("Export format %q does not exist.", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Could not write %s: %w", format, err)
+	}
+
+	return nil
+}
+
+// names names the accounts and categories of exported data.
+type names struct {
+	accounts   map[int]domain.Account
+	categories map[int]domain.Category
+}
+
+// newNames returns the names of the accounts and categories of data.
+func newNames(data domain.ExportData) names {
+	n := names{accounts: map[int]domain.Account{}, categories: map[int]domain.Category{}}
+
+	for _, account := range data.Accounts {
+		n.accounts[account.Id] = account
+	}
+	for _, category := range data.Categories {
+		n.categories[category.Id] = category
+	}
+
+	return n
+}
+
+// account returns the name of an account, empty without one.
+func (n names) account(id int) string {
+	return n.accounts[id].Name
+}
+
+// categoryPath returns the names of a category and its ancestors,
+// the top one first, none without category.
+func (n names) categoryPath(id int) []string {
+	path := []string{}
+
+	for depth := 0; id != 0 && depth < len(n.categories); depth++ {
+		category, ok := n.categories[id]
+		if !ok {
+			break
+		}
+		path = append([]string{category.Name}, path...)
+		id = category.ParentId
+	}
+
+	return path
+}
+
+// sortedBudgets returns the category budgets of a month ordered by the
+// path of their category.
+func (n names) sortedBudgets(budget domain.MonthBudget) []domain.Split {
+	budgets := []domain.Split{}
+	for id, amount := range budget.Categories {
+		budgets = append(budgets, domain.Split{CategoryId: id, Category: strings.Join(n.categoryPath(id), ":"), Amount: amount})
+	}
+
+	sort.Slice(budgets, func(i, j int) bool {
+		if budgets[i].Category != budgets[j].Category {
+			return budgets[i].Category < budgets[j].Category
+		}
+		return budgets[i].CategoryId < budgets[j].CategoryId
+	})
+
+	return budgets
+}
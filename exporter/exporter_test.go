@@ -0,0 +1,143 @@
+package exporter
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of testdata")
+
+// testData holds names to escape, splits, income, transfers, a refund
+// written as a negative expense and budgets.
+var testData = domain.ExportData{
+	From: "2023-02",
+	To:   "2023-02-28",
+	Accounts: []domain.Account{
+		{Id: 1, Name: "Main", Type: domain.Checking, OpeningBalance: 10000},
+		{Id: 2, Name: "Visa  card", Type: domain.CreditCard, OpeningBalance: -2500},
+	},
+	Categories: []domain.Category{
+		{Id: 1, Name: "Food & drink"},
+		{Id: 2, Name: `café "bar"`, ParentId: 1},
+		{Id: 3, Name: "Salary"},
+		{Id: 4, Name: "Old", Archived: true},
+	},
+	Expenses: []domain.Expense{
+		{
+			Id: 1, Date: "2023-02-01", Name: "Lunch, \"with\" Bob", Kind: domain.KindExpense, Amount: 2550,
+			CategoryId: 2, AccountId: 2, Payee: "Bistro\tLe Coin", Tags: []string{"work trip", "#meals"},
+			ImportId: "hash:0102",
+		},
+		{
+			Id: 2, Date: "2023-02", Name: "Groceries\nand more", Kind: domain.KindExpense, Amount: 10000,
+			AccountId: 1, Splits: []domain.Split{
+				{CategoryId: 1, Amount: 7000, Note: "food"},
+				{CategoryId: 0, Amount: 3000, Note: "soap;  towels"},
+			},
+		},
+		{Id: 3, Date: "2023-02-25", Name: "Salary", Kind: domain.KindIncome, Amount: 250000, CategoryId: 3, AccountId: 1},
+		{Id: 4, Date: "2023-02-26", Name: "Pay card", Kind: domain.KindTransfer, Amount: 2550, AccountId: 1, ToAccountId: 2},
+		{Id: 5, Date: "2023-02-27", Name: "Refund", Kind: domain.KindExpense, Amount: -1200, CategoryId: 4},
+	},
+	Budgets: []domain.MonthBudget{
+		{Month: "2023-02", Budget: 150000, Categories: map[int]domain.Money{2: 20000, 1: 50000}},
+	},
+}
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		format   domain.ExportFormat
+		currency string
+		golden   string
+	}{
+		{domain.ExportCSV, "", "export.csv"},
+		{domain.ExportJSON, "EUR", "export.json"},
+		{domain.ExportLedger, "", "export.ledger"},
+		{domain.ExportLedger, "EUR", "export-currency.ledger"},
+		{domain.ExportBeancount, "EUR", "export.beancount"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.golden, func(t *testing.T) {
+			out := bytes.Buffer{}
+			if err := Write(&out, testData, test.format, test.currency); err != nil {
+				t.Fatal(err)
+			}
+
+			path := filepath.Join("testdata", test.golden)
+			if *update {
+				if err := os.WriteFile(path, out.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if out.String() != string(want) {
+				t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+			}
+		})
+	}
+}
+
+func TestWriteErrors(t *testing.T) {
+	out := bytes.Buffer{}
+
+	if err := Write(&out, testData, domain.ExportBeancount, ""); err == nil {
+		t.Error("wrote beancount without currency")
+	}
+
+	if err := Write(&out, testData, "xml", ""); err == nil {
+		t.Error("wrote an unknown format")
+	}
+
+	if out.Len() != 0 {
+		t.Errorf("wrote %q on errors", out.String())
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+	tests := map[string]domain.ExportFormat{
+		"out.CSV":        domain.ExportCSV,
+		"dir/out.json":   domain.ExportJSON,
+		"out.journal":    domain.ExportLedger,
+		"out.hledger":    domain.ExportLedger,
+		"out.bean":       domain.ExportBeancount,
+		"out.beancount":  domain.ExportBeancount,
+		"out.txt":        "",
+		"out.csv.backup": "",
+	}
+
+	for path, want := range tests {
+		got, ok := FormatOf(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("FormatOf(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestBeancountComponent(t *testing.T) {
+	tests := map[string]string{
+		"Food & drink": "Food-drink",
+		"café":         "Café",
+		"2023 trip":    "2023-trip",
+		"-":            "X",
+		"ça va":        "Ça-va",
+		"_private":     "Private",
+		"日本":           "X日本",
+	}
+
+	for name, want := range tests {
+		if got := beancountComponent(name); got != want {
+			t.Errorf("beancountComponent(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package exporter
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Version is the version of the JSON schema, raised whenever a field
+// changes meaning or goes away. New fields keep the version.
+const Version = 1
+
+type jsonExport struct {
+	Version    int            `json:"version"`
+	From       string         `json:"from"`
+	To         string         `json:"to"`
+	Currency   string         `json:"currency,omitempty"`
+	Accounts   []jsonAccount  `json:"accounts"`
+	Categories []jsonCategory `json:"categories"`
+	Expenses   []jsonExpense  `json:"expenses"`
+	Budgets    []jsonBudget   `json:"budgets"`
+}
+
+type jsonAccount struct {
+	Id             int    `json:"id"`
+	Name           string `json:"name"`
+	Type           string `json:"type"`
+	OpeningBalance string `json:"opening_balance"`
+}
+
+type jsonCategory struct {
+	Id       int    `json:"id"`
+	Name     string `json:"name"`
+	Path     string `json:"path"`
+	ParentId int    `json:"parent_id"`
+	Archived bool   `json:"archived"`
+}
+
+type jsonExpense struct {
+	Id          int         `json:"id"`
+	Date        string      `json:"date"`
+	Name        string      `json:"name"`
+	Kind        string      `json:"kind"`
+	Amount      string      `json:"amount"`
+	Category    string      `json:"category"`
+	AccountId   int         `json:"account_id"`
+	Account     string      `json:"account"`
+	ToAccountId int         `json:"to_account_id"`
+	ToAccount   string      `json:"to_account"`
+	Payee       string      `json:"payee"`
+	Tags        []string    `json:"tags"`
+	Splits      []jsonSplit `json:"splits"`
+	ImportId    string      `json:"import_id"`
+}
+
+type jsonSplit struct {
+	Category string `json:"category"`
+	Amount   string `json:"amount"`
+	Note     string `json:"note"`
+}
+
+type jsonBudget struct {
+	Month      string               `json:"month"`
+	Amount     string               `json:"amount"`
+	Categories []jsonCategoryBudget `json:"categories"`
+}
+
+type jsonCategoryBudget struct {
+	Category string `json:"category"`
+	Amount   string `json:"amount"`
+}
+
+// writeJSON writes data as a single JSON object. Amounts are strings with
+// two decimals and categories are named by their path, like Food:Bakery.
+func writeJSON(w io.Writer, data domain.ExportData, currency string) error {
+	n := newNames(data)
+
+	export := jsonExport{
+		Version:    Version,
+		From:       data.From,
+		To:         data.To,
+		Currency:   currency,
+		Accounts:   []jsonAccount{},
+		Categories: []jsonCategory{},
+		Expenses:   []jsonExpense{},
+		Budgets:    []jsonBudget{},
+	}
+
+	for _, account := range data.Accounts {
+		export.Accounts = append(export.Accounts, jsonAccount{
+			Id:             account.Id,
+			Name:           account.Name,
+			Type:           string(account.Type),
+			OpeningBalance: account.OpeningBalance.String(),
+		})
+	}
+
+	for _, category := range data.Categories {
+		export.Categories = append(export.Categories, jsonCategory{
+			Id:       category.Id,
+			Name:     category.Name,
+			Path:     strings.Join(n.categoryPath(category.Id), ":"),
+			ParentId: category.ParentId,
+			Archived: category.Archived,
+		})
+	}
+
+	for _, expense := range data.Expenses {
+		exported := jsonExpense{
+			Id:          expense.Id,
+			Date:        expense.Date,
+			Name:        expense.Name,
+			Kind:        string(expense.Kind),
+			Amount:      expense.Amount.String(),
+			Category:    strings.Join(n.categoryPath(expense.CategoryId), ":"),
+			AccountId:   expense.AccountId,
+			Account:     n.account(expense.AccountId),
+			ToAccountId: expense.ToAccountId,
+			ToAccount:   n.account(expense.ToAccountId),
+			Payee:       expense.Payee,
+			Tags:        append([]string{}, expense.Tags...),
+			Splits:      []jsonSplit{},
+			ImportId:    expense.ImportId,
+		}
+
+		for _, split := range expense.Splits {
+			exported.Splits = append(exported.Splits, jsonSplit{
+				Category: strings.Join(n.categoryPath(split.CategoryId), ":"),
+				Amount:   split.Amount.String(),
+				Note:     split.Note,
+			})
+		}
+
+		export.Expenses = append(export.Expenses, exported)
+	}
+
+	for _, budget := range data.Budgets {
+		exported := jsonBudget{Month: budget.Month, Amount: budget.Budget.String(), Categories: []jsonCategoryBudget{}}
+		for _, category := range n.sortedBudgets(budget) {
+			exported.Categories = append(exported.Categories, jsonCategoryBudget{Category: category.Category, Amount: category.Amount.String()})
+		}
+		export.Budgets = append(export.Budgets, exported)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(export)
+}
@@ -0,0 +1,136 @@
+package exporter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// ledgerIndent starts the lines of a ledger entry below its first one.
+const ledgerIndent = "    "
+
+// ledgerPosting is a line of a ledger transaction moving amount to account.
+type ledgerPosting struct {
+	account string
+	amount  domain.Money
+	note    string
+}
+
+// writeLedger writes the budgets as periodic transactions, hledger style,
+// and the expenses as transactions between accounts under assets or
+// liabilities and categories under expenses or income.
+func writeLedger(w io.Writer, data domain.ExportData, currency string) error {
+	n := newNames(data)
+	writer := bufio.NewWriter(w)
+
+	fmt.Fprintf(writer, "; expenses from %s to %s\n", data.From, data.To)
+
+	for _, budget := range data.Budgets {
+		postings := []ledgerPosting{{account: "(expenses)", amount: budget.Budget}}
+		for _, category := range n.sortedBudgets(budget) {
+			postings = append(postings, ledgerPosting{
+				account: "(" + n.ledgerCategory("expenses", category.CategoryId) + ")",
+				amount:  category.Amount,
+			})
+		}
+
+		fmt.Fprintf(writer, "\n~ monthly in %s\n", budget.Month)
+		writeLedgerPostings(writer, postings, currency)
+	}
+
+	for _, expense := range data.Expenses {
+		fmt.Fprintf(writer, "\n%s %s\n", domain.RangeDate(expense.Date), ledgerText(expense.Name))
+		if expense.Payee != "" {
+			fmt.Fprintf(writer, "%s; payee: %s\n", ledgerIndent, ledgerText(expense.Payee))
+		}
+		for _, tag := range expense.Tags {
+			fmt.Fprintf(writer, "%s; tag: %s\n", ledgerIndent, ledgerText(tag))
+		}
+
+		account := n.ledgerAccount(expense.AccountId)
+		postings := []ledgerPosting{}
+
+		switch expense.Kind {
+		case domain.KindTransfer:
+			postings = append(postings,
+				ledgerPosting{account: n.ledgerAccount(expense.ToAccountId), amount: expense.Amount},
+				ledgerPosting{account: account, amount: -expense.Amount},
+			)
+		case domain.KindIncome:
+			postings = append(postings,
+				ledgerPosting{account: account, amount: expense.Amount},
+				ledgerPosting{account: n.ledgerCategory("income", expense.CategoryId), amount: -expense.Amount},
+			)
+		default:
+			for _, split := range expense.CategoryAmounts() {
+				postings = append(postings, ledgerPosting{
+					account: n.ledgerCategory("expenses", split.CategoryId),
+					amount:  split.Amount,
+					note:    split.Note,
+				})
+			}
+			postings = append(postings, ledgerPosting{account: account, amount: -expense.Amount})
+		}
+
+		writeLedgerPostings(writer, postings, currency)
+	}
+
+	return writer.Flush()
+}
+
+// writeLedgerPostings writes posting lines with their amounts aligned.
+func writeLedgerPostings(w io.Writer, postings []ledgerPosting, currency string) {
+	width := 0
+	for _, p := range postings {
+		if len(p.account) > width {
+			width = len(p.account)
+		}
+	}
+
+	for _, p := range postings {
+		amount := p.amount.String()
+		if currency != "" {
+			amount += " " + currency
+		}
+
+		line := fmt.Sprintf("%s%-*s  %12s", ledgerIndent, width, p.account, amount)
+		if p.note != "" {
+			line += "  ; " + ledgerText(p.note)
+		}
+		fmt.Fprintln(w, line)
+	}
+}
+
+// ledgerText returns text on a single line without the two spaces or
+// tabs ending account names.
+func ledgerText(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+// ledgerAccount returns the ledger account of an account: under
+// liabilities for credit cards, assets otherwise.
+func (n names) ledgerAccount(id int) string {
+	account, ok := n.accounts[id]
+	if !ok {
+		return "assets"
+	}
+
+	top := "assets"
+	if account.Type == domain.CreditCard {
+		top = "liabilities"
+	}
+
+	return top + ":" + ledgerText(account.Name)
+}
+
+// ledgerCategory returns the ledger account of a category under top.
+func (n names) ledgerCategory(top string, id int) string {
+	for _, name := range n.categoryPath(id) {
+		top += ":" + ledgerText(name)
+	}
+
+	return top
+}
@@ -0,0 +1,90 @@
+package importer
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Delimiters lists the delimiters DetectDelimiter picks from,
+// the preferred ones first.
+var Delimiters = []string{",", ";", "\t", "|"}
+
+// Record is a line of a CSV file and its line number, from 1.
+type Record struct {
+	Line   int
+	Fields []string
+}
+
+// DetectDelimiter returns the delimiter splitting the first lines of text
+// into the same number of fields most often, "," when none does.
+func DetectDelimiter(text string) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > 20 {
+		lines = lines[:20]
+	}
+	sample := strings.Join(lines, "\n")
+
+	best, bestScore := ",", 0
+	for _, delimiter := range Delimiters {
+		records, err := ReadCSV(sample, delimiter)
+		if err != nil || len(records) == 0 {
+			continue
+		}
+
+		counts := map[int]int{}
+		for _, record := range records {
+			counts[len(record.Fields)]++
+		}
+
+		score := 0
+		for fields, count := range counts {
+			if fields > 1 && count > score {
+				score = count
+			}
+		}
+
+		if score > bestScore {
+			best, bestScore = delimiter, score
+		}
+	}
+
+	return best
+}
+
+// ReadCSV splits text into records by delimiter, leaving out empty lines.
+// Quotes are lenient as bank statements often misuse them.
+func ReadCSV(text, delimiter string) ([]Record, error) {
+	comma, size := utf8.DecodeRuneInString(delimiter)
+	if size == 0 || size != len(delimiter) {
+		return nil, errors.New("Delimiter should be a single character.")
+	}
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = comma != '\t'
+
+	records := []Record{}
+	for {
+		fields, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Could not read CSV: %w", err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		records = append(records, Record{Line: line, Fields: fields})
+	}
+
+	return records, nil
+}
@@ -0,0 +1,49 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDetectDelimiter(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"comma", "date,name,amount\n2023-02-01,bakery,-3.50\n", ","},
+		{"semicolon", "date;name;amount\n01/02/2023;bakery;-3,50\n01/02/2023;rent;-900,00\n", ";"},
+		{"tab", "date\tname\tamount\n2023-02-01\tbakery, town\t-3.50\n", "\t"},
+		{"pipe", "date|name|amount\n2023-02-01|bakery|-3.50\n", "|"},
+		{"quoted commas", "date;name;amount\n2023-02-01;\"bakery, town\";\"-3,50\"\n", ";"},
+		{"single column", "bakery\nrent\n", ","},
+		{"empty", "", ","},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DetectDelimiter(test.text); got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestReadCSV(t *testing.T) {
+	records, err := ReadCSV("date;name\n\n2023-02-01; \"bakery \"town\"\" \n", ";")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Record{
+		{Line: 1, Fields: []string{"date", "name"}},
+		{Line: 3, Fields: []string{"2023-02-01", `bakery "town"`}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("got %q, want %q", records, want)
+	}
+
+	if _, err := ReadCSV("a,b", ";;"); err == nil {
+		t.Error("read with a delimiter of two characters")
+	}
+}
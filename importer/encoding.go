@@ -0,0 +1,96 @@
+// Package importer reads the bank statements expenses are imported from.
+package importer
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Encodings statements can be written in.
+const (
+	UTF8        = "utf-8"
+	UTF16LE     = "utf-16le"
+	UTF16BE     = "utf-16be"
+	Windows1252 = "windows-1252"
+	Latin1      = "iso-8859-1"
+)
+
+// Encodings lists the encodings Decode reads, the detected ones first.
+var Encodings = []string{UTF8, UTF16LE, UTF16BE, Windows1252, Latin1}
+
+// windows1252 maps the bytes 0x80 to 0x9F of Windows-1252 to their runes,
+// the other bytes being the same as in Latin-1.
+var windows1252 = [32]rune{
+	'€', 0x81, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0x8D, 'Ž', 0x8F,
+	0x90, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0x9D, 'ž', 'Ÿ',
+}
+
+// Decode returns content as text and the encoding it was read with.
+// An empty encoding is detected: UTF-16 and UTF-8 from their byte order
+// mark, then UTF-8 when content is valid UTF-8, else Windows-1252.
+func Decode(content []byte, encoding string) (string, string, error) {
+	encoding = strings.ToLower(strings.TrimSpace(encoding))
+
+	if encoding == "" {
+		encoding = detectEncoding(content)
+	}
+
+	switch encoding {
+	case UTF8:
+		content = bytes.TrimPrefix(content, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(content) {
+			return "", encoding, errors.New("File is not valid UTF-8, pick another encoding.")
+		}
+		return string(content), encoding, nil
+	case UTF16LE, UTF16BE:
+		return decodeUTF16(content, encoding == UTF16BE), encoding, nil
+	case Windows1252, Latin1:
+		runes := make([]rune, 0, len(content))
+		for _, b := range content {
+			r := rune(b)
+			if encoding == Windows1252 && b >= 0x80 && b <= 0x9F {
+				r = windows1252[b-0x80]
+			}
+			runes = append(runes, r)
+		}
+		return string(runes), encoding, nil
+	}
+
+	return "", encoding, fmt.Errorf("Encoding should be one of %s.", strings.Join(Encodings, ", "))
+}
+
+// detectEncoding guesses the encoding of content.
+func detectEncoding(content []byte) string {
+	switch {
+	case bytes.HasPrefix(content, []byte{0xff, 0xfe}):
+		return UTF16LE
+	case bytes.HasPrefix(content, []byte{0xfe, 0xff}):
+		return UTF16BE
+	case utf8.Valid(content):
+		return UTF8
+	}
+
+	return Windows1252
+}
+
+// decodeUTF16 returns UTF-16 content as text without its byte order mark.
+func decodeUTF16(content []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(content)/2)
+	for i := 0; i+1 < len(content); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(content[i])<<8|uint16(content[i+1]))
+		} else {
+			units = append(units, uint16(content[i+1])<<8|uint16(content[i]))
+		}
+	}
+
+	if len(units) > 0 && units[0] == 0xfeff {
+		units = units[1:]
+	}
+
+	return string(utf16.Decode(units))
+}
@@ -0,0 +1,43 @@
+package importer
+
+import "testing"
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  []byte
+		encoding string
+		want     string
+		detected string
+		fails    bool
+	}{
+		{name: "utf-8", content: []byte("café"), want: "café", detected: UTF8},
+		{name: "utf-8 bom", content: []byte("\xef\xbb\xbfcafé"), want: "café", detected: UTF8},
+		{name: "utf-16le", content: []byte{0xff, 0xfe, 'c', 0, 0xe9, 0}, want: "cé", detected: UTF16LE},
+		{name: "utf-16be", content: []byte{0xfe, 0xff, 0, 'c', 0, 0xe9}, want: "cé", detected: UTF16BE},
+		{name: "windows-1252", content: []byte("caf\xe9 \x80"), want: "café €", detected: Windows1252},
+		{name: "latin-1", content: []byte("caf\xe9 \x80"), encoding: "ISO-8859-1", want: "café \u0080", detected: Latin1},
+		{name: "utf-16le without bom", content: []byte{'c', 0, 0xe9, 0}, encoding: UTF16LE, want: "cé", detected: UTF16LE},
+		{name: "invalid utf-8", content: []byte("caf\xe9"), encoding: UTF8, detected: UTF8, fails: true},
+		{name: "unknown", content: []byte("café"), encoding: "ebcdic", detected: "ebcdic", fails: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, encoding, err := Decode(test.content, test.encoding)
+			if test.fails {
+				if err == nil {
+					t.Errorf("got %q, want an error", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != test.want || encoding != test.detected {
+				t.Errorf("got %q in %s, want %q in %s", got, encoding, test.want, test.detected)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"errors"
+	"html"
+	"strings"
+)
+
+// ReadOFX returns the bank and credit card transactions of an OFX or QFX
+// statement, either SGML or XML. Their id is the account and FITID.
+func ReadOFX(text string) ([]Transaction, error) {
+	if !strings.Contains(strings.ToUpper(text), "<OFX>") {
+		return nil, errors.New("File is not an OFX statement.")
+	}
+
+	transactions := []Transaction{}
+	account, fitId, memo := "", "", ""
+	var current *Transaction
+
+	// line is the line of text[counted], counting as tags are read.
+	line, counted := 1, 0
+
+	for i := 0; i < len(text); {
+		start := strings.IndexByte(text[i:], '<')
+		if start == -1 {
+			break
+		}
+		start += i
+
+		line += strings.Count(text[counted:start], "\n")
+		counted = start
+
+		end := strings.IndexByte(text[start:], '>')
+		if end == -1 {
+			break
+		}
+		end += start
+
+		tag := strings.ToUpper(strings.TrimSpace(text[start+1 : end]))
+		i = end + 1
+
+		value := text[i:]
+		if next := strings.IndexByte(value, '<'); next != -1 {
+			value = value[:next]
+		}
+		value = html.UnescapeString(strings.TrimSpace(value))
+
+		switch tag {
+		case "STMTTRN":
+			current = &Transaction{Line: line}
+			fitId, memo = "", ""
+		case "/STMTTRN":
+			if current == nil {
+				continue
+			}
+			if current.Name == "" {
+				current.Name = memo
+			}
+			if fitId != "" {
+				current.Id = account + "/" + fitId
+			}
+			transactions = append(transactions, *current)
+			current = nil
+		case "ACCTID":
+			if current == nil {
+				account = value
+			}
+		}
+
+		if current == nil {
+			continue
+		}
+
+		switch tag {
+		case "DTPOSTED":
+			current.Date = value
+			if len(value) > 8 {
+				current.Date = value[:8]
+			}
+		case "TRNAMT":
+			current.Amount = value
+		case "FITID":
+			fitId = value
+		case "NAME":
+			current.Name = value
+		case "MEMO":
+			memo = value
+		}
+	}
+
+	return transactions, nil
+}
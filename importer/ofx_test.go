@@ -0,0 +1,110 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+const sgmlStatement = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM>
+<BANKID>12345
+<ACCTID>000111
+</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20230201120000[-5:EST]
+<TRNAMT>-3.50
+<FITID>A1
+<NAME>Bakery &amp; Co
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20230205
+<TRNAMT>1500.00
+<FITID>A2
+<MEMO>Salary
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const xmlStatement = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX>
+  <CREDITCARDMSGSRSV1><CCSTMTTRNRS><CCSTMTRS>
+    <CCACCTFROM><ACCTID>4111</ACCTID></CCACCTFROM>
+    <BANKTRANLIST>
+      <STMTTRN>
+        <DTPOSTED>20230203000000.000</DTPOSTED>
+        <TRNAMT>-42.00</TRNAMT>
+        <FITID>X9</FITID>
+        <NAME>Books</NAME>
+        <MEMO>paperbacks</MEMO>
+      </STMTTRN>
+      <STMTTRN>
+        <DTPOSTED>20230204</DTPOSTED>
+        <TRNAMT>-1.00</TRNAMT>
+        <MEMO>Fee</MEMO>
+      </STMTTRN>
+    </BANKTRANLIST>
+  </CCSTMTRS></CCSTMTTRNRS></CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+const qfxStatement = `OFXHEADER:100
+DATA:OFXSGML
+
+<OFX>
+<SIGNONMSGSRSV1><SONRS><INTU.BID>3000<INTU.USERID>me</SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<BANKACCTFROM><ACCTID>222</BANKACCTFROM>
+<BANKTRANLIST>
+<STMTTRN><DTPOSTED>20230210<TRNAMT>-9.99<FITID>Q1<NAME>Streaming</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+func TestReadOFX(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Transaction
+	}{
+		{"sgml", sgmlStatement, []Transaction{
+			{Line: 12, Date: "20230201", Name: "Bakery & Co", Amount: "-3.50", Id: "000111/A1"},
+			{Line: 19, Date: "20230205", Name: "Salary", Amount: "1500.00", Id: "000111/A2"},
+		}},
+		{"xml", xmlStatement, []Transaction{
+			{Line: 7, Date: "20230203", Name: "Books", Amount: "-42.00", Id: "4111/X9"},
+			{Line: 14, Date: "20230204", Name: "Fee", Amount: "-1.00"},
+		}},
+		{"qfx", qfxStatement, []Transaction{
+			{Line: 9, Date: "20230210", Name: "Streaming", Amount: "-9.99", Id: "222/Q1"},
+		}},
+		{"empty", "<OFX></OFX>", []Transaction{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ReadOFX(test.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, test.want) {
+				t.Errorf("got %+v, want %+v", got, test.want)
+			}
+		})
+	}
+
+	if _, err := ReadOFX("date,name,amount\n"); err == nil {
+		t.Error("read a CSV file as OFX")
+	}
+}
@@ -0,0 +1,82 @@
+package importer
+
+import (
+	"errors"
+	"strings"
+)
+
+// qifTypes lists the QIF sections holding transactions of an account.
+var qifTypes = []string{"bank", "cash", "ccard", "oth a", "oth l"}
+
+// ReadQIF returns the transactions of the bank, cash, credit card, asset
+// and liability sections of a QIF statement. Transfers between accounts
+// are left without a category and classes are dropped from categories.
+func ReadQIF(text string) ([]Transaction, error) {
+	if !strings.HasPrefix(strings.TrimSpace(text), "!") {
+		return nil, errors.New("File is not a QIF statement.")
+	}
+
+	transactions := []Transaction{}
+	inSection := false
+	memo := ""
+	var current *Transaction
+
+	for i, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "!") {
+			header := strings.ToLower(line)
+			if strings.HasPrefix(header, "!type:") {
+				inSection = false
+				for _, qifType := range qifTypes {
+					inSection = inSection || strings.TrimSpace(header[len("!type:"):]) == qifType
+				}
+			} else if !strings.HasPrefix(header, "!option") && !strings.HasPrefix(header, "!clear") {
+				inSection = false
+			}
+			current = nil
+			continue
+		}
+
+		if !inSection {
+			continue
+		}
+
+		if current == nil {
+			current = &Transaction{Line: i + 1}
+			memo = ""
+		}
+
+		value := strings.TrimSpace(line[1:])
+
+		switch line[0] {
+		case '^':
+			if current.Name == "" {
+				current.Name = memo
+			}
+			transactions = append(transactions, *current)
+			current = nil
+		case 'D':
+			current.Date = strings.ReplaceAll(strings.ReplaceAll(value, " ", ""), "'", "/")
+		case 'T':
+			current.Amount = value
+		case 'U':
+			if current.Amount == "" {
+				current.Amount = value
+			}
+		case 'P':
+			current.Name = value
+		case 'M':
+			memo = value
+		case 'L':
+			if !strings.HasPrefix(value, "[") {
+				current.Category, _, _ = strings.Cut(value, "/")
+			}
+		}
+	}
+
+	return transactions, nil
+}
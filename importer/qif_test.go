@@ -0,0 +1,50 @@
+package importer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReadQIF(t *testing.T) {
+	text := "!Type:Bank\r\n" +
+		"D1/15'23\r\n" +
+		"T-1,234.56\r\n" +
+		"PRent\r\n" +
+		"LHousing/Home\r\n" +
+		"^\r\n" +
+		"D01/ 5/2023\r\n" +
+		"U25.00\r\n" +
+		"MRefund\r\n" +
+		"L[Savings]\r\n" +
+		"^\r\n" +
+		"!Type:Invst\r\n" +
+		"D1/20'23\r\n" +
+		"T-100.00\r\n" +
+		"PShares\r\n" +
+		"^\r\n" +
+		"!Option:AutoSwitch\r\n" +
+		"!Type:CCard\r\n" +
+		"D12/31/22\r\n" +
+		"T-3.50\r\n" +
+		"U-9.00\r\n" +
+		"PBakery\r\n" +
+		"^\r\n"
+
+	got, err := ReadQIF(text)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Transaction{
+		{Line: 2, Date: "1/15/23", Name: "Rent", Amount: "-1,234.56", Category: "Housing"},
+		{Line: 7, Date: "01/5/2023", Name: "Refund", Amount: "25.00"},
+		{Line: 19, Date: "12/31/22", Name: "Bakery", Amount: "-3.50"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if _, err := ReadQIF("date,name,amount\n"); err == nil {
+		t.Error("read a CSV file as QIF")
+	}
+}
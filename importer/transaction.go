@@ -0,0 +1,12 @@
+package importer
+
+// Transaction is a transaction of a statement as written in it, along with
+// the line it starts at, from 1, and the id the bank gave it, if any.
+type Transaction struct {
+	Line     int
+	Date     string
+	Name     string
+	Amount   string
+	Category string
+	Id       string
+}
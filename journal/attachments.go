@@ -0,0 +1,74 @@
+package journal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/alx-b/expensetracker/atomicfile"
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// InsertAttachment stores the content of an attachment under its File name,
+// unless a file with the same content is already stored, and inserts its
+// metadata.
+func (j *Journal) InsertAttachment(attachment domain.Attachment, content []byte) error {
+	return j.change(func() error {
+		if err := j.writeAttachmentFile(attachment.File, content); err != nil {
+			return err
+		}
+		return j.Storage.InsertAttachment(attachment, nil)
+	})
+}
+
+// DeleteAttachment deletes an attachment by its Id and removes its file
+// when no other attachment refers to it.
+func (j *Journal) DeleteAttachment(id int) error {
+	return j.change(func() error {
+		return j.Storage.DeleteAttachment(id)
+	})
+}
+
+// AttachmentPath returns the location of a stored attachment file.
+func (j *Journal) AttachmentPath(file string) string {
+	return filepath.Join(j.attachmentsDir, filepath.Base(file))
+}
+
+// writeAttachmentFile writes content to the attachments directory under
+// file, through a temporary file so a partial write is never visible.
+func (j *Journal) writeAttachmentFile(file string, content []byte) error {
+	path := j.AttachmentPath(file)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	if err := os.MkdirAll(j.attachmentsDir, 0o755); err != nil {
+		return fmt.Errorf("Could not create attachments directory: %w", err)
+	}
+
+	return atomicfile.WriteBytes(path, content)
+}
+
+// collectAttachmentFiles removes the files of the given attachments
+// when no attachment refers to them anymore.
+func (j *Journal) collectAttachmentFiles(attachments []domain.Attachment) error {
+	used := map[string]bool{}
+	for _, attachment := range j.Snapshot().Attachments {
+		used[attachment.File] = true
+	}
+
+	for _, attachment := range attachments {
+		if used[attachment.File] {
+			continue
+		}
+
+		err := os.Remove(j.AttachmentPath(attachment.File))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("Could not remove attachment file: %w", err)
+		}
+	}
+
+	return nil
+}
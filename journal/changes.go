@@ -0,0 +1,142 @@
+package journal
+
+import "github.com/alx-b/expensetracker/domain"
+
+// The methods below change the storage in memory through change,
+// so every change is written to the journal.
+
+func (j *Journal) InsertExpense(expense domain.Expense) (int, error) {
+	id := 0
+	err := j.change(func() (err error) {
+		id, err = j.Storage.InsertExpense(expense)
+		return err
+	})
+	return id, err
+}
+
+func (j *Journal) InsertExpenses(expenses []domain.Expense) ([]int, error) {
+	ids := []int{}
+	err := j.change(func() (err error) {
+		ids, err = j.Storage.InsertExpenses(expenses)
+		return err
+	})
+	return ids, err
+}
+
+func (j *Journal) UpdateExpense(expense domain.Expense) error {
+	return j.change(func() error { return j.Storage.UpdateExpense(expense) })
+}
+
+func (j *Journal) InsertBudget(amount domain.Money, date string) error {
+	return j.change(func() error { return j.Storage.InsertBudget(amount, date) })
+}
+
+func (j *Journal) DeleteBudget(date string) error {
+	return j.change(func() error { return j.Storage.DeleteBudget(date) })
+}
+
+func (j *Journal) UpdateDefaultBudget(amount domain.Money) error {
+	return j.change(func() error { return j.Storage.UpdateDefaultBudget(amount) })
+}
+
+func (j *Journal) DeleteExpense(id int, deletedAt string) error {
+	return j.change(func() error { return j.Storage.DeleteExpense(id, deletedAt) })
+}
+
+func (j *Journal) RestoreExpense(id int) error {
+	return j.change(func() error { return j.Storage.RestoreExpense(id) })
+}
+
+func (j *Journal) PurgeExpense(id int) error {
+	return j.change(func() error { return j.Storage.PurgeExpense(id) })
+}
+
+func (j *Journal) DiscardExpenses(ids []int) error {
+	return j.change(func() error { return j.Storage.DiscardExpenses(ids) })
+}
+
+func (j *Journal) InsertAuditEntries(entries []domain.AuditEntry) error {
+	return j.change(func() error { return j.Storage.InsertAuditEntries(entries) })
+}
+
+func (j *Journal) InsertCategory(category domain.Category) (int, error) {
+	id := 0
+	err := j.change(func() (err error) {
+		id, err = j.Storage.InsertCategory(category)
+		return err
+	})
+	return id, err
+}
+
+func (j *Journal) UpdateCategory(category domain.Category) error {
+	return j.change(func() error { return j.Storage.UpdateCategory(category) })
+}
+
+func (j *Journal) DeleteCategory(id int) error {
+	return j.change(func() error { return j.Storage.DeleteCategory(id) })
+}
+
+func (j *Journal) InsertCategoryBudget(categoryId int, amount domain.Money, date string) error {
+	return j.change(func() error { return j.Storage.InsertCategoryBudget(categoryId, amount, date) })
+}
+
+func (j *Journal) DeleteCategoryBudget(categoryId int, date string) error {
+	return j.change(func() error { return j.Storage.DeleteCategoryBudget(categoryId, date) })
+}
+
+func (j *Journal) InsertRecurringRule(rule domain.RecurringRule) error {
+	return j.change(func() error { return j.Storage.InsertRecurringRule(rule) })
+}
+
+func (j *Journal) UpdateRecurringRule(rule domain.RecurringRule) error {
+	return j.change(func() error { return j.Storage.UpdateRecurringRule(rule) })
+}
+
+func (j *Journal) DeleteRecurringRule(id int) error {
+	return j.change(func() error { return j.Storage.DeleteRecurringRule(id) })
+}
+
+func (j *Journal) InsertRecurringOccurrences(ruleId int, expenses []domain.Expense, lastGenerated string) error {
+	return j.change(func() error { return j.Storage.InsertRecurringOccurrences(ruleId, expenses, lastGenerated) })
+}
+
+func (j *Journal) UpdateRuleExpensesAfter(ruleId int, date string, expense domain.Expense) error {
+	return j.change(func() error { return j.Storage.UpdateRuleExpensesAfter(ruleId, date, expense) })
+}
+
+func (j *Journal) InsertAccount(account domain.Account) error {
+	return j.change(func() error { return j.Storage.InsertAccount(account) })
+}
+
+func (j *Journal) UpdateAccount(account domain.Account) error {
+	return j.change(func() error { return j.Storage.UpdateAccount(account) })
+}
+
+func (j *Journal) DeleteAccount(id int) error {
+	return j.change(func() error { return j.Storage.DeleteAccount(id) })
+}
+
+func (j *Journal) InsertPayee(payee domain.Payee) (int, error) {
+	id := 0
+	err := j.change(func() (err error) {
+		id, err = j.Storage.InsertPayee(payee)
+		return err
+	})
+	return id, err
+}
+
+func (j *Journal) InsertCategorizationRule(rule domain.CategorizationRule) error {
+	return j.change(func() error { return j.Storage.InsertCategorizationRule(rule) })
+}
+
+func (j *Journal) UpdateCategorizationRule(rule domain.CategorizationRule) error {
+	return j.change(func() error { return j.Storage.UpdateCategorizationRule(rule) })
+}
+
+func (j *Journal) DeleteCategorizationRule(id int) error {
+	return j.change(func() error { return j.Storage.DeleteCategorizationRule(id) })
+}
+
+func (j *Journal) UpdateSetting(key, value string) error {
+	return j.change(func() error { return j.Storage.UpdateSetting(key, value) })
+}
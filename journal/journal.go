@@ -0,0 +1,359 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/alx-b/expensetracker/atomicfile"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// Journal keeps expenses, budgets, categories, accounts and payees in a
+// plain text accounting journal readable by hledger and ledger. It only
+// rewrites the entries it wrote, tagged with their id, and keeps every other
+// entry and comment as it is. What plain text accounting has no place for
+// (recurring and categorization rules, settings, the audit log and the
+// attachments) is kept in a state file next to it.
+//
+// Queries are answered from memory. Changes made to the files while they
+// are open are read back before the next change.
+type Journal struct {
+	*memory.Storage
+	mu             sync.Mutex
+	path           string
+	statePath      string
+	attachmentsDir string
+	entries        []entry
+	content        []byte
+	state          []byte
+}
+
+// stateSuffix and attachmentsDirSuffix are appended to the name of the
+// journal, without extension, to name its state file and the directory
+// of its attachment files.
+const (
+	stateSuffix          = "-state.json"
+	attachmentsDirSuffix = "-attachments"
+)
+
+// state is the content of the state file.
+type state struct {
+	RecurringRules      []domain.RecurringRule      `json:"recurring_rules"`
+	CategorizationRules []domain.CategorizationRule `json:"categorization_rules"`
+	Attachments         []domain.Attachment         `json:"attachments"`
+	AuditLog            []domain.AuditEntry         `json:"audit_log"`
+	Settings            map[string]string           `json:"settings"`
+	PurgedImportIds     []string                    `json:"purged_import_ids"`
+}
+
+// CreateJournal opens the journal at path, creating it and its directory
+// if missing, and returns pointer to Journal struct.
+func CreateJournal(path string) (*Journal, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("Could not create journal directory: %w", err)
+	}
+
+	name := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+
+	j := &Journal{
+		Storage:        memory.CreateStorage(),
+		path:           path,
+		statePath:      filepath.Join(dir, name+stateSuffix),
+		attachmentsDir: filepath.Join(dir, name+attachmentsDirSuffix),
+	}
+
+	if err := j.load(); err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		if err := j.save(); err != nil {
+			return nil, err
+		}
+	}
+
+	return j, nil
+}
+
+// Close does nothing as every change is written right away.
+func (j *Journal) Close() error {
+	return nil
+}
+
+// readFile returns the content of a file, nil if it does not exist.
+func readFile(path string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Could not read file: %w", err)
+	}
+	return content, nil
+}
+
+// load reads the journal and its state file.
+func (j *Journal) load() error {
+	content, err := readFile(j.path)
+	if err != nil {
+		return err
+	}
+
+	stateContent, err := readFile(j.statePath)
+	if err != nil {
+		return err
+	}
+
+	s := state{}
+	if len(stateContent) > 0 {
+		if err := json.Unmarshal(stateContent, &s); err != nil {
+			return fmt.Errorf("Could not read journal state: %w", err)
+		}
+	}
+
+	snapshot, entries, err := parseJournal(string(content), memory.Snapshot{
+		RecurringRules:      s.RecurringRules,
+		CategorizationRules: s.CategorizationRules,
+		Attachments:         s.Attachments,
+		AuditLog:            s.AuditLog,
+		Settings:            s.Settings,
+		PurgedImportIds:     s.PurgedImportIds,
+	})
+	if err != nil {
+		return fmt.Errorf("Could not load journal %s: %w", j.path, err)
+	}
+
+	j.Storage.Restore(snapshot)
+	j.entries = entries
+	j.content = content
+	j.state = stateContent
+
+	return nil
+}
+
+// reloadIfChanged reads the files again when they were changed
+// by another program since they were last read or written.
+func (j *Journal) reloadIfChanged() error {
+	content, err := readFile(j.path)
+	if err != nil {
+		return err
+	}
+
+	stateContent, err := readFile(j.statePath)
+	if err != nil {
+		return err
+	}
+
+	if bytes.Equal(content, j.content) && bytes.Equal(stateContent, j.state) {
+		return nil
+	}
+
+	return j.load()
+}
+
+// save writes the journal and its state file from memory.
+func (j *Journal) save() error {
+	snapshot := j.Snapshot()
+
+	entries := merge(j.entries, render(snapshot))
+
+	lines := []string{}
+	for _, e := range entries {
+		lines = append(lines, e.lines...)
+	}
+	content := []byte(strings.Join(lines, "\n") + "\n")
+
+	stateContent, err := json.MarshalIndent(state{
+		RecurringRules:      snapshot.RecurringRules,
+		CategorizationRules: snapshot.CategorizationRules,
+		Attachments:         snapshot.Attachments,
+		AuditLog:            snapshot.AuditLog,
+		Settings:            snapshot.Settings,
+		PurgedImportIds:     snapshot.PurgedImportIds,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("Could not encode journal state: %w", err)
+	}
+
+	if err := atomicfile.WriteBytes(j.path, content); err != nil {
+		return err
+	}
+
+	if err := atomicfile.WriteBytes(j.statePath, stateContent); err != nil {
+		return err
+	}
+
+	j.entries = entries
+	j.content = content
+	j.state = stateContent
+
+	return nil
+}
+
+// group returns the kind of record an entry key names.
+func group(key string) string {
+	kind, _, _ := strings.Cut(key, ":")
+	return kind
+}
+
+// merge returns the entries of a journal once its records are replaced by
+// their current entries. Entries of records that no longer exist are
+// dropped along with the empty line after them, and entries of new records
+// are placed next to the records around them, see insertPosition. Empty
+// lines ending the journal are dropped.
+func merge(previous, current []entry) []entry {
+	rendered := map[string]entry{}
+	for _, e := range current {
+		rendered[e.key] = e
+	}
+
+	merged := []entry{}
+	index := map[string]int{}
+	first := map[string]int{}
+	after := map[string]int{}
+	dropped := false
+
+	for _, e := range previous {
+		if e.key == "" {
+			if e.blank() && dropped && (len(merged) == 0 || merged[len(merged)-1].blank()) {
+				continue
+			}
+			dropped = false
+			merged = append(merged, e)
+			continue
+		}
+
+		_, done := index[e.key]
+		update, ok := rendered[e.key]
+		if !ok || done {
+			dropped = true
+			continue
+		}
+
+		dropped = false
+		index[e.key] = len(merged)
+		if _, ok := first[group(e.key)]; !ok {
+			first[group(e.key)] = len(merged)
+		}
+		merged = append(merged, update)
+		after[group(e.key)] = len(merged)
+	}
+
+	inserts := map[int][]entry{}
+	for k, e := range current {
+		if _, ok := index[e.key]; ok {
+			continue
+		}
+		position := insertPosition(current, k, index, first, after, len(merged))
+		index[e.key] = position - 1
+		inserts[position] = append(inserts[position], e)
+	}
+
+	entries := []entry{}
+	separate := func(e entry) {
+		if len(entries) > 0 && !entries[len(entries)-1].blank() && !e.blank() {
+			entries = append(entries, entry{lines: []string{""}})
+		}
+	}
+
+	for i := 0; i <= len(merged); i++ {
+		inserted := len(inserts[i]) > 0
+		for _, e := range inserts[i] {
+			separate(e)
+			entries = append(entries, e)
+		}
+
+		if i == len(merged) {
+			break
+		}
+		if inserted {
+			separate(merged[i])
+		}
+		entries = append(entries, merged[i])
+	}
+
+	for len(entries) > 0 && entries[len(entries)-1].blank() {
+		entries = entries[:len(entries)-1]
+	}
+
+	return entries
+}
+
+// groups lists the kinds of records in the order they are rendered.
+var groups = []string{"account", "category", "payee", "budget", "expense"}
+
+// insertPosition returns the index of the merged entries a new entry,
+// current[k], goes before: right after the entry of the previous record of
+// its kind, else before the entry of the next one, else after the last entry
+// of the closest kind rendered before it, else before the first entry of
+// the closest kind rendered after it, else at the end.
+func insertPosition(current []entry, k int, index, first, after map[string]int, end int) int {
+	kind := group(current[k].key)
+
+	if k > 0 && group(current[k-1].key) == kind {
+		return index[current[k-1].key] + 1
+	}
+
+	for _, e := range current[k+1:] {
+		if group(e.key) != kind {
+			break
+		}
+		if i, ok := index[e.key]; ok {
+			return i
+		}
+	}
+
+	position, before := -1, true
+	for _, g := range groups {
+		switch {
+		case g == kind:
+			before = false
+		case before:
+			if i, ok := after[g]; ok {
+				position = i
+			}
+		case position == -1:
+			if i, ok := first[g]; ok {
+				return i
+			}
+		}
+	}
+	if position == -1 {
+		return end
+	}
+	return position
+}
+
+// change applies a change to memory and writes it, undoing it when it
+// could not be written. Attachment files no attachment refers to anymore
+// are then removed.
+func (j *Journal) change(apply func() error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.reloadIfChanged(); err != nil {
+		return err
+	}
+
+	before := j.Snapshot()
+
+	if err := apply(); err != nil {
+		return err
+	}
+
+	if err := j.save(); err != nil {
+		j.Storage.Restore(before)
+		return err
+	}
+
+	return j.collectAttachmentFiles(before.Attachments)
+}
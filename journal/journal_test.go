@@ -0,0 +1,98 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+	"github.com/alx-b/expensetracker/storagetest"
+)
+
+// withoutSplitIds returns a snapshot with its split ids cleared,
+// as split lines get new ids when a journal is read.
+func withoutSplitIds(snapshot memory.Snapshot) memory.Snapshot {
+	for i, expense := range snapshot.Expenses {
+		for k := range expense.Splits {
+			snapshot.Expenses[i].Splits[k].Id = 0
+		}
+	}
+	return snapshot
+}
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.Storage {
+		path := filepath.Join(t.TempDir(), "expenses.journal")
+		j, err := CreateJournal(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		t.Cleanup(func() {
+			reopened, err := CreateJournal(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, got := withoutSplitIds(j.Snapshot()), withoutSplitIds(reopened.Snapshot())
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("reopened journal holds %+v, want %+v", got, want)
+			}
+		})
+
+		return j
+	})
+}
+
+func TestKeepsOtherEntries(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "expenses.journal")
+	content := "; written by hand\n\n" +
+		"2023-01-02 Salary\n    assets:Bank  100\n    income:Work\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err := CreateJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := j.InsertExpense(domain.Expense{Name: "Lunch", Amount: 1250, Date: "2023-01-03", Kind: domain.KindExpense, AccountId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	written, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(written), content) {
+		t.Errorf("journal starts with %q, want %q", written, content)
+	}
+	if !strings.Contains(string(written), "2023-01-03 Lunch") {
+		t.Errorf("journal %q is missing the new expense", written)
+	}
+
+	if err := j.DeleteExpense(id, "2023-01-04 10:00:00"); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.PurgeExpense(id); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.DeleteAccount(1); err != nil {
+		t.Fatal(err)
+	}
+
+	written, err = os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(written), content) {
+		t.Errorf("journal starts with %q, want %q", written, content)
+	}
+	if strings.Contains(string(written), "Lunch") || strings.Contains(string(written), "assets:Main") {
+		t.Errorf("journal %q still holds deleted entries", written)
+	}
+}
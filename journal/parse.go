@@ -0,0 +1,631 @@
+package journal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// entry is a top-level entry of a journal with its indented lines.
+// Key names the record it holds when it was written by this program
+// (like "expense:12" or "budget:2023-02"), empty for any other entry,
+// which is kept as it is.
+type entry struct {
+	key   string
+	lines []string
+	line  int
+}
+
+// blank reports whether the entry is an empty line.
+func (e entry) blank() bool {
+	return len(e.lines) == 1 && strings.TrimSpace(e.lines[0]) == ""
+}
+
+// splitEntries splits the content of a journal into its top-level entries:
+// a line starting at the first column with the indented lines following
+// it, a comment block, or an empty line.
+func splitEntries(content string) []entry {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	content = strings.TrimSuffix(content, "\n")
+	if content == "" {
+		return []entry{}
+	}
+
+	entries := []entry{}
+	block := ""
+
+	for i, line := range strings.Split(content, "\n") {
+		last := len(entries) - 1
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case block != "":
+			entries[last].lines = append(entries[last].lines, line)
+			if trimmed == "end "+block {
+				block = ""
+			}
+		case trimmed == "":
+			entries = append(entries, entry{lines: []string{line}, line: i + 1})
+		case (line[0] == ' ' || line[0] == '\t') && last >= 0 && !entries[last].blank():
+			entries[last].lines = append(entries[last].lines, line)
+		default:
+			if trimmed == "comment" || trimmed == "test" {
+				block = trimmed
+			}
+			entries = append(entries, entry{lines: []string{line}, line: i + 1})
+		}
+	}
+
+	return entries
+}
+
+// splitComment splits a line at its first ';' into its text and comment.
+func splitComment(line string) (string, string) {
+	text, comment, _ := strings.Cut(line, ";")
+	return strings.TrimSpace(text), strings.TrimSpace(comment)
+}
+
+// parseTags returns the tags of a comment: "name: value" pairs
+// separated by commas, the name being the word before the colon.
+func parseTags(comment string, tags map[string][]string) {
+	for _, part := range strings.Split(comment, ",") {
+		before, value, ok := strings.Cut(part, ":")
+		fields := strings.Fields(before)
+		if !ok || len(fields) == 0 {
+			continue
+		}
+		name := fields[len(fields)-1]
+		tags[name] = append(tags[name], unescape(strings.TrimSpace(value)))
+	}
+}
+
+// tag returns the first value of a tag, empty if missing.
+func tag(tags map[string][]string, name string) string {
+	if values := tags[name]; len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// posting is an account line of a transaction. Without amount,
+// it balances the others.
+type posting struct {
+	account   string
+	amount    domain.Money
+	hasAmount bool
+	note      string
+	line      int
+}
+
+// transaction is a parsed transaction, periodic transaction or directive:
+// its first line without comment, the tags of its comments and its postings.
+type transaction struct {
+	head     string
+	tags     map[string][]string
+	postings []posting
+	line     int
+}
+
+// parseTransaction parses the lines of an entry. Amounts are only parsed
+// when amounts is true, so directives and unrelated entries never fail.
+func parseTransaction(lines []string, line int, amounts bool) (transaction, error) {
+	head, comment := splitComment(lines[0])
+	t := transaction{head: head, tags: map[string][]string{}, line: line}
+	parseTags(comment, t.tags)
+
+	for i, text := range lines[1:] {
+		text, comment := splitComment(text)
+		if text == "" {
+			parseTags(comment, t.tags)
+			continue
+		}
+
+		p := posting{note: unescape(comment), line: line + i + 1}
+
+		text = strings.TrimLeft(text, "*! ")
+		account, amount, _ := strings.Cut(strings.ReplaceAll(text, "\t", "  "), "  ")
+		account = strings.TrimSpace(account)
+		if len(account) > 1 && strings.ContainsRune("([", rune(account[0])) {
+			account = account[1 : len(account)-1]
+		}
+		p.account = account
+
+		if amount = strings.TrimSpace(amount); amount != "" && amounts {
+			money, err := parseAmount(amount)
+			if err != nil {
+				return t, fmt.Errorf("Could not parse line %d: %w", p.line, err)
+			}
+			p.amount = money
+			p.hasAmount = true
+		}
+
+		t.postings = append(t.postings, p)
+	}
+
+	if !amounts {
+		return t, nil
+	}
+
+	missing := -1
+	total := domain.Money(0)
+	for i, p := range t.postings {
+		if p.hasAmount {
+			total += p.amount
+			continue
+		}
+		if missing != -1 {
+			return t, fmt.Errorf("Could not parse line %d: only one posting may leave out its amount.", p.line)
+		}
+		missing = i
+	}
+	if missing != -1 {
+		t.postings[missing].amount = -total
+		t.postings[missing].hasAmount = true
+	}
+
+	return t, nil
+}
+
+// amountPattern matches the number of an amount, with or without commodity.
+var amountPattern = regexp.MustCompile(`[-+]?[0-9][0-9.,]*`)
+
+// parseAmount parses an amount like "12.50", "-1,200.00", "$12.50"
+// or "12.50 EUR", ignoring its commodity.
+func parseAmount(amount string) (domain.Money, error) {
+	number := amountPattern.FindString(amount)
+	if number == "" {
+		return 0, fmt.Errorf("Could not parse amount %q.", amount)
+	}
+
+	if strings.Contains(number, ".") {
+		number = strings.ReplaceAll(number, ",", "")
+	}
+	if strings.Contains(amount, "-") && !strings.HasPrefix(number, "-") {
+		number = "-" + number
+	}
+
+	return domain.ParseMoney(number)
+}
+
+// datePattern matches the date starting a transaction.
+var datePattern = regexp.MustCompile(`^([0-9]{4})[-/.]([0-9]{1,2})[-/.]([0-9]{1,2})`)
+
+// parseDate returns the date (YYYY-MM-DD) starting a transaction
+// and the rest of its line.
+func parseDate(head string) (string, string, bool) {
+	match := datePattern.FindStringSubmatch(head)
+	if match == nil {
+		return "", "", false
+	}
+
+	month, _ := strconv.Atoi(match[2])
+	day, _ := strconv.Atoi(match[3])
+	rest := head[len(match[0]):]
+
+	if strings.HasPrefix(rest, "=") {
+		_, rest, _ = strings.Cut(rest, " ")
+	}
+
+	return fmt.Sprintf("%s-%02d-%02d", match[1], month, day), rest, true
+}
+
+// parseDescription returns the description of a transaction after its date,
+// leaving out its status mark and code.
+func parseDescription(rest string) string {
+	rest = strings.TrimSpace(rest)
+	rest = strings.TrimSpace(strings.TrimLeft(rest, "*!"))
+
+	if strings.HasPrefix(rest, "(") {
+		if _, after, ok := strings.Cut(rest, ")"); ok {
+			rest = strings.TrimSpace(after)
+		}
+	}
+
+	return unescape(rest)
+}
+
+// root returns the top-level account of an account name, lowercased.
+func root(account string) string {
+	top, _, _ := strings.Cut(account, ":")
+	return strings.ToLower(top)
+}
+
+// subaccount returns the unescaped account name below its top-level account.
+func subaccount(account string) string {
+	_, name, _ := strings.Cut(account, ":")
+	return unescape(name)
+}
+
+// isAssetRoot reports whether an account holds money:
+// an asset or a liability like a credit card.
+func isAssetRoot(account string) bool {
+	return root(account) == "assets" || root(account) == "liabilities"
+}
+
+// isIncomeRoot reports whether an account is a source of income.
+func isIncomeRoot(account string) bool {
+	switch root(account) {
+	case "income", "revenue", "revenues":
+		return true
+	}
+	return false
+}
+
+// loader builds a snapshot from the entries of a journal, creating the
+// categories, accounts and payees entries refer to without declaring them.
+type loader struct {
+	snapshot   memory.Snapshot
+	categories map[string]int
+	accounts   map[string]int
+	payees     map[string]int
+	lastIds    map[string]int
+}
+
+// parseJournal returns the content of a journal and its entries,
+// keyed when written by this program. The sidecar state holds what
+// plain text accounting has no place for.
+func parseJournal(content string, state memory.Snapshot) (memory.Snapshot, []entry, error) {
+	entries := splitEntries(content)
+
+	l := loader{
+		snapshot: memory.Snapshot{
+			Expenses:            []domain.Expense{},
+			Budgets:             map[string]domain.Money{"default": 0},
+			Categories:          []domain.Category{},
+			CategoryBudgets:     map[string]map[int]domain.Money{},
+			RecurringRules:      state.RecurringRules,
+			Accounts:            []domain.Account{},
+			Attachments:         state.Attachments,
+			Payees:              []domain.Payee{},
+			CategorizationRules: state.CategorizationRules,
+			AuditLog:            state.AuditLog,
+			Settings:            state.Settings,
+			PurgedImportIds:     state.PurgedImportIds,
+		},
+		categories: map[string]int{},
+		accounts:   map[string]int{},
+		payees:     map[string]int{},
+		lastIds:    map[string]int{},
+	}
+
+	// Directives first, as entries may use them before they are declared.
+	for i, e := range entries {
+		key, err := l.parseDirective(e)
+		if err != nil {
+			return l.snapshot, nil, err
+		}
+		entries[i].key = key
+	}
+
+	seen := map[string]bool{}
+
+	for i, e := range entries {
+		if entries[i].key != "" {
+			continue
+		}
+
+		key, err := l.parseRecord(e)
+		if err != nil {
+			return l.snapshot, nil, err
+		}
+
+		if key != "" && seen[key] {
+			return l.snapshot, nil, fmt.Errorf("Could not load line %d: %s is written twice.", e.line, strings.Replace(key, ":", " ", 1))
+		}
+		seen[key] = true
+		entries[i].key = key
+	}
+
+	// A journal this program never wrote to starts like a new database.
+	if !owned(entries) {
+		l.snapshot.Accounts = []domain.Account{{Id: 1, Name: "Main", Type: domain.Checking}}
+	}
+
+	return l.snapshot, entries, nil
+}
+
+// owned reports whether any entry was written by this program.
+func owned(entries []entry) bool {
+	for _, e := range entries {
+		if e.key != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseDirective reads a category, account or payee declared by this
+// program and returns its key, empty for any other entry.
+func (l *loader) parseDirective(e entry) (string, error) {
+	head := strings.TrimSpace(e.lines[0])
+	if !strings.HasPrefix(head, "account ") && !strings.HasPrefix(head, "payee ") {
+		return "", nil
+	}
+
+	t, err := parseTransaction(e.lines, e.line, false)
+	if err != nil || tag(t.tags, "id") == "" {
+		return "", err
+	}
+
+	id, err := strconv.Atoi(tag(t.tags, "id"))
+	if err != nil || id <= 0 {
+		return "", fmt.Errorf("Could not load line %d: invalid id %q.", e.line, tag(t.tags, "id"))
+	}
+
+	directive, name, _ := strings.Cut(t.head, " ")
+	name = strings.TrimSpace(name)
+
+	switch {
+	case directive == "payee":
+		payee := domain.Payee{Id: id, Name: unescape(name)}
+		l.snapshot.Payees = append(l.snapshot.Payees, payee)
+		l.payees[strings.ToLower(payee.Name)] = id
+		l.keepId("payees", id)
+		return fmt.Sprintf("payee:%d", id), nil
+
+	case root(name) == "expenses":
+		category := domain.Category{
+			Id:       id,
+			Name:     subaccount(name),
+			Color:    tag(t.tags, "color"),
+			Icon:     tag(t.tags, "icon"),
+			Archived: len(t.tags["archived"]) > 0,
+		}
+		if parent := tag(t.tags, "parent"); parent != "" {
+			category.ParentId, err = strconv.Atoi(parent)
+			if err != nil {
+				return "", fmt.Errorf("Could not load line %d: invalid parent %q.", e.line, parent)
+			}
+		}
+		l.snapshot.Categories = append(l.snapshot.Categories, category)
+		l.categories[strings.ToLower(category.Name)] = id
+		l.keepId("categories", id)
+		return fmt.Sprintf("category:%d", id), nil
+
+	case isAssetRoot(name):
+		account := domain.Account{
+			Id:   id,
+			Name: subaccount(name),
+			Type: domain.AccountType(tag(t.tags, "kind")),
+		}
+		if account.Type == "" {
+			account.Type = defaultAccountType(name)
+		}
+		if opening := tag(t.tags, "opening"); opening != "" {
+			account.OpeningBalance, err = domain.ParseMoney(opening)
+			if err != nil {
+				return "", fmt.Errorf("Could not load line %d: %w", e.line, err)
+			}
+		}
+		l.snapshot.Accounts = append(l.snapshot.Accounts, account)
+		l.accounts[strings.ToLower(account.Name)] = id
+		l.keepId("accounts", id)
+		return fmt.Sprintf("account:%d", id), nil
+	}
+
+	return "", nil
+}
+
+// defaultAccountType returns the type of an account declared
+// without one: a credit card under liabilities, checking otherwise.
+func defaultAccountType(account string) domain.AccountType {
+	if root(account) == "liabilities" {
+		return domain.CreditCard
+	}
+	return domain.Checking
+}
+
+// parseRecord reads a budget or an expense written by this program
+// and returns its key, empty for any other entry.
+func (l *loader) parseRecord(e entry) (string, error) {
+	head := strings.TrimSpace(e.lines[0])
+
+	switch {
+	case strings.HasPrefix(head, "~"):
+		t, err := parseTransaction(e.lines, e.line, false)
+		if err != nil || tag(t.tags, "budget") == "" {
+			return "", err
+		}
+		return l.parseBudget(e)
+
+	case head == "comment" && len(e.lines) > 3:
+		deletedAt, ok := strings.CutPrefix(strings.TrimSpace(e.lines[1]), trashMark)
+		if !ok {
+			return "", nil
+		}
+		inner := entry{lines: e.lines[2 : len(e.lines)-1], line: e.line + 2}
+		return l.parseExpense(inner, strings.TrimSpace(deletedAt))
+
+	case datePattern.MatchString(head):
+		return l.parseExpense(e, "")
+	}
+
+	return "", nil
+}
+
+// parseBudget reads the budget of a month, or the default one, and the
+// budgets of its categories.
+func (l *loader) parseBudget(e entry) (string, error) {
+	t, err := parseTransaction(e.lines, e.line, true)
+	if err != nil {
+		return "", err
+	}
+
+	date := tag(t.tags, "budget")
+
+	for _, p := range t.postings {
+		switch {
+		case strings.ToLower(p.account) == "expenses":
+			l.snapshot.Budgets[date] = p.amount
+		case root(p.account) == "expenses":
+			if l.snapshot.CategoryBudgets[date] == nil {
+				l.snapshot.CategoryBudgets[date] = map[int]domain.Money{}
+			}
+			l.snapshot.CategoryBudgets[date][l.categoryId(subaccount(p.account))] = p.amount
+		}
+	}
+
+	return "budget:" + date, nil
+}
+
+// parseExpense reads an expense, income or transfer, in the trash
+// since deletedAt unless empty.
+func (l *loader) parseExpense(e entry, deletedAt string) (string, error) {
+	t, err := parseTransaction(e.lines, e.line, true)
+	if err != nil || tag(t.tags, "id") == "" {
+		return "", err
+	}
+
+	date, rest, ok := parseDate(t.head)
+	if !ok {
+		return "", nil
+	}
+
+	expense := domain.Expense{
+		Name:      parseDescription(rest),
+		Date:      date,
+		Tags:      []string{},
+		DeletedAt: deletedAt,
+	}
+
+	ids := []*int{&expense.Id, &expense.RuleId}
+	for i, name := range []string{"id", "rule"} {
+		if value := tag(t.tags, name); value != "" {
+			if *ids[i], err = strconv.Atoi(value); err != nil {
+				return "", fmt.Errorf("Could not load line %d: invalid %s %q.", e.line, name, value)
+			}
+		}
+	}
+
+	expense.ImportId = tag(t.tags, "import")
+
+	if month := tag(t.tags, "month"); month != "" && domain.RangeDate(month) == date {
+		expense.Date = month
+	}
+
+	if payee := tag(t.tags, "payee"); payee != "" {
+		expense.PayeeId = l.payeeId(payee)
+	}
+
+	expense.Tags = append(expense.Tags, t.tags["tag"]...)
+
+	money := []posting{}
+	categories := []posting{}
+	for _, p := range t.postings {
+		if isAssetRoot(p.account) {
+			money = append(money, p)
+		} else {
+			categories = append(categories, p)
+		}
+	}
+
+	switch {
+	case len(categories) == 0:
+		expense.Kind = domain.KindTransfer
+		if len(money) != 2 {
+			return "", fmt.Errorf("Could not load line %d: a transfer needs two postings.", e.line)
+		}
+		expense.Amount = money[0].amount
+		expense.ToAccountId = l.accountId(money[0].account)
+		expense.AccountId = l.accountId(money[1].account)
+
+	case isIncomeRoot(categories[0].account):
+		expense.Kind = domain.KindIncome
+		for _, p := range categories {
+			expense.Amount -= p.amount
+		}
+		expense.CategoryId = l.categoryId(subaccount(categories[0].account))
+
+	default:
+		expense.Kind = domain.KindExpense
+		for _, p := range categories {
+			expense.Amount += p.amount
+		}
+		if len(categories) == 1 && len(t.tags["split"]) == 0 {
+			expense.CategoryId = l.categoryId(subaccount(categories[0].account))
+			break
+		}
+		for _, p := range categories {
+			expense.Splits = append(expense.Splits, domain.Split{
+				CategoryId: l.categoryId(subaccount(p.account)),
+				Amount:     p.amount,
+				Note:       p.note,
+			})
+		}
+		if category := tag(t.tags, "category"); category != "" {
+			expense.CategoryId = l.categoryId(category)
+		}
+	}
+
+	if expense.Kind != domain.KindTransfer && len(money) > 0 {
+		expense.AccountId = l.accountId(money[0].account)
+	}
+
+	l.snapshot.Expenses = append(l.snapshot.Expenses, expense)
+
+	return fmt.Sprintf("expense:%d", expense.Id), nil
+}
+
+// keepId makes sure records created while loading get ids above id.
+func (l *loader) keepId(table string, id int) {
+	if id > l.lastIds[table] {
+		l.lastIds[table] = id
+	}
+}
+
+// categoryId returns the id of the category named name, creating it if
+// undeclared. The empty name is no category.
+func (l *loader) categoryId(name string) int {
+	if name == "" {
+		return 0
+	}
+
+	if id, ok := l.categories[strings.ToLower(name)]; ok {
+		return id
+	}
+
+	l.lastIds["categories"]++
+	id := l.lastIds["categories"]
+	l.categories[strings.ToLower(name)] = id
+	l.snapshot.Categories = append(l.snapshot.Categories, domain.Category{Id: id, Name: name})
+
+	return id
+}
+
+// accountId returns the id of the account of a posting, creating it if
+// undeclared. The top-level account alone is no account.
+func (l *loader) accountId(account string) int {
+	name := subaccount(account)
+	if name == "" {
+		return 0
+	}
+
+	if id, ok := l.accounts[strings.ToLower(name)]; ok {
+		return id
+	}
+
+	l.lastIds["accounts"]++
+	id := l.lastIds["accounts"]
+	l.accounts[strings.ToLower(name)] = id
+	l.snapshot.Accounts = append(l.snapshot.Accounts, domain.Account{Id: id, Name: name, Type: defaultAccountType(account)})
+
+	return id
+}
+
+// payeeId returns the id of the payee named name, creating it if undeclared.
+func (l *loader) payeeId(name string) int {
+	if id, ok := l.payees[strings.ToLower(name)]; ok {
+		return id
+	}
+
+	l.lastIds["payees"]++
+	id := l.lastIds["payees"]
+	l.payees[strings.ToLower(name)] = id
+	l.snapshot.Payees = append(l.snapshot.Payees, domain.Payee{Id: id, Name: name})
+
+	return id
+}
@@ -0,0 +1,292 @@
+package journal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/memory"
+)
+
+// trashMark starts the line of a comment block holding an expense in the
+// trash, followed by when it was deleted.
+const trashMark = "; trash:"
+
+// indent starts the lines of an entry below its first one.
+const indent = "    "
+
+var (
+	// valueEscaper escapes tag values, which end at a comma.
+	valueEscaper = strings.NewReplacer("%", "%25", ";", "%3B", ",", "%2C", "\n", "%0A")
+	// textEscaper escapes descriptions and notes, which end at a semicolon.
+	textEscaper = strings.NewReplacer("%", "%25", ";", "%3B", "\n", "%0A")
+	// accountEscaper escapes account names, which end at two spaces or a tab.
+	accountEscaper = strings.NewReplacer("%", "%25", ";", "%3B", "\n", "%0A", "\t", "%09", "  ", " %20")
+	// unescaper reverts every escaper.
+	unescaper = strings.NewReplacer("%25", "%", "%3B", ";", "%2C", ",", "%0A", "\n", "%09", "\t", "%20", " ", "%28", "(", "%2A", "*", "%21", "!")
+)
+
+// unescape reverts the escaping of a value written by this program.
+func unescape(value string) string {
+	return unescaper.Replace(value)
+}
+
+// escapeDescription escapes the description of a transaction, including
+// a first character that would be read as a status mark or a code.
+func escapeDescription(description string) string {
+	description = textEscaper.Replace(description)
+
+	for _, mark := range []string{"(", "*", "!"} {
+		if strings.HasPrefix(description, mark) {
+			return fmt.Sprintf("%%%02X", mark[0]) + description[1:]
+		}
+	}
+
+	return description
+}
+
+// renderer writes the records of a snapshot as journal entries.
+type renderer struct {
+	snapshot   memory.Snapshot
+	categories map[int]string
+	accounts   map[int]domain.Account
+	payees     map[int]string
+}
+
+// render returns the entries of every record of a snapshot, keyed,
+// accounts first, then categories, payees, budgets and expenses by date.
+func render(snapshot memory.Snapshot) []entry {
+	r := renderer{
+		snapshot:   snapshot,
+		categories: map[int]string{},
+		accounts:   map[int]domain.Account{},
+		payees:     map[int]string{},
+	}
+
+	for _, category := range snapshot.Categories {
+		r.categories[category.Id] = category.Name
+	}
+	for _, account := range snapshot.Accounts {
+		r.accounts[account.Id] = account
+	}
+	for _, payee := range snapshot.Payees {
+		r.payees[payee.Id] = payee.Name
+	}
+
+	entries := []entry{}
+
+	for _, account := range snapshot.Accounts {
+		lines := []string{"account " + r.accountName(account.Id), tagLine("id", fmt.Sprint(account.Id)), tagLine("kind", string(account.Type))}
+		if account.OpeningBalance != 0 {
+			lines = append(lines, tagLine("opening", account.OpeningBalance.String()))
+		}
+		entries = append(entries, entry{key: fmt.Sprintf("account:%d", account.Id), lines: lines})
+	}
+
+	for _, category := range snapshot.Categories {
+		lines := []string{"account " + r.categoryName("expenses", category.Id), tagLine("id", fmt.Sprint(category.Id))}
+		for _, field := range []struct{ name, value string }{
+			{"parent", fmt.Sprint(category.ParentId)},
+			{"color", category.Color},
+			{"icon", category.Icon},
+		} {
+			if field.value != "" && field.value != "0" {
+				lines = append(lines, tagLine(field.name, field.value))
+			}
+		}
+		if category.Archived {
+			lines = append(lines, tagLine("archived", "yes"))
+		}
+		entries = append(entries, entry{key: fmt.Sprintf("category:%d", category.Id), lines: lines})
+	}
+
+	for _, payee := range snapshot.Payees {
+		lines := []string{"payee " + accountEscaper.Replace(payee.Name), tagLine("id", fmt.Sprint(payee.Id))}
+		entries = append(entries, entry{key: fmt.Sprintf("payee:%d", payee.Id), lines: lines})
+	}
+
+	dates := []string{}
+	seen := map[string]bool{}
+	for date := range snapshot.Budgets {
+		dates, seen[date] = append(dates, date), true
+	}
+	for date := range snapshot.CategoryBudgets {
+		if !seen[date] {
+			dates = append(dates, date)
+		}
+	}
+	sort.Slice(dates, func(i, j int) bool {
+		if (dates[i] == "default") != (dates[j] == "default") {
+			return dates[i] == "default"
+		}
+		return dates[i] < dates[j]
+	})
+	for _, date := range dates {
+		entries = append(entries, entry{key: "budget:" + date, lines: r.budget(date)})
+	}
+
+	expenses := append([]domain.Expense{}, snapshot.Expenses...)
+	sort.SliceStable(expenses, func(i, j int) bool {
+		a, b := domain.RangeDate(expenses[i].Date), domain.RangeDate(expenses[j].Date)
+		if a != b {
+			return a < b
+		}
+		return expenses[i].Id < expenses[j].Id
+	})
+	for _, expense := range expenses {
+		entries = append(entries, entry{key: fmt.Sprintf("expense:%d", expense.Id), lines: r.expense(expense)})
+	}
+
+	return entries
+}
+
+// tagLine returns an indented comment line holding a tag.
+func tagLine(name, value string) string {
+	return indent + "; " + name + ": " + valueEscaper.Replace(value)
+}
+
+// accountName returns the journal account of an account:
+// under liabilities for credit cards, assets otherwise.
+func (r renderer) accountName(id int) string {
+	account, ok := r.accounts[id]
+	if !ok {
+		return "assets"
+	}
+
+	top := "assets"
+	if account.Type == domain.CreditCard {
+		top = "liabilities"
+	}
+
+	return top + ":" + accountEscaper.Replace(account.Name)
+}
+
+// categoryName returns the journal account of a category under top.
+func (r renderer) categoryName(top string, id int) string {
+	name, ok := r.categories[id]
+	if !ok || name == "" {
+		return top
+	}
+
+	return top + ":" + accountEscaper.Replace(name)
+}
+
+// postingLines returns posting lines with their amounts aligned.
+func postingLines(postings []posting) []string {
+	width := 0
+	for _, p := range postings {
+		if len(p.account) > width {
+			width = len(p.account)
+		}
+	}
+
+	lines := []string{}
+	for _, p := range postings {
+		line := fmt.Sprintf("%s%-*s  %12s", indent, width, p.account, p.amount)
+		if p.note != "" {
+			line += "  ; " + textEscaper.Replace(p.note)
+		}
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+// budget returns the periodic transaction holding the budget of a month
+// (YYYY-MM), or the default one, and the budgets of its categories as
+// unbalanced postings.
+func (r renderer) budget(date string) []string {
+	period := "~ monthly"
+	if date != "default" {
+		period += " in " + date
+	}
+
+	postings := []posting{}
+	if amount, ok := r.snapshot.Budgets[date]; ok {
+		postings = append(postings, posting{account: "(expenses)", amount: amount})
+	}
+
+	ids := []int{}
+	for id := range r.snapshot.CategoryBudgets[date] {
+		if r.categories[id] != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		postings = append(postings, posting{
+			account: "(" + r.categoryName("expenses", id) + ")",
+			amount:  r.snapshot.CategoryBudgets[date][id],
+		})
+	}
+
+	return append([]string{period, tagLine("budget", date)}, postingLines(postings)...)
+}
+
+// expense returns the transaction of an expense, income or transfer,
+// inside a comment block when it is in the trash.
+func (r renderer) expense(expense domain.Expense) []string {
+	lines := []string{
+		strings.TrimSpace(domain.RangeDate(expense.Date) + " " + escapeDescription(expense.Name)),
+		tagLine("id", fmt.Sprint(expense.Id)),
+	}
+
+	if expense.RuleId != 0 {
+		lines = append(lines, tagLine("rule", fmt.Sprint(expense.RuleId)))
+	}
+	if expense.ImportId != "" {
+		lines = append(lines, tagLine("import", expense.ImportId))
+	}
+	if expense.Date != domain.RangeDate(expense.Date) {
+		lines = append(lines, tagLine("month", expense.Date))
+	}
+	if name, ok := r.payees[expense.PayeeId]; ok {
+		lines = append(lines, tagLine("payee", name))
+	}
+	for _, tag := range expense.Tags {
+		lines = append(lines, tagLine("tag", tag))
+	}
+
+	account := r.accountName(expense.AccountId)
+	postings := []posting{}
+
+	switch expense.Kind {
+	case domain.KindTransfer:
+		postings = append(postings,
+			posting{account: r.accountName(expense.ToAccountId), amount: expense.Amount},
+			posting{account: account, amount: -expense.Amount},
+		)
+	case domain.KindIncome:
+		postings = append(postings,
+			posting{account: account, amount: expense.Amount},
+			posting{account: r.categoryName("income", expense.CategoryId), amount: -expense.Amount},
+		)
+	default:
+		if len(expense.Splits) > 0 {
+			lines = append(lines, tagLine("split", "yes"))
+			if expense.CategoryId != 0 {
+				lines = append(lines, tagLine("category", r.categories[expense.CategoryId]))
+			}
+			for _, split := range expense.Splits {
+				postings = append(postings, posting{
+					account: r.categoryName("expenses", split.CategoryId),
+					amount:  split.Amount,
+					note:    split.Note,
+				})
+			}
+		} else {
+			postings = append(postings, posting{account: r.categoryName("expenses", expense.CategoryId), amount: expense.Amount})
+		}
+		postings = append(postings, posting{account: account, amount: -expense.Amount})
+	}
+
+	lines = append(lines, postingLines(postings)...)
+
+	if expense.DeletedAt == "" {
+		return lines
+	}
+
+	lines = append([]string{"comment", trashMark + " " + expense.DeletedAt}, lines...)
+	return append(lines, "end comment")
+}
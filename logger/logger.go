@@ -1,19 +1,22 @@
 package logger
 
-// Package logger implements a barebone logger writing to logs.txt file.
+// Package logger implements a barebone logger writing to a log file.
 //
 // Import and use its functions with specific log level.
+// Call Open(path) first in your main, lines logged before going to stderr.
 // Defer CloseFile() in your main *(not required but better safe than sorry!)
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
+	"path/filepath"
 	"runtime"
 	"strings"
 )
 
-var loggers Loggers = createLoggers()
+var loggers Loggers = createLoggers(os.Stderr, nil)
 
 // Struct to gather all pointers to log.Logger and os.File
 type Loggers struct {
@@ -24,14 +27,13 @@ type Loggers struct {
 	panic *log.Logger
 }
 
-// createLoggers returns a Loggers struct
-func createLoggers() Loggers {
-	file := openFile()
-
-	info := log.New(file, "INFO: ", log.LstdFlags)
-	warn := log.New(file, "WARN: ", log.LstdFlags)
-	error := log.New(file, "ERROR: ", log.LstdFlags)
-	panic := log.New(file, "PANIC: ", log.LstdFlags)
+// createLoggers returns a Loggers struct writing to output,
+// file being the log file to close if any.
+func createLoggers(output io.Writer, file *os.File) Loggers {
+	info := log.New(output, "INFO: ", log.LstdFlags)
+	warn := log.New(output, "WARN: ", log.LstdFlags)
+	error := log.New(output, "ERROR: ", log.LstdFlags)
+	panic := log.New(output, "PANIC: ", log.LstdFlags)
 
 	return Loggers{
 		info:  info,
@@ -42,18 +44,30 @@ func createLoggers() Loggers {
 	}
 }
 
-// openFile opens and returns a pointer to a file.
-func openFile() *os.File {
-	file, err := os.OpenFile("logs.txt", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
+// Open opens the log file at path, creating it and its directory if
+// missing, and writes every following line to it.
+func Open(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("Could not create log directory: %w", err)
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0666)
 	if err != nil {
-		log.Fatal(err)
+		return fmt.Errorf("Could not open log file: %w", err)
 	}
 
-	return file
+	CloseFile()
+	loggers = createLoggers(file, file)
+
+	return nil
 }
 
-// CloseFile syncs and closes file.
+// CloseFile syncs and closes file, if one is open.
 func CloseFile() {
+	if loggers.file == nil {
+		return
+	}
+
 	if err := loggers.file.Sync(); err != nil {
 		Panic("file.Sync failing")
 	}
@@ -61,6 +75,8 @@ func CloseFile() {
 	if err := loggers.file.Close(); err != nil {
 		Panic("file.Close failing")
 	}
+
+	loggers = createLoggers(os.Stderr, nil)
 }
 
 // printLineToFile takes in a text string and print function
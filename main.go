@@ -1,24 +1,157 @@
 package main
 
 import (
+	"errors"
+	"flag"
+	"fmt"
 	"os"
+	"time"
 
 	"gioui.org/app"
 	"gioui.org/unit"
 
+	"github.com/alx-b/expensetracker/config"
 	"github.com/alx-b/expensetracker/controller"
 	"github.com/alx-b/expensetracker/database"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/journal"
 	"github.com/alx-b/expensetracker/logger"
 	"github.com/alx-b/expensetracker/ui"
 )
 
+// storage is a domain.Storage to close when the program ends.
+type storage interface {
+	domain.Storage
+	Close() error
+}
+
+// openStorage opens the journal or the sqlite database at path.
+func openStorage(path string) (storage, error) {
+	if config.IsJournal(path) {
+		return journal.CreateJournal(path)
+	}
+	return database.CreateDB(path)
+}
+
 func main() {
 	defer logger.CloseFile()
 
-	db := database.CreateDB()
+	dryRun := flag.Bool("migrate-dry-run", false, "print pending database migrations without applying them and exit")
+	dbFlag := flag.String("db", "", "path of the database, a plain text journal when ending in .journal, .ledger, .hledger or .j (default $"+config.DatabaseEnv+" or the data directory)")
+	logFlag := flag.String("log", "", "path of the log file (default $"+config.LogEnv+" or the state directory)")
+	userFlag := flag.String("user", "", "name written in the audit log (default $"+config.UserEnv+" or the system user)")
+	exportFlag := flag.String("export", "", "export expenses and budgets to this file and exit, in the format of its extension: .csv, .json, .ledger or .beancount")
+	fromFlag := flag.String("from", "", "first date (YYYY-MM-DD), month (YYYY-MM) or year (YYYY) to export")
+	toFlag := flag.String("to", "", "last date (YYYY-MM-DD), month (YYYY-MM) or year (YYYY) to export")
+	formatFlag := flag.String("format", "", "export format when the extension does not tell: csv, json, ledger or beancount")
+	currencyFlag := flag.String("currency", "", "commodity of exported amounts, like EUR, needed by beancount")
+	reportFlag := flag.String("report", "", "write the report of a month to this file and exit, as HTML or PDF by its extension: .html or .pdf")
+	monthFlag := flag.String("month", "", "month (YYYY-MM) of the report (default the current month)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] [database]\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() > 1 || (flag.NArg() == 1 && *dbFlag != "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if flag.NArg() == 1 {
+		*dbFlag = flag.Arg(0)
+	}
+
+	logPath, err := config.LogPath(*logFlag)
+	if err == nil {
+		err = logger.Open(logPath)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	dbPath, err := config.DatabasePath(*dbFlag)
+	if err != nil {
+		logger.Error(err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *dryRun && config.IsJournal(dbPath) {
+		fmt.Println("journals have no migrations")
+		return
+	}
+
+	if *dryRun {
+		pending, err := database.DryRunMigrations(dbPath)
+		for _, description := range pending {
+			fmt.Println("pending migration", description)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	logger.Info("Opening database " + dbPath)
+
+	db, err := openStorage(dbPath)
+	if err != nil {
+		logger.Error(err.Error())
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 	defer db.Close()
 
 	controller := controller.CreateController(db)
+	controller.SetUser(config.User(*userFlag))
+
+	if *exportFlag != "" {
+		count, err := controller.Export(*exportFlag, domain.ExportOptions{
+			From:     *fromFlag,
+			To:       *toFlag,
+			Format:   domain.ExportFormat(*formatFlag),
+			Currency: *currencyFlag,
+		})
+		if err != nil {
+			logger.Error(err.Error())
+			fmt.Fprintln(os.Stderr, err)
+			db.Close()
+			os.Exit(1)
+		}
+		fmt.Printf("exported %d expenses to %s\n", count, *exportFlag)
+		return
+	}
+
+	if *reportFlag != "" {
+		month := time.Now()
+		if *monthFlag != "" {
+			if month, err = time.Parse("2006-01", *monthFlag); err != nil {
+				err = errors.New("Month should be YYYY-MM.")
+			}
+		}
+		if err == nil {
+			err = controller.WriteMonthReport(month.Year(), month.Month(), *reportFlag)
+		}
+		if err != nil {
+			logger.Error(err.Error())
+			fmt.Fprintln(os.Stderr, err)
+			db.Close()
+			os.Exit(1)
+		}
+		fmt.Printf("wrote the report of %s %d to %s\n", month.Month(), month.Year(), *reportFlag)
+		return
+	}
+
+	if err := controller.MaterializeRecurring(); err != nil {
+		logger.Error(err.Error())
+	}
+
+	if purged, err := controller.PurgeExpiredTrash(); err != nil {
+		logger.Error(err.Error())
+	} else if purged > 0 {
+		logger.Info(fmt.Sprintf("Purged %d expenses from the trash", purged))
+	}
 
 	go func() {
 		w := app.NewWindow(app.Title("Simple Expense Tracker"), app.Size(unit.Dp(500), unit.Dp(700)))
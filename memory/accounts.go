@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// accountNamed returns the id of the account matching name
+// case-insensitively, 0 if none does.
+func (s *Storage) accountNamed(name string) int {
+	for id, account := range s.accounts {
+		if strings.EqualFold(account.Name, name) {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// GetAccounts returns every account by name.
+func (s *Storage) GetAccounts() ([]domain.Account, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.Account{}
+	for _, account := range s.accounts {
+		list = append(list, account)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return lessName(list[i].Name, list[i].Id, list[j].Name, list[j].Id, true)
+	})
+
+	return list, nil
+}
+
+// InsertAccount inserts a given account.
+func (s *Storage) InsertAccount(account domain.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accountNamed(account.Name) != 0 {
+		return fmt.Errorf("Could not insert into table: account %q already exists", account.Name)
+	}
+
+	account.Id = s.nextId("accounts")
+	s.accounts[account.Id] = account
+
+	return nil
+}
+
+// UpdateAccount updates an existing account by its Id.
+func (s *Storage) UpdateAccount(account domain.Account) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.accounts[account.Id]; !ok {
+		return fmt.Errorf("Could not find account with id %d", account.Id)
+	}
+
+	if id := s.accountNamed(account.Name); id != 0 && id != account.Id {
+		return fmt.Errorf("Could not update table: account %q already exists", account.Name)
+	}
+
+	s.accounts[account.Id] = account
+	return nil
+}
+
+// DeleteAccount deletes an account by its Id. Its expenses are kept
+// without account, and transfers to it are kept as plain expenses.
+func (s *Storage) DeleteAccount(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, expense := range s.expenses {
+		if expense.AccountId == id {
+			expense.AccountId = 0
+		}
+		if expense.ToAccountId == id {
+			expense.ToAccountId = 0
+			expense.Kind = domain.KindExpense
+		}
+		s.expenses[expense.Id] = expense
+	}
+
+	delete(s.accounts, id)
+	return nil
+}
+
+// GetAccountTransactions returns every expense, income and transfer of an
+// account, oldest first.
+func (s *Storage) GetAccountTransactions(accountId int) ([]domain.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queryExpenses(func(expense domain.Expense) bool {
+		return accountId != 0 && notDeleted(expense) &&
+			(expense.AccountId == accountId || expense.ToAccountId == accountId)
+	}), nil
+}
@@ -0,0 +1,93 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetAttachments returns the attachments of an expense by its Id.
+func (s *Storage) GetAttachments(expenseId int) ([]domain.Attachment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.Attachment{}
+	for _, attachment := range s.attachments {
+		if attachment.ExpenseId == expenseId {
+			list = append(list, attachment)
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].Id < list[j].Id
+	})
+
+	return list, nil
+}
+
+// InsertAttachment keeps the content of an attachment under its File name,
+// unless content is already kept under it, and inserts its metadata.
+func (s *Storage) InsertAttachment(attachment domain.Attachment, content []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.files[attachment.File]; !ok {
+		s.files[attachment.File] = append([]byte{}, content...)
+	}
+
+	attachment.Id = s.nextId("attachments")
+	s.attachments[attachment.Id] = attachment
+
+	return nil
+}
+
+// DeleteAttachment deletes an attachment by its Id and drops its content
+// when no other attachment refers to it.
+func (s *Storage) DeleteAttachment(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attachment, ok := s.attachments[id]
+	if !ok {
+		return nil
+	}
+
+	delete(s.attachments, id)
+	s.collectAttachmentFiles([]string{attachment.File})
+
+	return nil
+}
+
+// AttachmentPath returns the name attachment content is kept under,
+// as nothing is stored on disk.
+func (s *Storage) AttachmentPath(file string) string {
+	return file
+}
+
+// AttachmentContent returns the content kept under file
+// and whether there is any.
+func (s *Storage) AttachmentContent(file string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	content, ok := s.files[file]
+	return append([]byte{}, content...), ok
+}
+
+// collectAttachmentFiles drops the content of the given files
+// when no attachment refers to them anymore.
+func (s *Storage) collectAttachmentFiles(files []string) {
+	for _, file := range files {
+		used := false
+		for _, attachment := range s.attachments {
+			if attachment.File == file {
+				used = true
+				break
+			}
+		}
+
+		if !used {
+			delete(s.files, file)
+		}
+	}
+}
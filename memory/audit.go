@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// InsertAuditEntries appends entries to the audit log.
+func (s *Storage) InsertAuditEntries(entries []domain.AuditEntry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, entry := range entries {
+		entry.Id = s.nextId("audit_log")
+		s.auditLog = append(s.auditLog, entry)
+	}
+
+	return nil
+}
+
+// GetAuditEntries returns the entries of the audit log
+// matching a query, the latest first.
+func (s *Storage) GetAuditEntries(query domain.AuditQuery) ([]domain.AuditEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []domain.AuditEntry{}
+
+	for i := len(s.auditLog) - 1; i >= 0; i-- {
+		if query.Limit > 0 && len(entries) == query.Limit {
+			break
+		}
+
+		entry := s.auditLog[i]
+		switch {
+		case query.Entity != "" && entry.Entity != query.Entity,
+			query.Key != "" && entry.Key != query.Key,
+			query.User != "" && entry.User != query.User,
+			query.From != "" && entry.Timestamp < query.From,
+			query.To != "" && entry.Timestamp > query.To:
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,172 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// categoryNamed returns the id of the category matching name
+// case-insensitively, 0 if none does.
+func (s *Storage) categoryNamed(name string) int {
+	for id, category := range s.categories {
+		if strings.EqualFold(category.Name, name) {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// GetCategories returns every category, archived ones included, by name.
+func (s *Storage) GetCategories() ([]domain.Category, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.Category{}
+	for _, category := range s.categories {
+		list = append(list, category)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return lessName(list[i].Name, list[i].Id, list[j].Name, list[j].Id, true)
+	})
+
+	return list, nil
+}
+
+// GetCategoryWithName returns the category matching name case-insensitively
+// and whether it exists.
+func (s *Storage) GetCategoryWithName(name string) (domain.Category, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	id := s.categoryNamed(name)
+	if id == 0 {
+		return domain.Category{}, false, nil
+	}
+
+	return s.categories[id], true, nil
+}
+
+// InsertCategory inserts a category and returns its new id.
+func (s *Storage) InsertCategory(category domain.Category) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.categoryNamed(category.Name) != 0 {
+		return 0, fmt.Errorf("Could not insert into table: category %q already exists", category.Name)
+	}
+
+	category.Id = s.nextId("categories")
+	s.categories[category.Id] = category
+
+	return category.Id, nil
+}
+
+// UpdateCategory updates an existing category by its Id.
+func (s *Storage) UpdateCategory(category domain.Category) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categories[category.Id]; !ok {
+		return fmt.Errorf("Could not find category with id %d", category.Id)
+	}
+
+	if id := s.categoryNamed(category.Name); id != 0 && id != category.Id {
+		return fmt.Errorf("Could not update table: category %q already exists", category.Name)
+	}
+
+	s.categories[category.Id] = category
+	return nil
+}
+
+// DeleteCategory deletes a category and its budgets by its Id. Its expenses
+// and rules lose their category and its children become top level categories.
+func (s *Storage) DeleteCategory(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, expense := range s.expenses {
+		if expense.CategoryId == id {
+			expense.CategoryId = 0
+		}
+		for i := range expense.Splits {
+			if expense.Splits[i].CategoryId == id {
+				expense.Splits[i].CategoryId = 0
+			}
+		}
+		s.expenses[expense.Id] = expense
+	}
+
+	for _, rule := range s.categorizationRules {
+		if rule.CategoryId == id {
+			rule.CategoryId = 0
+			s.categorizationRules[rule.Id] = rule
+		}
+	}
+
+	for _, rule := range s.recurringRules {
+		if rule.CategoryId == id {
+			rule.CategoryId = 0
+			s.recurringRules[rule.Id] = rule
+		}
+	}
+
+	for _, budgets := range s.categoryBudgets {
+		delete(budgets, id)
+	}
+
+	for _, category := range s.categories {
+		if category.ParentId == id {
+			category.ParentId = 0
+			s.categories[category.Id] = category
+		}
+	}
+
+	delete(s.categories, id)
+	return nil
+}
+
+// GetCategoryBudgets returns the budget amount of every category that has
+// one for a specific month and year (YYYY-MM) or "default", by category id.
+func (s *Storage) GetCategoryBudgets(date string) (map[int]domain.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	budgets := map[int]domain.Money{}
+	for categoryId, amount := range s.categoryBudgets[date] {
+		budgets[categoryId] = amount
+	}
+
+	return budgets, nil
+}
+
+// InsertCategoryBudget inserts or replaces the budget amount of a category
+// for a specific month and year (YYYY-MM) or "default".
+func (s *Storage) InsertCategoryBudget(categoryId int, amount domain.Money, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.categoryBudgets[date] == nil {
+		s.categoryBudgets[date] = map[int]domain.Money{}
+	}
+
+	s.categoryBudgets[date][categoryId] = amount
+	return nil
+}
+
+// DeleteCategoryBudget deletes the budget amount of a category for a specific
+// month and year (YYYY-MM) or "default".
+func (s *Storage) DeleteCategoryBudget(categoryId int, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.categoryBudgets[date], categoryId)
+	if len(s.categoryBudgets[date]) == 0 {
+		delete(s.categoryBudgets, date)
+	}
+	return nil
+}
@@ -0,0 +1,399 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Storage keeps everything in memory, following the semantics of the
+// sqlite database: ids are assigned on insert, names are joined on read,
+// category, account and payee names are unique case-insensitively and
+// deleting a record unlinks what refers to it. Nothing outlives it.
+type Storage struct {
+	mu                  sync.Mutex
+	lastIds             map[string]int
+	expenses            map[int]domain.Expense
+	budgets             map[string]domain.Money
+	categories          map[int]domain.Category
+	categoryBudgets     map[string]map[int]domain.Money
+	recurringRules      map[int]domain.RecurringRule
+	accounts            map[int]domain.Account
+	attachments         map[int]domain.Attachment
+	files               map[string][]byte
+	payees              map[int]domain.Payee
+	categorizationRules map[int]domain.CategorizationRule
+	auditLog            []domain.AuditEntry
+	settings            map[string]string
+	purgedImportIds     map[string]bool
+}
+
+// CreateStorage returns pointer to an empty Storage struct, holding like
+// a new database a default budget of 0.00 and a "Main" checking account.
+func CreateStorage() *Storage {
+	return &Storage{
+		lastIds:             map[string]int{"accounts": 1},
+		expenses:            map[int]domain.Expense{},
+		budgets:             map[string]domain.Money{"default": 0},
+		categories:          map[int]domain.Category{},
+		categoryBudgets:     map[string]map[int]domain.Money{},
+		recurringRules:      map[int]domain.RecurringRule{},
+		accounts:            map[int]domain.Account{1: {Id: 1, Name: "Main", Type: domain.Checking}},
+		attachments:         map[int]domain.Attachment{},
+		files:               map[string][]byte{},
+		payees:              map[int]domain.Payee{},
+		categorizationRules: map[int]domain.CategorizationRule{},
+		auditLog:            []domain.AuditEntry{},
+		settings:            map[string]string{},
+		purgedImportIds:     map[string]bool{},
+	}
+}
+
+// nextId returns a new id for a record of table.
+func (s *Storage) nextId(table string) int {
+	s.lastIds[table]++
+	return s.lastIds[table]
+}
+
+// lessName reports whether a record named a with id aId sorts before
+// one named b with id bId: by name, case-insensitively when nocase, then by id.
+func lessName(a string, aId int, b string, bId int, nocase bool) bool {
+	if nocase {
+		a, b = strings.ToLower(a), strings.ToLower(b)
+	}
+	if a != b {
+		return a < b
+	}
+	return aId < bId
+}
+
+// copyTags returns a copy of tags, never nil.
+func copyTags(tags []string) []string {
+	return append([]string{}, tags...)
+}
+
+// storeSplits returns the split lines of an expense as they are stored,
+// with new ids and without category names.
+func (s *Storage) storeSplits(splits []domain.Split) []domain.Split {
+	if len(splits) == 0 {
+		return nil
+	}
+
+	list := make([]domain.Split, len(splits))
+	for i, split := range splits {
+		split.Id = s.nextId("expense_splits")
+		split.Category = ""
+		list[i] = split
+	}
+
+	return list
+}
+
+// categoryName returns the name of a category, empty if it does not exist.
+func (s *Storage) categoryName(id int) string {
+	return s.categories[id].Name
+}
+
+// accountName returns the name of an account, empty if it does not exist.
+func (s *Storage) accountName(id int) string {
+	return s.accounts[id].Name
+}
+
+// expense returns a stored expense as it is read: with the names of its
+// category, accounts and payee, its attachment count and copies of its
+// tags and split lines.
+func (s *Storage) expense(stored domain.Expense) domain.Expense {
+	expense := stored
+	expense.Category = s.categoryName(expense.CategoryId)
+	expense.Account = s.accountName(expense.AccountId)
+	expense.ToAccount = s.accountName(expense.ToAccountId)
+	expense.Payee = s.payees[expense.PayeeId].Name
+	expense.Tags = copyTags(stored.Tags)
+	expense.Splits = nil
+	expense.Attachments = 0
+
+	for _, split := range stored.Splits {
+		split.Category = s.categoryName(split.CategoryId)
+		expense.Splits = append(expense.Splits, split)
+	}
+
+	for _, attachment := range s.attachments {
+		if attachment.ExpenseId == expense.Id {
+			expense.Attachments++
+		}
+	}
+
+	return expense
+}
+
+// queryExpenses returns the expenses keep selects, as they are read,
+// ordered by date.
+func (s *Storage) queryExpenses(keep func(domain.Expense) bool) []domain.Expense {
+	list := []domain.Expense{}
+
+	for _, expense := range s.expenses {
+		if keep(expense) {
+			list = append(list, s.expense(expense))
+		}
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		a, b := domain.RangeDate(list[i].Date), domain.RangeDate(list[j].Date)
+		if a != b {
+			return a < b
+		}
+		return list[i].Id < list[j].Id
+	})
+
+	return list
+}
+
+// notDeleted leaves out expenses in the trash.
+func notDeleted(expense domain.Expense) bool {
+	return expense.DeletedAt == ""
+}
+
+// occurrenceTaken reports whether an expense other than exceptId was
+// already generated from a rule on date.
+func (s *Storage) occurrenceTaken(ruleId int, date string, exceptId int) bool {
+	if ruleId == 0 {
+		return false
+	}
+
+	for _, expense := range s.expenses {
+		if expense.Id != exceptId && expense.RuleId == ruleId && expense.Date == date {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetExpenses returns every expense not in the trash ordered by date.
+func (s *Storage) GetExpenses() ([]domain.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.queryExpenses(notDeleted), nil
+}
+
+// GetExpenseWithId returns the expense with an id, even in the trash,
+// and whether it exists.
+func (s *Storage) GetExpenseWithId(id int) (domain.Expense, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expense, ok := s.expenses[id]
+	if !ok {
+		return domain.Expense{}, false, nil
+	}
+
+	return s.expense(expense), true, nil
+}
+
+// GetFirstExpenseDate returns the date of the oldest expense not in the
+// trash and whether there is any expense.
+func (s *Storage) GetFirstExpenseDate() (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.queryExpenses(notDeleted)
+	if len(list) == 0 {
+		return "", false, nil
+	}
+
+	return domain.RangeDate(list[0].Date), true, nil
+}
+
+// GetExpensesInRange returns the expenses not in the trash dated from one
+// day to another (YYYY-MM-DD, both included) ordered by date. Month-only expenses count
+// as dated on the first day of their month (see domain.RangeDate).
+func (s *Storage) GetExpensesInRange(from, to string) ([]domain.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	from, to = domain.RangeDate(from), domain.RangeDate(to)
+
+	return s.queryExpenses(func(expense domain.Expense) bool {
+		day := domain.RangeDate(expense.Date)
+		return notDeleted(expense) && day >= from && day <= to
+	}), nil
+}
+
+// GetDefaultBudget returns the default monthly budget amount.
+func (s *Storage) GetDefaultBudget() (domain.Money, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.budgets["default"], nil
+}
+
+// UpdateDefaultBudget updates the default monthly budget amount.
+func (s *Storage) UpdateDefaultBudget(amount domain.Money) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.budgets["default"] = amount
+	return nil
+}
+
+// InsertBudget inserts budget amount for a specific month and year (YYYY-MM).
+func (s *Storage) InsertBudget(amount domain.Money, date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.budgets[date] = amount
+	return nil
+}
+
+// DeleteBudget deletes the budget of a specific month and year (YYYY-MM),
+// the month falling back to the default budget.
+func (s *Storage) DeleteBudget(date string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if date != "default" {
+		delete(s.budgets, date)
+	}
+	return nil
+}
+
+// GetBudgetWithYearMonth returns budget amount of a specific month and
+// year (YYYY-MM) and whether a budget was set for that month.
+func (s *Storage) GetBudgetWithYearMonth(date string) (domain.Money, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	amount, ok := s.budgets[date]
+	return amount, ok, nil
+}
+
+// InsertExpense inserts a given expense with its split lines and returns
+// its id.
+func (s *Storage) InsertExpense(expense domain.Expense) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkNewExpenses([]domain.Expense{expense}); err != nil {
+		return 0, err
+	}
+
+	return s.insertExpense(expense), nil
+}
+
+// InsertExpenses inserts expenses with their split lines, all of them
+// or none, and returns their ids.
+func (s *Storage) InsertExpenses(expenses []domain.Expense) ([]int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkNewExpenses(expenses); err != nil {
+		return nil, err
+	}
+
+	ids := []int{}
+	for _, expense := range expenses {
+		ids = append(ids, s.insertExpense(expense))
+	}
+
+	return ids, nil
+}
+
+// checkNewExpenses returns an error when inserting expenses would give
+// a recurring rule two expenses on a date or two expenses an import id.
+func (s *Storage) checkNewExpenses(expenses []domain.Expense) error {
+	occurrences := map[string]bool{}
+	importIds := map[string]bool{}
+
+	for _, expense := range expenses {
+		occurrence := fmt.Sprintf("%d %s", expense.RuleId, expense.Date)
+		if s.occurrenceTaken(expense.RuleId, expense.Date, 0) || (expense.RuleId != 0 && occurrences[occurrence]) {
+			return fmt.Errorf("Could not insert into table: rule %d already has an expense on %s", expense.RuleId, expense.Date)
+		}
+		occurrences[occurrence] = true
+
+		if expense.ImportId == "" {
+			continue
+		}
+		if _, taken := s.expenseWithImportId(expense.ImportId); taken || importIds[expense.ImportId] {
+			return fmt.Errorf("Could not insert into table: import id %q already exists", expense.ImportId)
+		}
+		importIds[expense.ImportId] = true
+	}
+
+	return nil
+}
+
+// expenseWithImportId returns the stored expense with an import id
+// and whether there is one.
+func (s *Storage) expenseWithImportId(importId string) (domain.Expense, bool) {
+	for _, expense := range s.expenses {
+		if expense.ImportId == importId {
+			return expense, true
+		}
+	}
+
+	return domain.Expense{}, false
+}
+
+// GetExpenseWithImportId returns the expense imported from a statement
+// transaction, even in the trash, and whether it exists.
+func (s *Storage) GetExpenseWithImportId(importId string) (domain.Expense, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.expenseWithImportId(importId)
+	if !ok || importId == "" {
+		return domain.Expense{}, false, nil
+	}
+
+	return s.expense(stored), true, nil
+}
+
+// IsImportIdPurged reports whether an expense imported from a statement
+// transaction was purged from the trash.
+func (s *Storage) IsImportIdPurged(importId string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.purgedImportIds[importId], nil
+}
+
+// insertExpense stores a new expense and returns its id.
+func (s *Storage) insertExpense(expense domain.Expense) int {
+	expense.Id = s.nextId("expenses")
+	expense.Tags = copyTags(expense.Tags)
+	expense.Splits = s.storeSplits(expense.Splits)
+	expense.DeletedAt = ""
+	s.expenses[expense.Id] = expense
+
+	return expense.Id
+}
+
+// UpdateExpense updates an existing expense by its Id, replacing its
+// split lines. Its recurring rule, import id and trash state are kept.
+func (s *Storage) UpdateExpense(expense domain.Expense) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, ok := s.expenses[expense.Id]
+	if !ok {
+		return fmt.Errorf("Could not find expense with id %d", expense.Id)
+	}
+
+	if s.occurrenceTaken(stored.RuleId, expense.Date, expense.Id) {
+		return fmt.Errorf("Could not update table: rule %d already has an expense on %s", stored.RuleId, expense.Date)
+	}
+
+	expense.RuleId = stored.RuleId
+	expense.ImportId = stored.ImportId
+	expense.DeletedAt = stored.DeletedAt
+	expense.Tags = copyTags(expense.Tags)
+	expense.Splits = s.storeSplits(expense.Splits)
+	s.expenses[expense.Id] = expense
+
+	return nil
+}
@@ -0,0 +1,14 @@
+package memory
+
+import (
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/storagetest"
+)
+
+func TestStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) domain.Storage {
+		return CreateStorage()
+	})
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetPayees returns every payee by name.
+func (s *Storage) GetPayees() ([]domain.Payee, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.Payee{}
+	for _, payee := range s.payees {
+		list = append(list, payee)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return lessName(list[i].Name, list[i].Id, list[j].Name, list[j].Id, true)
+	})
+
+	return list, nil
+}
+
+// GetPayeeWithName returns the payee matching name case-insensitively
+// and whether it exists.
+func (s *Storage) GetPayeeWithName(name string) (domain.Payee, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, payee := range s.payees {
+		if strings.EqualFold(payee.Name, name) {
+			return payee, true, nil
+		}
+	}
+
+	return domain.Payee{}, false, nil
+}
+
+// InsertPayee inserts a payee and returns its new id.
+func (s *Storage) InsertPayee(payee domain.Payee) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, other := range s.payees {
+		if strings.EqualFold(other.Name, payee.Name) {
+			return 0, fmt.Errorf("Could not insert into table: payee %q already exists", payee.Name)
+		}
+	}
+
+	payee.Id = s.nextId("payees")
+	s.payees[payee.Id] = payee
+
+	return payee.Id, nil
+}
@@ -0,0 +1,122 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetRecurringRules returns every recurring rule by name.
+func (s *Storage) GetRecurringRules() ([]domain.RecurringRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.RecurringRule{}
+	for _, rule := range s.recurringRules {
+		rule.Category = s.categoryName(rule.CategoryId)
+		list = append(list, rule)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		return lessName(list[i].Name, list[i].Id, list[j].Name, list[j].Id, false)
+	})
+
+	return list, nil
+}
+
+// InsertRecurringRule inserts a given rule.
+func (s *Storage) InsertRecurringRule(rule domain.RecurringRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.Id = s.nextId("recurring_rules")
+	s.recurringRules[rule.Id] = rule
+
+	return nil
+}
+
+// UpdateRecurringRule updates an existing rule by its Id.
+func (s *Storage) UpdateRecurringRule(rule domain.RecurringRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.recurringRules[rule.Id]; !ok {
+		return fmt.Errorf("Could not find recurring rule with id %d", rule.Id)
+	}
+
+	s.recurringRules[rule.Id] = rule
+	return nil
+}
+
+// DeleteRecurringRule deletes a rule by its Id.
+// Expenses it generated are kept but no longer linked to it.
+func (s *Storage) DeleteRecurringRule(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, expense := range s.expenses {
+		if expense.RuleId == id {
+			expense.RuleId = 0
+			s.expenses[expense.Id] = expense
+		}
+	}
+
+	delete(s.recurringRules, id)
+	return nil
+}
+
+// InsertRecurringOccurrences inserts the expenses generated from a rule and
+// moves its materialized until date, never duplicating an occurrence
+// already inserted for the same date.
+func (s *Storage) InsertRecurringOccurrences(ruleId int, expenses []domain.Expense, materializedUntil string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, expense := range expenses {
+		if s.occurrenceTaken(ruleId, expense.Date, 0) {
+			continue
+		}
+
+		id := s.nextId("expenses")
+		s.expenses[id] = domain.Expense{
+			Id:         id,
+			Name:       expense.Name,
+			Date:       expense.Date,
+			Amount:     expense.Amount,
+			CategoryId: expense.CategoryId,
+			RuleId:     ruleId,
+			Kind:       domain.KindExpense,
+			Tags:       []string{},
+		}
+	}
+
+	if rule, ok := s.recurringRules[ruleId]; ok {
+		rule.MaterializedUntil = materializedUntil
+		s.recurringRules[ruleId] = rule
+	}
+
+	return nil
+}
+
+// UpdateRuleExpensesAfter updates name, amount and category of the expenses
+// generated from a rule dated after date (YYYY-MM-DD). Their split lines
+// are dropped as they no longer add up to the new amount.
+func (s *Storage) UpdateRuleExpensesAfter(ruleId int, date string, expense domain.Expense) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stored := range s.expenses {
+		if stored.RuleId != ruleId || domain.RangeDate(stored.Date) <= date {
+			continue
+		}
+
+		stored.Name = expense.Name
+		stored.Amount = expense.Amount
+		stored.CategoryId = expense.CategoryId
+		stored.Splits = nil
+		s.expenses[stored.Id] = stored
+	}
+
+	return nil
+}
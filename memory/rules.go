@@ -0,0 +1,68 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// GetCategorizationRules returns every categorization rule
+// in the order they are tried.
+func (s *Storage) GetCategorizationRules() ([]domain.CategorizationRule, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := []domain.CategorizationRule{}
+	for _, rule := range s.categorizationRules {
+		rule.Category = s.categoryName(rule.CategoryId)
+		rule.Payee = s.payees[rule.PayeeId].Name
+		rule.Tags = copyTags(rule.Tags)
+		list = append(list, rule)
+	}
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Priority != list[j].Priority {
+			return list[i].Priority < list[j].Priority
+		}
+		return list[i].Id < list[j].Id
+	})
+
+	return list, nil
+}
+
+// InsertCategorizationRule inserts a given rule.
+func (s *Storage) InsertCategorizationRule(rule domain.CategorizationRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	rule.Id = s.nextId("categorization_rules")
+	rule.Tags = copyTags(rule.Tags)
+	s.categorizationRules[rule.Id] = rule
+
+	return nil
+}
+
+// UpdateCategorizationRule updates an existing rule by its Id.
+func (s *Storage) UpdateCategorizationRule(rule domain.CategorizationRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.categorizationRules[rule.Id]; !ok {
+		return fmt.Errorf("Could not find categorization rule with id %d", rule.Id)
+	}
+
+	rule.Tags = copyTags(rule.Tags)
+	s.categorizationRules[rule.Id] = rule
+
+	return nil
+}
+
+// DeleteCategorizationRule deletes a rule by its Id.
+func (s *Storage) DeleteCategorizationRule(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.categorizationRules, id)
+	return nil
+}
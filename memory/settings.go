@@ -0,0 +1,19 @@
+package memory
+
+// GetSetting returns the value stored under key and whether it is set.
+func (s *Storage) GetSetting(key string) (string, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.settings[key]
+	return value, ok, nil
+}
+
+// UpdateSetting stores value under key, replacing any previous value.
+func (s *Storage) UpdateSetting(key, value string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.settings[key] = value
+	return nil
+}
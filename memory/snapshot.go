@@ -0,0 +1,238 @@
+package memory
+
+import (
+	"sort"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Snapshot is the whole content of a Storage but attachment contents,
+// for storages persisting it elsewhere. Lists are ordered by id, expenses
+// in the trash included, and maps leave out dates without budget.
+type Snapshot struct {
+	Expenses            []domain.Expense
+	Budgets             map[string]domain.Money
+	Categories          []domain.Category
+	CategoryBudgets     map[string]map[int]domain.Money
+	RecurringRules      []domain.RecurringRule
+	Accounts            []domain.Account
+	Attachments         []domain.Attachment
+	Payees              []domain.Payee
+	CategorizationRules []domain.CategorizationRule
+	AuditLog            []domain.AuditEntry
+	Settings            map[string]string
+	PurgedImportIds     []string
+}
+
+// Snapshot returns the content of the storage.
+func (s *Storage) Snapshot() Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snapshot := Snapshot{
+		Expenses:            []domain.Expense{},
+		Categories:          []domain.Category{},
+		RecurringRules:      []domain.RecurringRule{},
+		Accounts:            []domain.Account{},
+		Attachments:         []domain.Attachment{},
+		Payees:              []domain.Payee{},
+		CategorizationRules: []domain.CategorizationRule{},
+		Budgets:             map[string]domain.Money{},
+		CategoryBudgets:     map[string]map[int]domain.Money{},
+		AuditLog:            append([]domain.AuditEntry{}, s.auditLog...),
+		Settings:            map[string]string{},
+		PurgedImportIds:     []string{},
+	}
+
+	for _, expense := range s.expenses {
+		snapshot.Expenses = append(snapshot.Expenses, s.expense(expense))
+	}
+	sort.Slice(snapshot.Expenses, func(i, j int) bool {
+		return snapshot.Expenses[i].Id < snapshot.Expenses[j].Id
+	})
+
+	for date, amount := range s.budgets {
+		snapshot.Budgets[date] = amount
+	}
+
+	for date, budgets := range s.categoryBudgets {
+		if len(budgets) == 0 {
+			continue
+		}
+		snapshot.CategoryBudgets[date] = map[int]domain.Money{}
+		for categoryId, amount := range budgets {
+			snapshot.CategoryBudgets[date][categoryId] = amount
+		}
+	}
+
+	for key, value := range s.settings {
+		snapshot.Settings[key] = value
+	}
+
+	for importId := range s.purgedImportIds {
+		snapshot.PurgedImportIds = append(snapshot.PurgedImportIds, importId)
+	}
+	sort.Strings(snapshot.PurgedImportIds)
+
+	for _, category := range s.categories {
+		snapshot.Categories = append(snapshot.Categories, category)
+	}
+	sort.Slice(snapshot.Categories, func(i, j int) bool {
+		return snapshot.Categories[i].Id < snapshot.Categories[j].Id
+	})
+
+	for _, account := range s.accounts {
+		snapshot.Accounts = append(snapshot.Accounts, account)
+	}
+	sort.Slice(snapshot.Accounts, func(i, j int) bool {
+		return snapshot.Accounts[i].Id < snapshot.Accounts[j].Id
+	})
+
+	for _, payee := range s.payees {
+		snapshot.Payees = append(snapshot.Payees, payee)
+	}
+	sort.Slice(snapshot.Payees, func(i, j int) bool {
+		return snapshot.Payees[i].Id < snapshot.Payees[j].Id
+	})
+
+	for _, attachment := range s.attachments {
+		snapshot.Attachments = append(snapshot.Attachments, attachment)
+	}
+	sort.Slice(snapshot.Attachments, func(i, j int) bool {
+		return snapshot.Attachments[i].Id < snapshot.Attachments[j].Id
+	})
+
+	for _, rule := range s.recurringRules {
+		rule.Category = s.categoryName(rule.CategoryId)
+		snapshot.RecurringRules = append(snapshot.RecurringRules, rule)
+	}
+	sort.Slice(snapshot.RecurringRules, func(i, j int) bool {
+		return snapshot.RecurringRules[i].Id < snapshot.RecurringRules[j].Id
+	})
+
+	for _, rule := range s.categorizationRules {
+		rule.Category = s.categoryName(rule.CategoryId)
+		rule.Payee = s.payees[rule.PayeeId].Name
+		rule.Tags = copyTags(rule.Tags)
+		snapshot.CategorizationRules = append(snapshot.CategorizationRules, rule)
+	}
+	sort.Slice(snapshot.CategorizationRules, func(i, j int) bool {
+		return snapshot.CategorizationRules[i].Id < snapshot.CategorizationRules[j].Id
+	})
+
+	return snapshot
+}
+
+// CreateStorageFrom returns pointer to a Storage struct holding the content
+// of a snapshot, keeping its ids. Split lines without id get one.
+func CreateStorageFrom(snapshot Snapshot) *Storage {
+	s := CreateStorage()
+	s.Restore(snapshot)
+	return s
+}
+
+// Restore replaces the content of the storage with a snapshot,
+// keeping its ids. Split lines without id get one.
+func (s *Storage) Restore(snapshot Snapshot) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	restored := CreateStorage()
+	restored.lastIds = map[string]int{}
+	restored.accounts = map[int]domain.Account{}
+	restored.files = s.files
+
+	for _, expense := range snapshot.Expenses {
+		restored.keepId("expenses", expense.Id)
+		expense.Tags = copyTags(expense.Tags)
+		expense.Splits = append([]domain.Split(nil), expense.Splits...)
+		for _, split := range expense.Splits {
+			restored.keepId("expense_splits", split.Id)
+		}
+		restored.expenses[expense.Id] = expense
+	}
+
+	for _, expense := range restored.expenses {
+		for i := range expense.Splits {
+			if expense.Splits[i].Id == 0 {
+				expense.Splits[i].Id = restored.nextId("expense_splits")
+			}
+		}
+	}
+
+	for date, amount := range snapshot.Budgets {
+		restored.budgets[date] = amount
+	}
+
+	for date, budgets := range snapshot.CategoryBudgets {
+		restored.categoryBudgets[date] = map[int]domain.Money{}
+		for categoryId, amount := range budgets {
+			restored.categoryBudgets[date][categoryId] = amount
+		}
+	}
+
+	for _, category := range snapshot.Categories {
+		restored.keepId("categories", category.Id)
+		restored.categories[category.Id] = category
+	}
+
+	for _, rule := range snapshot.RecurringRules {
+		restored.keepId("recurring_rules", rule.Id)
+		restored.recurringRules[rule.Id] = rule
+	}
+
+	for _, account := range snapshot.Accounts {
+		restored.keepId("accounts", account.Id)
+		restored.accounts[account.Id] = account
+	}
+
+	for _, attachment := range snapshot.Attachments {
+		restored.keepId("attachments", attachment.Id)
+		restored.attachments[attachment.Id] = attachment
+	}
+
+	for _, payee := range snapshot.Payees {
+		restored.keepId("payees", payee.Id)
+		restored.payees[payee.Id] = payee
+	}
+
+	for _, rule := range snapshot.CategorizationRules {
+		restored.keepId("categorization_rules", rule.Id)
+		rule.Tags = copyTags(rule.Tags)
+		restored.categorizationRules[rule.Id] = rule
+	}
+
+	for _, entry := range snapshot.AuditLog {
+		restored.keepId("audit_log", entry.Id)
+		restored.auditLog = append(restored.auditLog, entry)
+	}
+
+	for key, value := range snapshot.Settings {
+		restored.settings[key] = value
+	}
+
+	for _, importId := range snapshot.PurgedImportIds {
+		restored.purgedImportIds[importId] = true
+	}
+
+	s.lastIds = restored.lastIds
+	s.expenses = restored.expenses
+	s.budgets = restored.budgets
+	s.categories = restored.categories
+	s.categoryBudgets = restored.categoryBudgets
+	s.recurringRules = restored.recurringRules
+	s.accounts = restored.accounts
+	s.attachments = restored.attachments
+	s.payees = restored.payees
+	s.categorizationRules = restored.categorizationRules
+	s.auditLog = restored.auditLog
+	s.settings = restored.settings
+	s.purgedImportIds = restored.purgedImportIds
+}
+
+// keepId makes sure new records of table get ids above id.
+func (s *Storage) keepId(table string, id int) {
+	if id > s.lastIds[table] {
+		s.lastIds[table] = id
+	}
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// DeleteExpense moves an expense to the trash by its Id,
+// deletedAt being when (RFC 3339 in UTC).
+func (s *Storage) DeleteExpense(id int, deletedAt string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expense, ok := s.expenses[id]
+	if !ok || expense.DeletedAt != "" {
+		return fmt.Errorf("Could not find expense with id %d", id)
+	}
+
+	expense.DeletedAt = deletedAt
+	s.expenses[id] = expense
+
+	return nil
+}
+
+// GetDeletedExpenses returns the expenses in the trash,
+// the latest deleted first.
+func (s *Storage) GetDeletedExpenses() ([]domain.Expense, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := s.queryExpenses(func(expense domain.Expense) bool {
+		return !notDeleted(expense)
+	})
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].DeletedAt != list[j].DeletedAt {
+			return list[i].DeletedAt > list[j].DeletedAt
+		}
+		return list[i].Id > list[j].Id
+	})
+
+	return list, nil
+}
+
+// RestoreExpense takes an expense out of the trash by its Id.
+func (s *Storage) RestoreExpense(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expense, ok := s.expenses[id]
+	if !ok || expense.DeletedAt == "" {
+		return fmt.Errorf("Could not find expense with id %d in the trash", id)
+	}
+
+	expense.DeletedAt = ""
+	s.expenses[id] = expense
+
+	return nil
+}
+
+// PurgeExpense permanently deletes an expense in the trash by its Id,
+// along with its split lines and attachments.
+func (s *Storage) PurgeExpense(id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expense, ok := s.expenses[id]
+	if !ok || expense.DeletedAt == "" {
+		return fmt.Errorf("Could not find expense with id %d in the trash", id)
+	}
+
+	if expense.ImportId != "" {
+		s.purgedImportIds[expense.ImportId] = true
+	}
+
+	s.removeExpenses([]int{id})
+
+	return nil
+}
+
+// DiscardExpenses permanently deletes expenses by their Id as if they
+// were never inserted, for changes rolled back. Unlike purged ones,
+// their import ids can be imported again.
+func (s *Storage) DiscardExpenses(ids []int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, id := range ids {
+		if _, ok := s.expenses[id]; !ok {
+			return fmt.Errorf("Could not find expense with id %d", id)
+		}
+	}
+
+	s.removeExpenses(ids)
+
+	return nil
+}
+
+// removeExpenses deletes expenses along with their split lines and
+// attachments, and the attachment files no other expense refers to.
+func (s *Storage) removeExpenses(ids []int) {
+	removed := map[int]bool{}
+	for _, id := range ids {
+		removed[id] = true
+		delete(s.expenses, id)
+	}
+
+	files := []string{}
+	for _, attachment := range s.attachments {
+		if removed[attachment.ExpenseId] {
+			files = append(files, attachment.File)
+			delete(s.attachments, attachment.Id)
+		}
+	}
+
+	s.collectAttachmentFiles(files)
+}
@@ -0,0 +1,121 @@
+package report
+
+import (
+	"fmt"
+	"html"
+	"html/template"
+	"math"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// barWidth is the width of the longest bar of bar charts, in pixels.
+const barWidth = 320
+
+// donutChart returns an SVG donut of the share of each slice, with its
+// legend.
+func donutChart(slices []slice) template.HTML {
+	if len(slices) == 0 {
+		return template.HTML(`<p class="empty">No spending this month.</p>`)
+	}
+
+	const radius, thickness, center = 70.0, 34.0, 100.0
+	circumference := 2 * math.Pi * radius
+	height := math.Max(200, float64(len(slices))*22+20)
+
+	svg := strings.Builder{}
+	fmt.Fprintf(&svg, `<svg class="chart" viewBox="0 0 560 %.0f" width="560" height="%.0f" role="img" aria-label="Spending by category">`, height, height)
+
+	offset := 0.0
+	for _, s := range slices {
+		length := s.Share * circumference
+		fmt.Fprintf(&svg, `<circle cx="%.0f" cy="%.0f" r="%.0f" fill="none" stroke="%s" stroke-width="%.0f" stroke-dasharray="%.2f %.2f" stroke-dashoffset="%.2f" transform="rotate(-90 %.0f %.0f)"><title>%s</title></circle>`,
+			center, center, radius, s.Color, thickness, length, circumference-length, -offset, center, center, html.EscapeString(s.Label))
+		offset += length
+	}
+
+	for i, s := range slices {
+		y := 20 + float64(i)*22
+		fmt.Fprintf(&svg, `<rect x="220" y="%.0f" width="14" height="14" fill="%s"/>`, y, s.Color)
+		fmt.Fprintf(&svg, `<text x="242" y="%.0f">%s</text>`, y+12, html.EscapeString(s.Label))
+		fmt.Fprintf(&svg, `<text x="550" y="%.0f" text-anchor="end">%s (%s)</text>`, y+12, s.Amount, percent(s.Share))
+	}
+
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
+
+// categoryChart returns SVG bars of the spending of each category over
+// its budget, red when over it.
+func categoryChart(categories []domain.CategoryComparison) template.HTML {
+	rows := []domain.CategoryComparison{}
+	max := domain.Money(0)
+	for _, category := range categories {
+		if category.Budget <= 0 && category.Spent <= 0 {
+			continue
+		}
+		rows = append(rows, category)
+		max = largest(max, category.Budget, category.Spent)
+	}
+
+	if len(rows) == 0 {
+		return template.HTML(`<p class="empty">No budget or spending this month.</p>`)
+	}
+
+	svg := strings.Builder{}
+	height := float64(len(rows))*28 + 10
+	fmt.Fprintf(&svg, `<svg class="chart" viewBox="0 0 560 %.0f" width="560" height="%.0f" role="img" aria-label="Spending and budget by category">`, height, height)
+
+	for i, category := range rows {
+		y := 5 + float64(i)*28
+		color := palette[0]
+		if category.Budget > 0 && category.Spent > category.Budget {
+			color = overColor
+		}
+
+		fmt.Fprintf(&svg, `<text x="140" y="%.0f" text-anchor="end">%s</text>`, y+15, html.EscapeString(category.Category))
+		fmt.Fprintf(&svg, `<rect x="150" y="%.0f" width="%.1f" height="20" fill="%s"/>`, y, ratio(category.Budget, max)*barWidth, budgetColor)
+		fmt.Fprintf(&svg, `<rect x="150" y="%.0f" width="%.1f" height="12" fill="%s"><title>%s of %s</title></rect>`,
+			y+4, ratio(category.Spent, max)*barWidth, color, category.Spent, category.Budget)
+		fmt.Fprintf(&svg, `<text x="556" y="%.0f" text-anchor="end">%s</text>`, y+15, category.Spent)
+	}
+
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
+
+// comparisonChart returns SVG bars of the spending, income and budget of
+// the month next to the ones of the month before.
+func comparisonChart(r domain.MonthReport) template.HTML {
+	rows := summaryRows(r)
+	picked := []summaryRow{rows[3], rows[5], rows[0]}
+
+	max := domain.Money(0)
+	for _, row := range picked {
+		max = largest(max, row.Current, row.Previous)
+	}
+
+	svg := strings.Builder{}
+	height := float64(len(picked))*48 + 30
+	fmt.Fprintf(&svg, `<svg class="chart" viewBox="0 0 560 %.0f" width="560" height="%.0f" role="img" aria-label="Comparison with the previous month">`, height, height)
+
+	for i, row := range picked {
+		y := 5 + float64(i)*48
+		fmt.Fprintf(&svg, `<text x="140" y="%.0f" text-anchor="end">%s</text>`, y+22, row.Label)
+		fmt.Fprintf(&svg, `<rect x="150" y="%.0f" width="%.1f" height="18" fill="%s"/>`, y, ratio(row.Current, max)*barWidth, palette[0])
+		fmt.Fprintf(&svg, `<text x="556" y="%.0f" text-anchor="end">%s</text>`, y+14, row.Current)
+		fmt.Fprintf(&svg, `<rect x="150" y="%.0f" width="%.1f" height="18" fill="%s"/>`, y+20, ratio(row.Previous, max)*barWidth, previousColor)
+		fmt.Fprintf(&svg, `<text x="556" y="%.0f" text-anchor="end" class="muted">%s</text>`, y+34, row.Previous)
+	}
+
+	y := float64(len(picked))*48 + 12
+	fmt.Fprintf(&svg, `<rect x="150" y="%.0f" width="14" height="14" fill="%s"/><text x="170" y="%.0f">%s</text>`, y, palette[0], y+12, html.EscapeString(monthTitle(r.Month)))
+	fmt.Fprintf(&svg, `<rect x="320" y="%.0f" width="14" height="14" fill="%s"/><text x="340" y="%.0f">%s</text>`, y, previousColor, y+12, html.EscapeString(monthTitle(r.Previous)))
+
+	svg.WriteString(`</svg>`)
+
+	return template.HTML(svg.String())
+}
@@ -0,0 +1,44 @@
+package report
+
+import (
+	_ "embed"
+	"html/template"
+	"io"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+//go:embed month.html
+var monthTemplate string
+
+// htmlTemplate renders the HTML report, styles and charts inline.
+var htmlTemplate = template.Must(template.New("month").Funcs(template.FuncMap{
+	"change":   change,
+	"percent":  percent,
+	"signed":   signedAmount,
+	"negative": func(amount domain.Money) bool { return amount < 0 },
+}).Parse(monthTemplate))
+
+// htmlReport is what the HTML template renders.
+type htmlReport struct {
+	Title           string
+	Previous        string
+	Report          domain.MonthReport
+	Summary         []summaryRow
+	BreakdownChart  template.HTML
+	CategoryChart   template.HTML
+	ComparisonChart template.HTML
+}
+
+// writeHTML writes the report as a single HTML page with SVG charts.
+func writeHTML(w io.Writer, r domain.MonthReport) error {
+	return htmlTemplate.Execute(w, htmlReport{
+		Title:           monthTitle(r.Month),
+		Previous:        monthTitle(r.Previous),
+		Report:          r,
+		Summary:         summaryRows(r),
+		BreakdownChart:  donutChart(breakdown(r)),
+		CategoryChart:   categoryChart(r.Categories),
+		ComparisonChart: comparisonChart(r),
+	})
+}
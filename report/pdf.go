@@ -0,0 +1,205 @@
+package report
+
+import (
+	"io"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Layout of PDF reports, in points.
+const (
+	pdfMargin = 50.0
+	pdfRight  = pageWidth - pdfMargin
+	pdfLine   = 16.0
+	pdfSize   = 10.0
+)
+
+// Colors of PDF text.
+const (
+	textColor  = "#2b2b35"
+	mutedColor = "#777777"
+	titleColor = "#036a66"
+)
+
+// pdfReport lays out a report top to bottom, starting new pages as needed.
+type pdfReport struct {
+	doc pdfDocument
+	y   float64
+}
+
+// writePDF writes the report as a PDF with bar charts.
+func writePDF(w io.Writer, r domain.MonthReport) error {
+	p := pdfReport{}
+	p.newPage()
+
+	title, previous := monthTitle(r.Month), monthTitle(r.Previous)
+
+	p.doc.text(pdfMargin, p.y, 20, true, titleColor, "Expenses of "+title)
+	p.y -= 20
+	p.doc.text(pdfMargin, p.y, pdfSize, false, mutedColor, "Compared with "+previous+". Saved "+percent(r.Month.SavingsRate)+
+		" of the income, "+percent(r.Previous.SavingsRate)+" the month before.")
+	p.y -= 12
+
+	p.heading("Summary")
+	p.row(true, textColor, "", title, previous, "Change")
+	for _, row := range summaryRows(r) {
+		p.row(false, textColor, row.Label, row.Current.String(), row.Previous.String(), change(row.Current, row.Previous))
+	}
+
+	p.y -= 8
+	rows := summaryRows(r)
+	picked := []summaryRow{rows[3], rows[5], rows[0]}
+	max := domain.Money(0)
+	for _, row := range picked {
+		max = largest(max, row.Current, row.Previous)
+	}
+	for _, row := range picked {
+		p.bar(row.Label, row.Current, ratio(row.Current, max), palette[0], 0, "")
+		p.bar("", row.Previous, ratio(row.Previous, max), previousColor, 0, "")
+		p.y -= 4
+	}
+	p.legend(title, previous)
+
+	p.heading("Spending by category")
+	slices := breakdown(r)
+	if len(slices) == 0 {
+		p.note("No spending this month.")
+	}
+	for _, s := range slices {
+		p.bar(s.Label, s.Amount, s.Share, s.Color, 0, percent(s.Share))
+	}
+
+	p.heading("Categories")
+	categories := []domain.CategoryComparison{}
+	max = 0
+	for _, category := range r.Categories {
+		if category.Budget > 0 || category.Spent > 0 {
+			categories = append(categories, category)
+			max = largest(max, category.Budget, category.Spent)
+		}
+	}
+	if len(categories) == 0 {
+		p.note("No budget or spending this month.")
+	}
+	for _, category := range categories {
+		color := palette[0]
+		if category.Budget > 0 && category.Spent > category.Budget {
+			color = overColor
+		}
+		p.bar(category.Category, category.Spent, ratio(category.Spent, max), color, ratio(category.Budget, max), "")
+	}
+	if len(r.Categories) > 0 {
+		p.y -= 8
+		p.ensure(pdfLine * 2)
+		p.categoryRow(true, "Category", "Budget", "Spent", "Remaining", previous, "Change")
+		for _, category := range r.Categories {
+			name := category.Category
+			if category.ParentId != 0 {
+				name = "   " + name
+			}
+			p.categoryRow(false, name, category.Budget.String(), category.Spent.String(), category.Remaining.String(),
+				category.PreviousSpent.String(), change(category.Spent, category.PreviousSpent))
+		}
+	}
+
+	p.heading("Transactions")
+	if len(r.Month.Expenses) == 0 {
+		p.note("No transactions this month.")
+	} else {
+		p.expenseRow(true, "Date", "Name", "Category", "Amount")
+	}
+	for _, expense := range r.Month.Expenses {
+		p.expenseRow(false, expense.Date, expense.Name, expense.Category, signedAmount(expense))
+	}
+
+	return p.doc.write(w)
+}
+
+// newPage starts a page and moves to its top.
+func (p *pdfReport) newPage() {
+	p.doc.addPage()
+	p.y = pageHeight - pdfMargin - 10
+}
+
+// ensure starts a new page unless height fits above the bottom margin.
+func (p *pdfReport) ensure(height float64) {
+	if p.y-height < pdfMargin {
+		p.newPage()
+	}
+}
+
+// heading draws the title of a section with a rule below it.
+func (p *pdfReport) heading(text string) {
+	p.y -= 14
+	p.ensure(pdfLine * 4)
+	p.y -= 14
+	p.doc.text(pdfMargin, p.y, 14, true, textColor, text)
+	p.y -= 6
+	p.doc.rect(pdfMargin, p.y, pdfRight-pdfMargin, 1.5, titleColor)
+	p.y -= pdfLine
+}
+
+// note draws a line of muted text.
+func (p *pdfReport) note(text string) {
+	p.ensure(pdfLine)
+	p.doc.text(pdfMargin, p.y, pdfSize, false, mutedColor, text)
+	p.y -= pdfLine
+}
+
+// row draws a label and three amounts aligned right.
+func (p *pdfReport) row(bold bool, color, label string, amounts ...string) {
+	p.ensure(pdfLine)
+	p.doc.text(pdfMargin, p.y, pdfSize, bold, color, label)
+	for i, amount := range amounts {
+		p.doc.textRight(pdfMargin+240+float64(i)*85, p.y, pdfSize, bold, color, amount)
+	}
+	p.y -= pdfLine
+}
+
+// categoryRow draws a category name and five amounts aligned right.
+func (p *pdfReport) categoryRow(bold bool, name string, amounts ...string) {
+	p.ensure(pdfLine)
+	p.doc.text(pdfMargin, p.y, pdfSize, bold, textColor, fitText(name, 130, pdfSize))
+	for i, amount := range amounts {
+		p.doc.textRight(pdfMargin+195+float64(i)*65, p.y, pdfSize, bold, textColor, fitText(amount, 62, pdfSize))
+	}
+	p.y -= pdfLine
+}
+
+// expenseRow draws the date, name, category and amount of an expense.
+func (p *pdfReport) expenseRow(bold bool, date, name, category, amount string) {
+	p.ensure(pdfLine)
+	p.doc.text(pdfMargin, p.y, pdfSize, bold, textColor, date)
+	p.doc.text(pdfMargin+75, p.y, pdfSize, bold, textColor, fitText(name, 200, pdfSize))
+	p.doc.text(pdfMargin+285, p.y, pdfSize, bold, textColor, fitText(category, 120, pdfSize))
+	p.doc.textRight(pdfRight, p.y, pdfSize, bold, textColor, amount)
+	p.y -= pdfLine
+}
+
+// bar draws a labelled bar of length share, over a lighter one of length
+// background when not 0, followed by the amount and a note.
+func (p *pdfReport) bar(label string, amount domain.Money, share float64, color string, background float64, note string) {
+	const x, width = pdfMargin + 130, 250.0
+
+	p.ensure(pdfLine)
+	p.doc.textRight(x-8, p.y, pdfSize, false, textColor, fitText(label, 120, pdfSize))
+	p.doc.rect(x, p.y-3, background*width, 13, budgetColor)
+	p.doc.rect(x, p.y-1, share*width, 9, color)
+
+	text := amount.String()
+	if note != "" {
+		text += " (" + note + ")"
+	}
+	p.doc.textRight(pdfRight, p.y, pdfSize, false, textColor, text)
+	p.y -= pdfLine
+}
+
+// legend draws the colors of the month and of the month before.
+func (p *pdfReport) legend(current, previous string) {
+	p.ensure(pdfLine)
+	p.doc.rect(pdfMargin+130, p.y-1, 9, 9, palette[0])
+	p.doc.text(pdfMargin+144, p.y, pdfSize, false, textColor, current)
+	p.doc.rect(pdfMargin+260, p.y-1, 9, 9, previousColor)
+	p.doc.text(pdfMargin+274, p.y, pdfSize, false, textColor, previous)
+	p.y -= pdfLine
+}
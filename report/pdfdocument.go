@@ -0,0 +1,202 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Size of A4 pages, in points.
+const (
+	pageWidth  = 595.0
+	pageHeight = 842.0
+)
+
+// helveticaWidths are the widths of the printable ASCII characters in
+// Helvetica, in thousandths of the font size.
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// winAnsi maps the runes of the bytes 0x80 to 0x9F of WinAnsiEncoding,
+// the encoding of the standard PDF fonts, to their byte.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88,
+	'‰': 0x89, 'Š': 0x8A, '‹': 0x8B, 'Œ': 0x8C, 'Ž': 0x8E, '‘': 0x91, '’': 0x92, '“': 0x93,
+	'”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9A, '›': 0x9B,
+	'œ': 0x9C, 'ž': 0x9E, 'Ÿ': 0x9F,
+}
+
+// pdfDocument draws text and rectangles on A4 pages with the standard
+// Helvetica fonts, which PDF readers have without embedding them.
+type pdfDocument struct {
+	pages []*bytes.Buffer
+}
+
+// addPage starts a new page, drawn on from then on.
+func (d *pdfDocument) addPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// page returns the page being drawn on.
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.addPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// text draws text with its baseline starting at x, y from the bottom left.
+func (d *pdfDocument) text(x, y, size float64, bold bool, color, text string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+
+	fmt.Fprintf(d.page(), "BT /%s %s Tf %s rg %s %s Td (%s) Tj ET\n",
+		font, number(size), pdfColor(color), number(x), number(y), escapePDFText(text))
+}
+
+// textRight draws text ending at x.
+func (d *pdfDocument) textRight(x, y, size float64, bold bool, color, text string) {
+	d.text(x-textWidth(text, size), y, size, bold, color, text)
+}
+
+// rect fills a rectangle whose bottom left corner is at x, y.
+func (d *pdfDocument) rect(x, y, width, height float64, color string) {
+	if width <= 0 || height <= 0 {
+		return
+	}
+
+	fmt.Fprintf(d.page(), "%s rg %s %s %s %s re f\n", pdfColor(color), number(x), number(y), number(width), number(height))
+}
+
+// write writes the document as a PDF file.
+func (d *pdfDocument) write(w io.Writer) error {
+	d.page()
+
+	out := &bytes.Buffer{}
+	offsets := []int{}
+	object := func(content string) {
+		offsets = append(offsets, out.Len())
+		fmt.Fprintf(out, "%d 0 obj\n%s\nendobj\n", len(offsets), content)
+	}
+
+	out.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	kids := []string{}
+	for i := range d.pages {
+		kids = append(kids, fmt.Sprintf("%d 0 R", 5+2*i))
+	}
+
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d /MediaBox [0 0 %s %s] >>",
+		strings.Join(kids, " "), len(d.pages), number(pageWidth), number(pageHeight)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, page := range d.pages {
+		compressed := &bytes.Buffer{}
+		writer := zlib.NewWriter(compressed)
+		if _, err := writer.Write(page.Bytes()); err != nil {
+			return err
+		}
+		if err := writer.Close(); err != nil {
+			return err
+		}
+
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", 6+2*i))
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", compressed.Len(), compressed.Bytes()))
+	}
+
+	xref := out.Len()
+	fmt.Fprintf(out, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	_, err := w.Write(out.Bytes())
+	return err
+}
+
+// number formats a coordinate or size with at most two decimals.
+func number(value float64) string {
+	return strconv.FormatFloat(math.Round(value*100)/100, 'f', -1, 64)
+}
+
+// pdfColor returns a color written #rrggbb as PDF color components.
+func pdfColor(color string) string {
+	value, err := strconv.ParseUint(strings.TrimPrefix(color, "#"), 16, 32)
+	if err != nil {
+		return "0 0 0"
+	}
+
+	component := func(shift uint) string {
+		return strconv.FormatFloat(float64(value>>shift&0xff)/255, 'f', 3, 64)
+	}
+
+	return component(16) + " " + component(8) + " " + component(0)
+}
+
+// escapePDFText returns text in WinAnsiEncoding as the content of a PDF
+// string, the characters it cannot write replaced by question marks.
+func escapePDFText(text string) string {
+	escaped := strings.Builder{}
+
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			escaped.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		case winAnsi[r] != 0:
+			fmt.Fprintf(&escaped, "\\%03o", winAnsi[r])
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+
+	return escaped.String()
+}
+
+// textWidth returns the width of text in Helvetica at size, counting
+// characters other than printable ASCII as wide as a digit.
+func textWidth(text string, size float64) float64 {
+	width := 0
+	for _, r := range text {
+		if r >= 0x20 && r < 0x7f {
+			width += helveticaWidths[r-0x20]
+		} else {
+			width += 556
+		}
+	}
+
+	return float64(width) * size / 1000
+}
+
+// fitText returns text shortened with an ellipsis to fit width at size.
+func fitText(text string, width, size float64) string {
+	if textWidth(text, size) <= width {
+		return text
+	}
+
+	runes := []rune(text)
+	for len(runes) > 0 && textWidth(string(runes)+"...", size) > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
@@ -0,0 +1,167 @@
+// Package report renders the report of a month as a self-contained HTML
+// or PDF file, without reaching the network.
+package report
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Formats reports are written in.
+const (
+	HTML = "html"
+	PDF  = "pdf"
+)
+
+// Extensions lists the extensions of report files.
+var Extensions = []string{".html", ".htm", ".pdf"}
+
+// palette colors the categories of charts in turn.
+var palette = []string{"#036a66", "#353571", "#b0774f", "#4f8fb0", "#8a4fb0", "#6a9a3a", "#c9a227", "#b04f6a"}
+
+// Colors of the parts of charts that are not a category.
+const (
+	previousColor = "#a0a0a8"
+	budgetColor   = "#d5d5dc"
+	overColor     = "#b04f4f"
+)
+
+// FormatOf returns the format of the report file at path by its extension.
+func FormatOf(path string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return HTML, true
+	case ".pdf":
+		return PDF, true
+	}
+	return "", false
+}
+
+// Write writes the report of a month to w in format.
+func Write(w io.Writer, r domain.MonthReport, format string) error {
+	var err error
+
+	switch format {
+	case HTML:
+		err = writeHTML(w, r)
+	case PDF:
+		err = writePDF(w, r)
+	default:
+		return fmt.Errorf("Report format %q does not exist.", format)
+	}
+
+	if err != nil {
+		return fmt.Errorf("Could not write %s report: %w", format, err)
+	}
+
+	return nil
+}
+
+// monthTitle returns the name of the month of data, like March 2023.
+func monthTitle(data domain.MonthData) string {
+	return fmt.Sprintf("%s %d", data.Month, data.Year)
+}
+
+// change returns how much an amount changed since the previous month,
+// signed.
+func change(current, previous domain.Money) string {
+	difference := current.Sub(previous)
+	if difference > 0 {
+		return "+" + difference.String()
+	}
+	return difference.String()
+}
+
+// percent returns a rate as a whole percentage.
+func percent(rate float64) string {
+	return fmt.Sprintf("%.0f%%", rate*100)
+}
+
+// summaryRow is a total of the month and of the month before.
+type summaryRow struct {
+	Label    string
+	Current  domain.Money
+	Previous domain.Money
+}
+
+// summaryRows returns the totals of a report.
+func summaryRows(r domain.MonthReport) []summaryRow {
+	return []summaryRow{
+		{"Budget", r.Month.Budget, r.Previous.Budget},
+		{"Base budget", r.Month.BaseBudget, r.Previous.BaseBudget},
+		{"Carried over", r.Month.CarriedOver, r.Previous.CarriedOver},
+		{"Spent", r.Month.TotalSpendings, r.Previous.TotalSpendings},
+		{"Money left", r.Month.MoneyLeft, r.Previous.MoneyLeft},
+		{"Income", r.Month.TotalIncome, r.Previous.TotalIncome},
+		{"Net cash flow", r.Month.NetCashFlow, r.Previous.NetCashFlow},
+	}
+}
+
+// slice is the share of the spending of a month going to a category.
+type slice struct {
+	Label  string
+	Amount domain.Money
+	Share  float64
+	Color  string
+}
+
+// breakdown returns the spending of the top categories of a month, the
+// largest first, their spending including the one of their children.
+func breakdown(r domain.MonthReport) []slice {
+	slices := []slice{}
+	total := domain.Money(0)
+
+	for _, category := range r.Categories {
+		if category.ParentId != 0 || category.Spent <= 0 {
+			continue
+		}
+		slices = append(slices, slice{Label: category.Category, Amount: category.Spent})
+		total = total.Add(category.Spent)
+	}
+
+	sort.SliceStable(slices, func(i, j int) bool {
+		return slices[i].Amount > slices[j].Amount
+	})
+
+	for i := range slices {
+		slices[i].Share = float64(slices[i].Amount) / float64(total)
+		slices[i].Color = palette[i%len(palette)]
+	}
+
+	return slices
+}
+
+// ratio returns amount over max, from 0 to 1.
+func ratio(amount, max domain.Money) float64 {
+	if max <= 0 || amount <= 0 {
+		return 0
+	}
+	if amount > max {
+		return 1
+	}
+	return float64(amount) / float64(max)
+}
+
+// largest returns the largest of amounts, 0 at least.
+func largest(amounts ...domain.Money) domain.Money {
+	max := domain.Money(0)
+	for _, amount := range amounts {
+		if amount > max {
+			max = amount
+		}
+	}
+	return max
+}
+
+// signedAmount returns the amount of an expense, income with a plus sign.
+func signedAmount(expense domain.Expense) string {
+	if expense.IsIncome() {
+		return "+" + expense.Amount.String()
+	}
+	return expense.Amount.String()
+}
@@ -0,0 +1,182 @@
+package report
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// evilName would break out of HTML, SVG and PDF strings if not escaped.
+const evilName = `<script>alert("x")</script> & (Co) \ Zoë's`
+
+// testReport returns the report of a month whose categories and expenses
+// are named evilName, with enough expenses to fill several PDF pages.
+func testReport() domain.MonthReport {
+	month := domain.MonthData{
+		Year:           2023,
+		Month:          time.March,
+		Budget:         100000,
+		BaseBudget:     100000,
+		TotalSpendings: 60000,
+		MoneyLeft:      40000,
+		TotalIncome:    250000,
+		NetCashFlow:    190000,
+		SavingsRate:    0.76,
+		Categories: []domain.CategoryData{
+			{CategoryId: 1, Category: evilName, Budget: 50000, Spent: 45000, Remaining: 5000},
+			{Category: "Uncategorized", Spent: 15000, Remaining: -15000},
+		},
+	}
+
+	for i := 0; i < 120; i++ {
+		month.Expenses = append(month.Expenses, domain.Expense{
+			Id:         i + 1,
+			Name:       fmt.Sprintf("%s %d", evilName, i),
+			Date:       "2023-03-01",
+			Amount:     500,
+			Kind:       domain.KindExpense,
+			CategoryId: 1,
+			Category:   evilName,
+		})
+	}
+
+	return domain.MonthReport{
+		Month:    month,
+		Previous: domain.MonthData{Year: 2023, Month: time.February, Budget: 100000, TotalSpendings: 80000},
+		Categories: []domain.CategoryComparison{
+			{CategoryId: 1, Category: evilName, Budget: 50000, Spent: 45000, Remaining: 5000, PreviousSpent: 70000},
+			{CategoryId: 2, Category: "<b>child</b>", ParentId: 1, Spent: 1000, Remaining: -1000},
+			{Category: "Uncategorized", Spent: 15000, Remaining: -15000, PreviousSpent: 10000},
+		},
+	}
+}
+
+func TestWriteHTMLEscapes(t *testing.T) {
+	out := bytes.Buffer{}
+	if err := Write(&out, testReport(), HTML); err != nil {
+		t.Fatal(err)
+	}
+
+	page := out.String()
+	for _, raw := range []string{"<script>", "</script>", "<b>child", `alert("x")`} {
+		if strings.Contains(page, raw) {
+			t.Errorf("report holds %q unescaped", raw)
+		}
+	}
+
+	start, end := strings.Index(page, "<svg"), strings.LastIndex(page, "</svg>")
+	if start == -1 || end < start {
+		t.Fatal("report has no chart")
+	}
+
+	if !strings.Contains(page[start:end], "&lt;script&gt;") {
+		t.Error("charts do not hold the escaped category name")
+	}
+}
+
+func TestWritePDF(t *testing.T) {
+	out := bytes.Buffer{}
+	if err := Write(&out, testReport(), PDF); err != nil {
+		t.Fatal(err)
+	}
+
+	pages := checkPDF(t, out.Bytes())
+	if len(pages) < 3 {
+		t.Fatalf("got %d pages, want the expenses to go on other pages", len(pages))
+	}
+
+	content := strings.Join(pages, "")
+	if !strings.Contains(content, `(<script>alert\("x"\)</script> & \(Co\) \\ Zo\353's 0) Tj`) {
+		t.Error("PDF does not hold the escaped name of the first expense")
+	}
+}
+
+func TestWriteUnknownFormat(t *testing.T) {
+	if err := Write(io.Discard, testReport(), "docx"); err == nil {
+		t.Error("wrote a report in an unknown format")
+	}
+}
+
+var (
+	objectPattern = regexp.MustCompile(`^(\d+) 0 obj\n`)
+	streamPattern = regexp.MustCompile(`(?s)<< /Length (\d+) /Filter /FlateDecode >>\nstream\n`)
+)
+
+// checkPDF checks that the xref table of a PDF file points at its
+// objects and that its streams have the length they tell, and returns
+// the decompressed content of its pages.
+func checkPDF(t *testing.T, file []byte) []string {
+	t.Helper()
+
+	if !bytes.HasPrefix(file, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(file, []byte("%%EOF\n")) {
+		t.Fatal("PDF has no header or end of file marker")
+	}
+
+	trailer := file[bytes.LastIndex(file, []byte("startxref\n"))+len("startxref\n"):]
+	xref, err := strconv.Atoi(string(trailer[:bytes.IndexByte(trailer, '\n')]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(file[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+
+	lines := strings.Split(string(file[xref:]), "\n")
+	var first, count int
+	if _, err := fmt.Sscanf(lines[1], "%d %d", &first, &count); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i < count; i++ {
+		offset, err := strconv.Atoi(lines[2+i][:10])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		match := objectPattern.FindSubmatch(file[offset:])
+		if match == nil || string(match[1]) != strconv.Itoa(i) {
+			t.Fatalf("xref entry %d at %d does not point at its object", i, offset)
+		}
+	}
+
+	if !strings.Contains(string(file[xref:]), fmt.Sprintf("/Size %d /Root 1 0 R", count)) {
+		t.Error("trailer does not tell the size of the xref table")
+	}
+
+	pages := []string{}
+	for _, match := range streamPattern.FindAllSubmatchIndex(file, -1) {
+		length, err := strconv.Atoi(string(file[match[2]:match[3]]))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stream := file[match[1] : match[1]+length]
+		if !bytes.HasPrefix(file[match[1]+length:], []byte("\nendstream")) {
+			t.Fatalf("stream at %d is not %d bytes long", match[1], length)
+		}
+
+		reader, err := zlib.NewReader(bytes.NewReader(stream))
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := io.ReadAll(reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pages = append(pages, string(content))
+	}
+
+	if !strings.Contains(string(file), fmt.Sprintf("/Count %d ", len(pages))) {
+		t.Errorf("page tree does not count the %d pages", len(pages))
+	}
+
+	return pages
+}
@@ -0,0 +1,1067 @@
+package storagetest
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// Run runs the conformance suite against the domain.Storage implementation
+// create returns. Every test gets a new, empty storage from create.
+func Run(t *testing.T, create func(t *testing.T) domain.Storage) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, s domain.Storage)
+	}{
+		{"InsertExpense", testInsertExpense},
+		{"InsertExpenses", testInsertExpenses},
+		{"ImportIds", testImportIds},
+		{"UpdateExpense", testUpdateExpense},
+		{"DeleteExpense", testDeleteExpense},
+		{"PurgeExpense", testPurgeExpense},
+		{"DiscardExpenses", testDiscardExpenses},
+		{"DefaultBudget", testDefaultBudget},
+		{"MonthBudgets", testMonthBudgets},
+		{"MonthFiltering", testMonthFiltering},
+		{"FirstExpenseDate", testFirstExpenseDate},
+		{"Categories", testCategories},
+		{"DeleteCategory", testDeleteCategory},
+		{"CategoryBudgets", testCategoryBudgets},
+		{"Accounts", testAccounts},
+		{"RecurringRules", testRecurringRules},
+		{"Attachments", testAttachments},
+		{"Payees", testPayees},
+		{"CategorizationRules", testCategorizationRules},
+		{"Settings", testSettings},
+		{"AuditLog", testAuditLog},
+	}
+
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.test(t, create(t))
+		})
+	}
+}
+
+// check fails the test right away on an unexpected error.
+func check(t *testing.T, err error) {
+	t.Helper()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
+
+// insertExpense inserts an expense and returns its id.
+func insertExpense(t *testing.T, s domain.Storage, expense domain.Expense) int {
+	t.Helper()
+	if expense.Kind == "" {
+		expense.Kind = domain.KindExpense
+	}
+	id, err := s.InsertExpense(expense)
+	check(t, err)
+	return id
+}
+
+// getExpense returns the expense with an id, failing if it does not exist.
+func getExpense(t *testing.T, s domain.Storage, id int) domain.Expense {
+	t.Helper()
+	expense, ok, err := s.GetExpenseWithId(id)
+	check(t, err)
+	if !ok {
+		t.Fatalf("expense %d not found", id)
+	}
+	return expense
+}
+
+// insertCategory inserts a category named name and returns its id.
+func insertCategory(t *testing.T, s domain.Storage, name string) int {
+	t.Helper()
+	id, err := s.InsertCategory(domain.Category{Name: name})
+	check(t, err)
+	return id
+}
+
+// expenseNames returns the names of a list of expenses, in order.
+func expenseNames(list []domain.Expense) []string {
+	names := []string{}
+	for _, expense := range list {
+		names = append(names, expense.Name)
+	}
+	return names
+}
+
+// expectNames fails when the expenses are not named names, in order.
+func expectNames(t *testing.T, list []domain.Expense, names ...string) {
+	t.Helper()
+	if got := expenseNames(list); !reflect.DeepEqual(got, append([]string{}, names...)) {
+		t.Errorf("got expenses %q, want %q", got, names)
+	}
+}
+
+func testInsertExpense(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+	grocery := insertCategory(t, s, "Grocery")
+	payee, err := s.InsertPayee(domain.Payee{Name: "Market"})
+	check(t, err)
+
+	id := insertExpense(t, s, domain.Expense{
+		Id:         999,
+		Name:       "groceries",
+		Date:       "2023-02-10",
+		Amount:     4250,
+		CategoryId: food,
+		AccountId:  1,
+		PayeeId:    payee,
+		Tags:       []string{"weekly", "shop"},
+		Splits: []domain.Split{
+			{CategoryId: food, Amount: 3000, Note: "meals"},
+			{CategoryId: grocery, Amount: 1250},
+		},
+	})
+	other := insertExpense(t, s, domain.Expense{Name: "bus", Date: "2023-02-11", Amount: 200})
+
+	if id == 0 || id == 999 || other == id {
+		t.Fatalf("got ids %d and %d, want new distinct ids", id, other)
+	}
+
+	expense := getExpense(t, s, id)
+	if expense.Id != id || expense.Name != "groceries" || expense.Date != "2023-02-10" || expense.Amount != 4250 {
+		t.Errorf("got %+v", expense)
+	}
+	if expense.Kind != domain.KindExpense || expense.DeletedAt != "" {
+		t.Errorf("got kind %q deleted at %q", expense.Kind, expense.DeletedAt)
+	}
+	if expense.Category != "Food" || expense.CategoryId != food {
+		t.Errorf("got category %q (%d), want Food (%d)", expense.Category, expense.CategoryId, food)
+	}
+	if expense.Account != "Main" || expense.AccountId != 1 {
+		t.Errorf("got account %q (%d), want Main (1)", expense.Account, expense.AccountId)
+	}
+	if expense.Payee != "Market" || expense.PayeeId != payee {
+		t.Errorf("got payee %q (%d), want Market (%d)", expense.Payee, expense.PayeeId, payee)
+	}
+	if !reflect.DeepEqual(expense.Tags, []string{"weekly", "shop"}) {
+		t.Errorf("got tags %q", expense.Tags)
+	}
+
+	if len(expense.Splits) != 2 {
+		t.Fatalf("got %d splits, want 2", len(expense.Splits))
+	}
+	first, second := expense.Splits[0], expense.Splits[1]
+	if first.Id == 0 || first.Id == second.Id {
+		t.Errorf("got split ids %d and %d, want new distinct ids", first.Id, second.Id)
+	}
+	if first.Category != "Food" || first.Amount != 3000 || first.Note != "meals" {
+		t.Errorf("got first split %+v", first)
+	}
+	if second.Category != "Grocery" || second.CategoryId != grocery || second.Amount != 1250 {
+		t.Errorf("got second split %+v", second)
+	}
+
+	bus := getExpense(t, s, other)
+	if bus.Category != "" || bus.CategoryId != 0 || bus.Account != "" || len(bus.Splits) != 0 {
+		t.Errorf("got %+v, want no category, account or split", bus)
+	}
+	if bus.Tags == nil || len(bus.Tags) != 0 {
+		t.Errorf("got tags %#v, want an empty list", bus.Tags)
+	}
+
+	if _, ok, err := s.GetExpenseWithId(other + 100); err != nil || ok {
+		t.Errorf("got ok %v error %v for a missing expense, want false and no error", ok, err)
+	}
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "groceries", "bus")
+}
+
+func testInsertExpenses(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+
+	ids, err := s.InsertExpenses([]domain.Expense{
+		{Name: "bread", Date: "2023-03-01", Amount: 300, Kind: domain.KindExpense, CategoryId: food},
+		{Name: "salary", Date: "2023-03-02", Amount: 200000, Kind: domain.KindIncome, AccountId: 1},
+		{Name: "rent", Date: "2023-03-03", Amount: 90000, Kind: domain.KindExpense, RuleId: 7},
+	})
+	check(t, err)
+
+	if len(ids) != 3 || ids[0] == ids[1] || ids[1] == ids[2] || ids[0] == ids[2] {
+		t.Fatalf("got ids %v, want 3 distinct ids", ids)
+	}
+	if expense := getExpense(t, s, ids[1]); expense.Name != "salary" || expense.Kind != domain.KindIncome {
+		t.Errorf("got %+v", expense)
+	}
+
+	// The rule already has an expense on that date: nothing is inserted.
+	_, err = s.InsertExpenses([]domain.Expense{
+		{Name: "milk", Date: "2023-03-04", Amount: 150, Kind: domain.KindExpense},
+		{Name: "rent", Date: "2023-03-03", Amount: 90000, Kind: domain.KindExpense, RuleId: 7},
+	})
+	if err == nil {
+		t.Fatal("inserted an occurrence twice")
+	}
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "bread", "salary", "rent")
+}
+
+func testImportIds(t *testing.T, s domain.Storage) {
+	id := insertExpense(t, s, domain.Expense{Name: "coffee", Date: "2023-03-01", Amount: 300, ImportId: "ofx:1:42"})
+	insertExpense(t, s, domain.Expense{Name: "tea", Date: "2023-03-01", Amount: 250})
+
+	expense, ok, err := s.GetExpenseWithImportId("ofx:1:42")
+	check(t, err)
+	if !ok || expense.Id != id || expense.ImportId != "ofx:1:42" {
+		t.Fatalf("got %+v (%v), want expense %d", expense, ok, id)
+	}
+
+	if _, ok, err := s.GetExpenseWithImportId(""); err != nil || ok {
+		t.Errorf("found an expense without import id: %v", err)
+	}
+
+	if _, err := s.InsertExpense(domain.Expense{Name: "coffee", Date: "2023-03-01", Amount: 300, Kind: domain.KindExpense, ImportId: "ofx:1:42"}); err == nil {
+		t.Error("inserted an import id twice")
+	}
+
+	_, err = s.InsertExpenses([]domain.Expense{
+		{Name: "bus", Date: "2023-03-02", Amount: 200, Kind: domain.KindExpense, ImportId: "hash:1"},
+		{Name: "bus", Date: "2023-03-02", Amount: 200, Kind: domain.KindExpense, ImportId: "hash:1"},
+	})
+	if err == nil {
+		t.Error("inserted an import id twice at once")
+	}
+
+	expense.Name = "espresso"
+	expense.ImportId = ""
+	check(t, s.UpdateExpense(expense))
+	check(t, s.DeleteExpense(id, "2023-03-05T10:00:00Z"))
+
+	expense, ok, err = s.GetExpenseWithImportId("ofx:1:42")
+	check(t, err)
+	if !ok || expense.Name != "espresso" || expense.DeletedAt == "" {
+		t.Errorf("got %+v (%v), want the updated expense in the trash", expense, ok)
+	}
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "tea")
+
+	if purged, err := s.IsImportIdPurged("ofx:1:42"); err != nil || purged {
+		t.Errorf("import id purged before its expense: %v", err)
+	}
+
+	check(t, s.PurgeExpense(id))
+
+	if _, ok, err := s.GetExpenseWithImportId("ofx:1:42"); err != nil || ok {
+		t.Errorf("found a purged expense: %v", err)
+	}
+
+	if purged, err := s.IsImportIdPurged("ofx:1:42"); err != nil || !purged {
+		t.Errorf("import id of a purged expense forgotten: %v", err)
+	}
+}
+
+func testUpdateExpense(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+	id := insertExpense(t, s, domain.Expense{
+		Name:   "lunch",
+		Date:   "2023-02-10",
+		Amount: 1200,
+		Splits: []domain.Split{{CategoryId: food, Amount: 1200}},
+	})
+
+	err := s.UpdateExpense(domain.Expense{
+		Id:         id,
+		Name:       "dinner",
+		Date:       "2023-03-01",
+		Amount:     3000,
+		CategoryId: food,
+		Kind:       domain.KindExpense,
+		Tags:       []string{"out"},
+	})
+	check(t, err)
+
+	expense := getExpense(t, s, id)
+	if expense.Name != "dinner" || expense.Date != "2023-03-01" || expense.Amount != 3000 || expense.Category != "Food" {
+		t.Errorf("got %+v", expense)
+	}
+	if len(expense.Splits) != 0 {
+		t.Errorf("got %d splits, want the split lines replaced by none", len(expense.Splits))
+	}
+	if !reflect.DeepEqual(expense.Tags, []string{"out"}) {
+		t.Errorf("got tags %q", expense.Tags)
+	}
+
+	list, err := s.GetExpensesInRange("2023-02-01", "2023-02-28")
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	if err := s.UpdateExpense(domain.Expense{Id: id + 100, Name: "ghost"}); err == nil {
+		t.Error("updating a missing expense should fail")
+	}
+}
+
+func testDeleteExpense(t *testing.T, s domain.Storage) {
+	first := insertExpense(t, s, domain.Expense{Name: "first", Date: "2023-02-01", Amount: 100, AccountId: 1})
+	second := insertExpense(t, s, domain.Expense{Name: "second", Date: "2023-02-02", Amount: 200, AccountId: 1})
+	third := insertExpense(t, s, domain.Expense{Name: "third", Date: "2023-02-03", Amount: 300, AccountId: 1})
+
+	check(t, s.DeleteExpense(first, "2023-02-05T10:00:00Z"))
+	check(t, s.DeleteExpense(third, "2023-02-05T10:00:00Z"))
+	check(t, s.DeleteExpense(second, "2023-02-04T10:00:00Z"))
+
+	if err := s.DeleteExpense(first, "2023-02-06T10:00:00Z"); err == nil {
+		t.Error("deleting an expense already in the trash should fail")
+	}
+	if err := s.DeleteExpense(third+100, "2023-02-06T10:00:00Z"); err == nil {
+		t.Error("deleting a missing expense should fail")
+	}
+
+	expense := getExpense(t, s, first)
+	if expense.DeletedAt != "2023-02-05T10:00:00Z" {
+		t.Errorf("got deleted at %q, want the first deletion", expense.DeletedAt)
+	}
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	list, err = s.GetExpensesInRange("2023-02-01", "2023-02-28")
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	list, err = s.GetAccountTransactions(1)
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	if _, ok, err := s.GetFirstExpenseDate(); err != nil || ok {
+		t.Errorf("got ok %v error %v with every expense in the trash, want false and no error", ok, err)
+	}
+
+	trash, err := s.GetDeletedExpenses()
+	check(t, err)
+	expectNames(t, trash, "third", "first", "second")
+
+	check(t, s.RestoreExpense(second))
+	if err := s.RestoreExpense(second); err == nil {
+		t.Error("restoring an expense not in the trash should fail")
+	}
+
+	list, err = s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "second")
+	if list[0].DeletedAt != "" {
+		t.Errorf("got deleted at %q for a restored expense", list[0].DeletedAt)
+	}
+
+	trash, err = s.GetDeletedExpenses()
+	check(t, err)
+	expectNames(t, trash, "third", "first")
+}
+
+func testPurgeExpense(t *testing.T, s domain.Storage) {
+	kept := insertExpense(t, s, domain.Expense{Name: "kept", Date: "2023-02-01", Amount: 100})
+	purged := insertExpense(t, s, domain.Expense{
+		Name:   "purged",
+		Date:   "2023-02-02",
+		Amount: 200,
+		Splits: []domain.Split{{Amount: 200}},
+	})
+
+	if err := s.PurgeExpense(purged); err == nil {
+		t.Error("purging an expense not in the trash should fail")
+	}
+
+	check(t, s.DeleteExpense(purged, "2023-02-05T10:00:00Z"))
+	check(t, s.PurgeExpense(purged))
+
+	if _, ok, err := s.GetExpenseWithId(purged); err != nil || ok {
+		t.Errorf("got ok %v error %v for a purged expense, want false and no error", ok, err)
+	}
+	if err := s.PurgeExpense(purged); err == nil {
+		t.Error("purging a purged expense should fail")
+	}
+	if err := s.RestoreExpense(purged); err == nil {
+		t.Error("restoring a purged expense should fail")
+	}
+
+	trash, err := s.GetDeletedExpenses()
+	check(t, err)
+	expectNames(t, trash, []string{}...)
+
+	getExpense(t, s, kept)
+}
+
+func testDiscardExpenses(t *testing.T, s domain.Storage) {
+	kept := insertExpense(t, s, domain.Expense{Name: "kept", Date: "2023-02-01", Amount: 100})
+	ids, err := s.InsertExpenses([]domain.Expense{
+		{Name: "first", Date: "2023-02-02", Amount: 200, Kind: domain.KindExpense, ImportId: "hash:1", Splits: []domain.Split{{Amount: 200}}},
+		{Name: "second", Date: "2023-02-03", Amount: 300, Kind: domain.KindExpense, ImportId: "hash:2"},
+	})
+	check(t, err)
+
+	check(t, s.DiscardExpenses(ids))
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "kept")
+
+	if purged, err := s.IsImportIdPurged("hash:1"); err != nil || purged {
+		t.Errorf("import id of a discarded expense kept as purged: %v", err)
+	}
+	if err := s.DiscardExpenses(ids); err == nil {
+		t.Error("discarding discarded expenses should fail")
+	}
+
+	// Their import ids are free again.
+	insertExpense(t, s, domain.Expense{Name: "first", Date: "2023-02-02", Amount: 200, ImportId: "hash:1"})
+	getExpense(t, s, kept)
+}
+
+func testDefaultBudget(t *testing.T, s domain.Storage) {
+	budget, err := s.GetDefaultBudget()
+	check(t, err)
+	if budget != 0 {
+		t.Errorf("got default budget %s, want 0.00", budget)
+	}
+
+	check(t, s.UpdateDefaultBudget(150000))
+	budget, err = s.GetDefaultBudget()
+	check(t, err)
+	if budget != 150000 {
+		t.Errorf("got default budget %s, want 1500.00", budget)
+	}
+
+	check(t, s.DeleteBudget("default"))
+	budget, err = s.GetDefaultBudget()
+	check(t, err)
+	if budget != 150000 {
+		t.Errorf("got default budget %s after deleting it, want it kept", budget)
+	}
+
+	if _, ok, err := s.GetBudgetWithYearMonth("2023-02"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a month without budget, want false and no error", ok, err)
+	}
+}
+
+func testMonthBudgets(t *testing.T, s domain.Storage) {
+	check(t, s.InsertBudget(100000, "2023-02"))
+	check(t, s.InsertBudget(120000, "2023-02"))
+	check(t, s.InsertBudget(90000, "2023-03"))
+
+	budget, ok, err := s.GetBudgetWithYearMonth("2023-02")
+	check(t, err)
+	if !ok || budget != 120000 {
+		t.Errorf("got %s (%v), want the replaced budget 1200.00", budget, ok)
+	}
+
+	check(t, s.DeleteBudget("2023-02"))
+	check(t, s.DeleteBudget("2023-04"))
+
+	if _, ok, err := s.GetBudgetWithYearMonth("2023-02"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a deleted budget, want false and no error", ok, err)
+	}
+
+	budget, ok, err = s.GetBudgetWithYearMonth("2023-03")
+	check(t, err)
+	if !ok || budget != 90000 {
+		t.Errorf("got %s (%v), want 900.00", budget, ok)
+	}
+
+	budget, err = s.GetDefaultBudget()
+	check(t, err)
+	if budget != 0 {
+		t.Errorf("got default budget %s, want it untouched by month budgets", budget)
+	}
+}
+
+func testMonthFiltering(t *testing.T, s domain.Storage) {
+	insertExpense(t, s, domain.Expense{Name: "march", Date: "2023-03-01", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "end of february", Date: "2023-02-28", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "february", Date: "2023-02", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "first of february", Date: "2023-02-01", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "january", Date: "2023-01-31", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "last year", Date: "2022-02-15", Amount: 100})
+
+	list, err := s.GetExpensesInRange("2023-02-01", "2023-02-28")
+	check(t, err)
+	expectNames(t, list, "february", "first of february", "end of february")
+
+	list, err = s.GetExpensesInRange("2023-02-02", "2023-02-28")
+	check(t, err)
+	expectNames(t, list, "end of february")
+
+	list, err = s.GetExpensesInRange("2023-02", "2023-02")
+	check(t, err)
+	expectNames(t, list, "february", "first of february")
+
+	list, err = s.GetExpensesInRange("2023-01-31", "2023-03-01")
+	check(t, err)
+	expectNames(t, list, "january", "february", "first of february", "end of february", "march")
+
+	list, err = s.GetExpensesInRange("2023-04-01", "2023-04-30")
+	check(t, err)
+	if list == nil || len(list) != 0 {
+		t.Errorf("got %#v for a month without expenses, want an empty list", list)
+	}
+
+	list, err = s.GetExpensesInRange("2023-03-01", "2023-02-01")
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	list, err = s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "last year", "january", "february", "first of february", "end of february", "march")
+}
+
+func testFirstExpenseDate(t *testing.T, s domain.Storage) {
+	if _, ok, err := s.GetFirstExpenseDate(); err != nil || ok {
+		t.Errorf("got ok %v error %v without expenses, want false and no error", ok, err)
+	}
+
+	insertExpense(t, s, domain.Expense{Name: "later", Date: "2023-02-10", Amount: 100})
+	insertExpense(t, s, domain.Expense{Name: "month only", Date: "2023-01", Amount: 100})
+
+	date, ok, err := s.GetFirstExpenseDate()
+	check(t, err)
+	if !ok || date != "2023-01-01" {
+		t.Errorf("got %q (%v), want 2023-01-01", date, ok)
+	}
+}
+
+func testCategories(t *testing.T, s domain.Storage) {
+	transport := insertCategory(t, s, "transport")
+	food, err := s.InsertCategory(domain.Category{Name: "Food", Color: "#ff0000", Icon: "fork"})
+	check(t, err)
+	fruit, err := s.InsertCategory(domain.Category{Name: "Fruit", ParentId: food, Archived: true})
+	check(t, err)
+
+	if _, err := s.InsertCategory(domain.Category{Name: "FOOD"}); err == nil {
+		t.Error("inserting a category with a taken name should fail")
+	}
+
+	categories, err := s.GetCategories()
+	check(t, err)
+	want := []domain.Category{
+		{Id: food, Name: "Food", Color: "#ff0000", Icon: "fork"},
+		{Id: fruit, Name: "Fruit", ParentId: food, Archived: true},
+		{Id: transport, Name: "transport"},
+	}
+	if !reflect.DeepEqual(categories, want) {
+		t.Errorf("got categories %+v, want %+v", categories, want)
+	}
+
+	category, ok, err := s.GetCategoryWithName("fRUIT")
+	check(t, err)
+	if !ok || category.Id != fruit {
+		t.Errorf("got %+v (%v), want Fruit", category, ok)
+	}
+
+	if _, ok, err := s.GetCategoryWithName("rent"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a missing category, want false and no error", ok, err)
+	}
+
+	check(t, s.UpdateCategory(domain.Category{Id: transport, Name: "Travel"}))
+	category, ok, err = s.GetCategoryWithName("travel")
+	check(t, err)
+	if !ok || category.Id != transport {
+		t.Errorf("got %+v (%v), want the renamed category", category, ok)
+	}
+
+	if err := s.UpdateCategory(domain.Category{Id: transport, Name: "food"}); err == nil {
+		t.Error("renaming a category to a taken name should fail")
+	}
+	if err := s.UpdateCategory(domain.Category{Id: fruit + 100, Name: "Rent"}); err == nil {
+		t.Error("updating a missing category should fail")
+	}
+}
+
+func testDeleteCategory(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+	other := insertCategory(t, s, "Other")
+	fruit, err := s.InsertCategory(domain.Category{Name: "Fruit", ParentId: food})
+	check(t, err)
+
+	id := insertExpense(t, s, domain.Expense{
+		Name:       "market",
+		Date:       "2023-02-10",
+		Amount:     1000,
+		CategoryId: food,
+		Splits:     []domain.Split{{CategoryId: food, Amount: 600}, {CategoryId: other, Amount: 400}},
+	})
+	check(t, s.InsertCategoryBudget(food, 5000, "2023-02"))
+	check(t, s.InsertCategoryBudget(other, 2000, "2023-02"))
+	check(t, s.InsertCategorizationRule(domain.CategorizationRule{
+		Priority:   1,
+		MatchType:  domain.MatchExact,
+		Pattern:    "market",
+		CategoryId: food,
+	}))
+	check(t, s.InsertRecurringRule(domain.RecurringRule{
+		Name:       "market",
+		Amount:     1000,
+		CategoryId: food,
+		Interval:   domain.Monthly,
+		Every:      1,
+		StartDate:  "2023-02-10",
+	}))
+
+	check(t, s.DeleteCategory(food))
+
+	expense := getExpense(t, s, id)
+	if expense.CategoryId != 0 || expense.Category != "" {
+		t.Errorf("got category %q (%d), want none", expense.Category, expense.CategoryId)
+	}
+	if expense.Splits[0].CategoryId != 0 || expense.Splits[1].Category != "Other" {
+		t.Errorf("got splits %+v, want the first one without category", expense.Splits)
+	}
+
+	budgets, err := s.GetCategoryBudgets("2023-02")
+	check(t, err)
+	if !reflect.DeepEqual(budgets, map[int]domain.Money{other: 2000}) {
+		t.Errorf("got budgets %v, want only the budget of Other", budgets)
+	}
+
+	category, ok, err := s.GetCategoryWithName("Fruit")
+	check(t, err)
+	if !ok || category.Id != fruit || category.ParentId != 0 {
+		t.Errorf("got %+v (%v), want Fruit as a top level category", category, ok)
+	}
+
+	rules, err := s.GetCategorizationRules()
+	check(t, err)
+	if len(rules) != 1 || rules[0].CategoryId != 0 || rules[0].Category != "" {
+		t.Errorf("got rules %+v, want one rule without category", rules)
+	}
+
+	if _, ok, err := s.GetCategoryWithName("Food"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a deleted category, want false and no error", ok, err)
+	}
+
+	// A category created next, maybe with the freed id, gets nothing.
+	insertCategory(t, s, "Gifts")
+
+	recurringRules, err := s.GetRecurringRules()
+	check(t, err)
+	if len(recurringRules) != 1 || recurringRules[0].CategoryId != 0 || recurringRules[0].Category != "" {
+		t.Errorf("got recurring rules %+v, want one rule without category", recurringRules)
+	}
+
+	expense = getExpense(t, s, id)
+	if expense.CategoryId != 0 || expense.Category != "" {
+		t.Errorf("got category %q (%d) after adding a category, want none", expense.Category, expense.CategoryId)
+	}
+}
+
+func testCategoryBudgets(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+	rent := insertCategory(t, s, "Rent")
+
+	budgets, err := s.GetCategoryBudgets("2023-02")
+	check(t, err)
+	if budgets == nil || len(budgets) != 0 {
+		t.Errorf("got %#v without budgets, want an empty map", budgets)
+	}
+
+	check(t, s.InsertCategoryBudget(food, 5000, "2023-02"))
+	check(t, s.InsertCategoryBudget(food, 6000, "2023-02"))
+	check(t, s.InsertCategoryBudget(rent, 90000, "2023-02"))
+	check(t, s.InsertCategoryBudget(food, 4000, "default"))
+
+	budgets, err = s.GetCategoryBudgets("2023-02")
+	check(t, err)
+	if !reflect.DeepEqual(budgets, map[int]domain.Money{food: 6000, rent: 90000}) {
+		t.Errorf("got budgets %v", budgets)
+	}
+
+	budgets, err = s.GetCategoryBudgets("default")
+	check(t, err)
+	if !reflect.DeepEqual(budgets, map[int]domain.Money{food: 4000}) {
+		t.Errorf("got default budgets %v", budgets)
+	}
+
+	check(t, s.DeleteCategoryBudget(food, "2023-02"))
+	check(t, s.DeleteCategoryBudget(food, "2023-03"))
+
+	budgets, err = s.GetCategoryBudgets("2023-02")
+	check(t, err)
+	if !reflect.DeepEqual(budgets, map[int]domain.Money{rent: 90000}) {
+		t.Errorf("got budgets %v after delete", budgets)
+	}
+}
+
+func testAccounts(t *testing.T, s domain.Storage) {
+	accounts, err := s.GetAccounts()
+	check(t, err)
+	if !reflect.DeepEqual(accounts, []domain.Account{{Id: 1, Name: "Main", Type: domain.Checking}}) {
+		t.Fatalf("got accounts %+v, want the Main checking account", accounts)
+	}
+
+	check(t, s.InsertAccount(domain.Account{Name: "wallet", Type: domain.Cash, OpeningBalance: 5000}))
+	if err := s.InsertAccount(domain.Account{Name: "MAIN", Type: domain.Savings}); err == nil {
+		t.Error("inserting an account with a taken name should fail")
+	}
+
+	accounts, err = s.GetAccounts()
+	check(t, err)
+	if len(accounts) != 2 || accounts[1].Name != "wallet" || accounts[1].OpeningBalance != 5000 {
+		t.Fatalf("got accounts %+v", accounts)
+	}
+	wallet := accounts[1].Id
+
+	insertExpense(t, s, domain.Expense{Name: "coffee", Date: "2023-02-02", Amount: 300, AccountId: wallet})
+	insertExpense(t, s, domain.Expense{Name: "rent", Date: "2023-02-01", Amount: 90000, AccountId: 1})
+	transfer := insertExpense(t, s, domain.Expense{
+		Name:        "cash",
+		Date:        "2023-02-03",
+		Amount:      2000,
+		Kind:        domain.KindTransfer,
+		AccountId:   1,
+		ToAccountId: wallet,
+	})
+	insertExpense(t, s, domain.Expense{Name: "no account", Date: "2023-02-04", Amount: 100})
+
+	list, err := s.GetAccountTransactions(wallet)
+	check(t, err)
+	expectNames(t, list, "coffee", "cash")
+
+	list, err = s.GetAccountTransactions(0)
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	check(t, s.UpdateAccount(domain.Account{Id: wallet, Name: "Pocket", Type: domain.Cash}))
+	if getExpense(t, s, transfer).ToAccount != "Pocket" {
+		t.Error("a renamed account should be renamed in its transactions")
+	}
+	if err := s.UpdateAccount(domain.Account{Id: wallet + 100, Name: "Ghost", Type: domain.Cash}); err == nil {
+		t.Error("updating a missing account should fail")
+	}
+	if err := s.UpdateAccount(domain.Account{Id: wallet, Name: "main", Type: domain.Cash}); err == nil {
+		t.Error("renaming an account to a taken name should fail")
+	}
+
+	check(t, s.DeleteAccount(wallet))
+
+	expense := getExpense(t, s, transfer)
+	if expense.Kind != domain.KindExpense || expense.ToAccountId != 0 || expense.AccountId != 1 {
+		t.Errorf("got %+v, want a plain expense of Main", expense)
+	}
+
+	list, err = s.GetAccountTransactions(wallet)
+	check(t, err)
+	expectNames(t, list, []string{}...)
+
+	list, err = s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "rent", "coffee", "cash", "no account")
+	if list[1].AccountId != 0 || list[1].Account != "" {
+		t.Errorf("got account %q (%d), want none", list[1].Account, list[1].AccountId)
+	}
+}
+
+func testRecurringRules(t *testing.T, s domain.Storage) {
+	rent := insertCategory(t, s, "Rent")
+
+	check(t, s.InsertRecurringRule(domain.RecurringRule{Name: "streaming", Amount: 1000, Interval: domain.Monthly, Every: 1, StartDate: "2023-01-05"}))
+	check(t, s.InsertRecurringRule(domain.RecurringRule{
+		Name:       "Rent",
+		Amount:     90000,
+		CategoryId: rent,
+		Interval:   domain.Monthly,
+		Every:      1,
+		DayOfMonth: 1,
+		StartDate:  "2023-01-01",
+		EndDate:    "2023-12-31",
+		Count:      12,
+	}))
+
+	rules, err := s.GetRecurringRules()
+	check(t, err)
+	if len(rules) != 2 || rules[0].Name != "Rent" || rules[1].Name != "streaming" {
+		t.Fatalf("got rules %+v, want Rent then streaming", rules)
+	}
+	rule := rules[0]
+	if rule.Category != "Rent" || rule.DayOfMonth != 1 || rule.EndDate != "2023-12-31" || rule.Count != 12 || rule.Paused {
+		t.Errorf("got rule %+v", rule)
+	}
+
+	occurrences := []domain.Expense{
+		{Name: "Rent", Date: "2023-01-01", Amount: 90000, CategoryId: rent},
+		{Name: "Rent", Date: "2023-02-01", Amount: 90000, CategoryId: rent},
+	}
+	check(t, s.InsertRecurringOccurrences(rule.Id, occurrences, "2023-02-15"))
+	occurrences = append(occurrences, domain.Expense{Name: "Rent", Date: "2023-03-01", Amount: 90000, CategoryId: rent})
+	check(t, s.InsertRecurringOccurrences(rule.Id, occurrences, "2023-03-15"))
+
+	list, err := s.GetExpenses()
+	check(t, err)
+	if len(list) != 3 {
+		t.Fatalf("got %d expenses, want each occurrence inserted once", len(list))
+	}
+	for _, expense := range list {
+		if expense.RuleId != rule.Id || expense.Kind != domain.KindExpense || expense.Category != "Rent" {
+			t.Errorf("got occurrence %+v", expense)
+		}
+	}
+
+	if _, err := s.InsertExpense(domain.Expense{Name: "Rent", Date: "2023-01-01", RuleId: rule.Id, Kind: domain.KindExpense}); err == nil {
+		t.Error("inserting a second occurrence of a rule on the same date should fail")
+	}
+
+	rules, err = s.GetRecurringRules()
+	check(t, err)
+	if rules[0].MaterializedUntil != "2023-03-15" {
+		t.Errorf("got materialized until %q, want 2023-03-15", rules[0].MaterializedUntil)
+	}
+
+	check(t, s.UpdateExpense(domain.Expense{
+		Id:     list[2].Id,
+		Name:   "Rent",
+		Date:   "2023-03-01",
+		Amount: 90000,
+		Kind:   domain.KindExpense,
+		Splits: []domain.Split{{CategoryId: rent, Amount: 90000}},
+	}))
+	check(t, s.UpdateRuleExpensesAfter(rule.Id, "2023-01-31", domain.Expense{Name: "New rent", Amount: 95000}))
+
+	list, err = s.GetExpenses()
+	check(t, err)
+	expectNames(t, list, "Rent", "New rent", "New rent")
+	if list[0].Amount != 90000 || list[1].Amount != 95000 || list[2].CategoryId != 0 || len(list[2].Splits) != 0 {
+		t.Errorf("got %+v", list)
+	}
+
+	rule.Paused = true
+	rule.Amount = 95000
+	check(t, s.UpdateRecurringRule(rule))
+	rules, err = s.GetRecurringRules()
+	check(t, err)
+	if !rules[0].Paused || rules[0].Amount != 95000 {
+		t.Errorf("got rule %+v, want it paused at 950.00", rules[0])
+	}
+
+	if err := s.UpdateRecurringRule(domain.RecurringRule{Id: rule.Id + 100, Name: "ghost"}); err == nil {
+		t.Error("updating a missing recurring rule should fail")
+	}
+
+	check(t, s.DeleteRecurringRule(rule.Id))
+
+	list, err = s.GetExpenses()
+	check(t, err)
+	if len(list) != 3 || list[0].RuleId != 0 {
+		t.Errorf("got %+v, want the occurrences kept without rule", list)
+	}
+
+	rules, err = s.GetRecurringRules()
+	check(t, err)
+	if len(rules) != 1 || rules[0].Name != "streaming" {
+		t.Errorf("got rules %+v, want only streaming left", rules)
+	}
+}
+
+func testAttachments(t *testing.T, s domain.Storage) {
+	id := insertExpense(t, s, domain.Expense{Name: "receipt", Date: "2023-02-10", Amount: 100})
+	other := insertExpense(t, s, domain.Expense{Name: "other", Date: "2023-02-11", Amount: 100})
+
+	attachment := domain.Attachment{
+		ExpenseId: id,
+		Name:      "receipt.png",
+		File:      "abc.png",
+		Hash:      "abc",
+		MimeType:  "image/png",
+		Size:      3,
+		AddedAt:   "2023-02-10T10:00:00Z",
+	}
+	check(t, s.InsertAttachment(attachment, []byte("png")))
+	attachment.Name = "copy.png"
+	check(t, s.InsertAttachment(attachment, []byte("png")))
+	attachment.ExpenseId = other
+	check(t, s.InsertAttachment(attachment, []byte("png")))
+
+	list, err := s.GetAttachments(id)
+	check(t, err)
+	if len(list) != 2 || list[0].Name != "receipt.png" || list[1].Name != "copy.png" {
+		t.Fatalf("got attachments %+v", list)
+	}
+	if list[0].Id == 0 || list[0].File != "abc.png" || list[0].MimeType != "image/png" || list[0].Size != 3 {
+		t.Errorf("got attachment %+v", list[0])
+	}
+	if s.AttachmentPath(list[0].File) == "" {
+		t.Error("got an empty attachment path")
+	}
+
+	if count := getExpense(t, s, id).Attachments; count != 2 {
+		t.Errorf("got %d attachments counted, want 2", count)
+	}
+
+	check(t, s.DeleteAttachment(list[1].Id))
+	list, err = s.GetAttachments(id)
+	check(t, err)
+	if len(list) != 1 {
+		t.Errorf("got %d attachments, want 1 left", len(list))
+	}
+
+	check(t, s.DeleteExpense(id, "2023-02-12T10:00:00Z"))
+	check(t, s.PurgeExpense(id))
+
+	list, err = s.GetAttachments(id)
+	check(t, err)
+	if len(list) != 0 {
+		t.Errorf("got %d attachments of a purged expense, want none", len(list))
+	}
+
+	list, err = s.GetAttachments(other)
+	check(t, err)
+	if len(list) != 1 {
+		t.Errorf("got %d attachments of another expense, want it kept", len(list))
+	}
+}
+
+func testPayees(t *testing.T, s domain.Storage) {
+	market, err := s.InsertPayee(domain.Payee{Name: "market"})
+	check(t, err)
+	bakery, err := s.InsertPayee(domain.Payee{Name: "Bakery"})
+	check(t, err)
+
+	if _, err := s.InsertPayee(domain.Payee{Name: "MARKET"}); err == nil {
+		t.Error("inserting a payee with a taken name should fail")
+	}
+
+	payees, err := s.GetPayees()
+	check(t, err)
+	want := []domain.Payee{{Id: bakery, Name: "Bakery"}, {Id: market, Name: "market"}}
+	if !reflect.DeepEqual(payees, want) {
+		t.Errorf("got payees %+v, want %+v", payees, want)
+	}
+
+	payee, ok, err := s.GetPayeeWithName("Market")
+	check(t, err)
+	if !ok || payee.Id != market {
+		t.Errorf("got %+v (%v), want market", payee, ok)
+	}
+
+	if _, ok, err := s.GetPayeeWithName("butcher"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a missing payee, want false and no error", ok, err)
+	}
+}
+
+func testCategorizationRules(t *testing.T, s domain.Storage) {
+	food := insertCategory(t, s, "Food")
+	market, err := s.InsertPayee(domain.Payee{Name: "Market"})
+	check(t, err)
+
+	check(t, s.InsertCategorizationRule(domain.CategorizationRule{Priority: 2, MatchType: domain.MatchPrefix, Pattern: "bus", Tags: []string{}}))
+	check(t, s.InsertCategorizationRule(domain.CategorizationRule{
+		Priority:   1,
+		MatchType:  domain.MatchRegex,
+		Pattern:    "^market",
+		MinAmount:  100,
+		MaxAmount:  10000,
+		CategoryId: food,
+		PayeeId:    market,
+		Tags:       []string{"food", "weekly"},
+	}))
+	check(t, s.InsertCategorizationRule(domain.CategorizationRule{Priority: 2, MatchType: domain.MatchExact, Pattern: "train", Tags: []string{}}))
+
+	rules, err := s.GetCategorizationRules()
+	check(t, err)
+	if len(rules) != 3 || rules[0].Pattern != "^market" || rules[1].Pattern != "bus" || rules[2].Pattern != "train" {
+		t.Fatalf("got rules %+v, want them by priority then insertion", rules)
+	}
+	rule := rules[0]
+	if rule.Category != "Food" || rule.Payee != "Market" || rule.MinAmount != 100 || rule.MaxAmount != 10000 {
+		t.Errorf("got rule %+v", rule)
+	}
+	if !reflect.DeepEqual(rule.Tags, []string{"food", "weekly"}) {
+		t.Errorf("got tags %q", rule.Tags)
+	}
+
+	rule.Priority = 3
+	check(t, s.UpdateCategorizationRule(rule))
+	if err := s.UpdateCategorizationRule(domain.CategorizationRule{Id: rule.Id + 100, Pattern: "ghost"}); err == nil {
+		t.Error("updating a missing categorization rule should fail")
+	}
+
+	check(t, s.DeleteCategorizationRule(rules[1].Id))
+
+	rules, err = s.GetCategorizationRules()
+	check(t, err)
+	if len(rules) != 2 || rules[0].Pattern != "train" || rules[1].Pattern != "^market" {
+		t.Errorf("got rules %+v, want train then ^market", rules)
+	}
+}
+
+func testSettings(t *testing.T, s domain.Storage) {
+	if _, ok, err := s.GetSetting("missing"); err != nil || ok {
+		t.Errorf("got ok %v error %v for a missing setting, want false and no error", ok, err)
+	}
+
+	check(t, s.UpdateSetting("mode", "full"))
+	check(t, s.UpdateSetting("mode", "capped"))
+	check(t, s.UpdateSetting("empty", ""))
+
+	value, ok, err := s.GetSetting("mode")
+	check(t, err)
+	if !ok || value != "capped" {
+		t.Errorf("got %q (%v), want the replaced value", value, ok)
+	}
+
+	value, ok, err = s.GetSetting("empty")
+	check(t, err)
+	if !ok || value != "" {
+		t.Errorf("got %q (%v), want an empty value that is set", value, ok)
+	}
+}
+
+func testAuditLog(t *testing.T, s domain.Storage) {
+	entries := []domain.AuditEntry{
+		{Timestamp: "2023-02-01T10:00:00Z", User: "ann", Action: domain.AuditInsert, Entity: domain.AuditExpense, Key: "1", After: `{"Name":"a"}`},
+		{Timestamp: "2023-02-02T10:00:00Z", User: "bob", Action: domain.AuditUpdate, Entity: domain.AuditExpense, Key: "1", Before: `{"Name":"a"}`, After: `{"Name":"b"}`},
+		{Timestamp: "2023-02-03T10:00:00Z", User: "ann", Action: domain.AuditInsert, Entity: domain.AuditBudget, Key: "2023-02"},
+		{Timestamp: "2023-02-04T10:00:00Z", Action: domain.AuditDelete, Entity: domain.AuditExpense, Key: "2"},
+	}
+	check(t, s.InsertAuditEntries(entries[:1]))
+	check(t, s.InsertAuditEntries(entries[1:]))
+
+	keys := func(list []domain.AuditEntry) []string {
+		keys := []string{}
+		for _, entry := range list {
+			keys = append(keys, entry.Timestamp[8:10])
+		}
+		return keys
+	}
+
+	for _, test := range []struct {
+		query domain.AuditQuery
+		want  []string
+	}{
+		{domain.AuditQuery{}, []string{"04", "03", "02", "01"}},
+		{domain.AuditQuery{Entity: domain.AuditExpense}, []string{"04", "02", "01"}},
+		{domain.AuditQuery{Entity: domain.AuditExpense, Key: "1"}, []string{"02", "01"}},
+		{domain.AuditQuery{User: "ann"}, []string{"03", "01"}},
+		{domain.AuditQuery{From: "2023-02-02T10:00:00Z", To: "2023-02-03T10:00:00Z"}, []string{"03", "02"}},
+		{domain.AuditQuery{Limit: 2}, []string{"04", "03"}},
+		{domain.AuditQuery{Entity: domain.AuditExpense, Limit: 1}, []string{"04"}},
+		{domain.AuditQuery{Key: "3"}, []string{}},
+	} {
+		list, err := s.GetAuditEntries(test.query)
+		check(t, err)
+		if got := keys(list); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("got entries of days %q for %+v, want %q", got, test.query, test.want)
+		}
+	}
+
+	list, err := s.GetAuditEntries(domain.AuditQuery{Key: "1"})
+	check(t, err)
+	entry := list[0]
+	if entry.Id == 0 || entry.Id <= list[1].Id {
+		t.Errorf("got ids %d and %d, want growing ids", entry.Id, list[1].Id)
+	}
+	entry.Id = 0
+	if entry != entries[1] {
+		t.Errorf("got entry %+v, want %+v", entry, entries[1])
+	}
+}
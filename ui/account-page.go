@@ -0,0 +1,358 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+type AccountPage struct {
+	list          material.ListStyle
+	entryList     material.ListStyle
+	theme         *material.Theme
+	accountLabel  material.LabelStyle
+	entryLabel    material.LabelStyle
+	balanceLabel  material.LabelStyle
+	messageLabel  material.LabelStyle
+	nameInput     material.EditorStyle
+	openingInput  material.EditorStyle
+	accountType   *widget.Enum
+	typeRadios    []material.RadioButtonStyle
+	submitButton  material.ButtonStyle
+	cancelButton  material.ButtonStyle
+	viewButtons   []material.ButtonStyle
+	editButtons   []material.ButtonStyle
+	deleteButtons []material.ButtonStyle
+	accounts      []domain.AccountData
+	viewing       int
+	editingId     int
+	controller    domain.API
+}
+
+// createAccountPage returns AccountPage struct.
+func createAccountPage(th *material.Theme, controller domain.API) AccountPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+	var entryList widget.List
+	entryList.Axis = layout.Vertical
+
+	accountLabel := material.Label(th, unit.Sp(16), "")
+	accountLabel.MaxLines = 1
+	entryLabel := material.Label(th, unit.Sp(14), "")
+	entryLabel.MaxLines = 1
+	balanceLabel := material.Label(th, unit.Sp(14), "")
+	balanceLabel.MaxLines = 1
+	balanceLabel.Alignment = text.End
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	nameInput := material.Editor(th, &widget.Editor{}, "account name")
+	openingInput := material.Editor(th, &widget.Editor{}, "opening balance")
+
+	inputs := []*material.EditorStyle{
+		&nameInput,
+		&openingInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	accountType := &widget.Enum{Value: string(domain.Checking)}
+	typeRadios := []material.RadioButtonStyle{}
+
+	for _, value := range []domain.AccountType{domain.Checking, domain.Savings, domain.CreditCard, domain.Cash} {
+		typeRadios = append(typeRadios, material.RadioButton(th, accountType, string(value), string(value)))
+	}
+
+	submitButton := material.Button(th, &widget.Clickable{}, "Add")
+	submitButton.Background = color.NRGBA{53, 53, 113, 255}
+	cancelButton := material.Button(th, &widget.Clickable{}, "Cancel")
+	cancelButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	return AccountPage{
+		list:         material.List(th, &list),
+		entryList:    material.List(th, &entryList),
+		theme:        th,
+		accountLabel: accountLabel,
+		entryLabel:   entryLabel,
+		balanceLabel: balanceLabel,
+		messageLabel: messageLabel,
+		nameInput:    nameInput,
+		openingInput: openingInput,
+		accountType:  accountType,
+		typeRadios:   typeRadios,
+		submitButton: submitButton,
+		cancelButton: cancelButton,
+		viewing:      -1,
+		controller:   controller,
+	}
+}
+
+// Reload fetches accounts with their running balances
+// from controller and rebuilds the rows.
+func (ap *AccountPage) Reload() {
+	accounts, err := ap.controller.GetAccounts()
+	if err != nil {
+		logger.Error(err.Error())
+		ap.messageLabel.Text = "Could not load accounts: " + err.Error()
+		accounts = []domain.Account{}
+	}
+
+	ap.accounts = []domain.AccountData{}
+	ap.viewButtons = []material.ButtonStyle{}
+	ap.editButtons = []material.ButtonStyle{}
+	ap.deleteButtons = []material.ButtonStyle{}
+
+	for _, account := range accounts {
+		data, err := ap.controller.CreateAccountData(account.Id)
+		if err != nil {
+			logger.Error(err.Error())
+			ap.messageLabel.Text = err.Error()
+			data = domain.AccountData{Account: account}
+		}
+		ap.accounts = append(ap.accounts, data)
+
+		viewButton := material.Button(ap.theme, &widget.Clickable{}, "view")
+		viewButton.Background = color.NRGBA{3, 106, 102, 255}
+		editButton := material.Button(ap.theme, &widget.Clickable{}, "e")
+		editButton.Background = color.NRGBA{53, 53, 113, 255}
+		deleteButton := material.Button(ap.theme, &widget.Clickable{}, "x")
+		deleteButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		ap.viewButtons = append(ap.viewButtons, viewButton)
+		ap.editButtons = append(ap.editButtons, editButton)
+		ap.deleteButtons = append(ap.deleteButtons, deleteButton)
+	}
+
+	if ap.viewing >= len(ap.accounts) {
+		ap.viewing = -1
+	}
+}
+
+// clearInputs clear its inputs and leaves edit mode.
+func (ap *AccountPage) clearInputs() {
+	ap.nameInput.Editor.SetText("")
+	ap.openingInput.Editor.SetText("")
+	ap.accountType.Value = string(domain.Checking)
+	ap.editingId = 0
+	ap.submitButton.Text = "Add"
+}
+
+// Update updates data based on button clicks.
+func (ap *AccountPage) Update() {
+	if ap.cancelButton.Button.Clicked() {
+		ap.clearInputs()
+		ap.messageLabel.Text = ""
+	}
+
+	if ap.submitButton.Button.Clicked() {
+		opening := domain.Money(0)
+		if strings.TrimSpace(ap.openingInput.Editor.Text()) != "" {
+			parsed, err := domain.ParseMoney(ap.openingInput.Editor.Text())
+			if err != nil {
+				ap.messageLabel.Text = err.Error()
+				return
+			}
+			opening = parsed
+		}
+
+		account := domain.Account{
+			Id:             ap.editingId,
+			Name:           ap.nameInput.Editor.Text(),
+			Type:           domain.AccountType(ap.accountType.Value),
+			OpeningBalance: opening,
+		}
+
+		var err error
+		if account.Id != 0 {
+			err = ap.controller.UpdateAccount(account)
+		} else {
+			err = ap.controller.AddAccount(account)
+		}
+		if err != nil {
+			ap.messageLabel.Text = err.Error()
+			return
+		}
+
+		ap.messageLabel.Text = ""
+		ap.clearInputs()
+		ap.Reload()
+		return
+	}
+
+	for i := range ap.accounts {
+		account := ap.accounts[i].Account
+
+		if ap.viewButtons[i].Button.Clicked() {
+			if ap.viewing == i {
+				ap.viewing = -1
+			} else {
+				ap.viewing = i
+			}
+			return
+		}
+
+		if ap.editButtons[i].Button.Clicked() {
+			ap.nameInput.Editor.SetText(account.Name)
+			ap.openingInput.Editor.SetText(account.OpeningBalance.String())
+			ap.accountType.Value = string(account.Type)
+			ap.editingId = account.Id
+			ap.submitButton.Text = "Save"
+			return
+		}
+
+		if ap.deleteButtons[i].Button.Clicked() {
+			if err := ap.controller.RemoveAccount(account.Id); err != nil {
+				ap.messageLabel.Text = err.Error()
+				return
+			}
+			if ap.editingId == account.Id {
+				ap.clearInputs()
+			}
+			ap.viewing = -1
+			ap.Reload()
+			return
+		}
+	}
+}
+
+// Layout returns its layout.
+func (ap *AccountPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(10)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(10))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(20)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	radios := []layout.FlexChild{}
+	for i := range ap.typeRadios {
+		radios = append(radios, layout.Rigid(ap.typeRadios[i].Layout))
+	}
+
+	accountList := func(gtx layout.Context) layout.Dimensions {
+		return ap.list.Layout(gtx, len(ap.accounts), func(gtx layout.Context, i int) layout.Dimensions {
+			data := ap.accounts[i]
+			ap.accountLabel.Text = fmt.Sprintf("%s (%s)  %s", data.Account.Name, data.Account.Type, data.Balance)
+			return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+				paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+				return layout.Flex{
+					Axis: layout.Horizontal,
+				}.Layout(gtx,
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						return topBottomMargins.Layout(gtx, ap.accountLabel.Layout)
+					}),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return topBottomMargins.Layout(gtx, ap.viewButtons[i].Layout)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return topBottomMargins.Layout(gtx, ap.editButtons[i].Layout)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+					layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+						return topBottomMargins.Layout(gtx, ap.deleteButtons[i].Layout)
+					}),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+				)
+			})
+		})
+	}
+
+	entries := func(gtx layout.Context) layout.Dimensions {
+		if ap.viewing < 0 {
+			return layout.Dimensions{}
+		}
+		data := ap.accounts[ap.viewing]
+		return ap.entryList.Layout(gtx, len(data.Entries), func(gtx layout.Context, i int) layout.Dimensions {
+			entry := data.Entries[i]
+			return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis: layout.Horizontal,
+				}.Layout(gtx,
+					layout.Flexed(2, func(gtx layout.Context) layout.Dimensions {
+						ap.entryLabel.Text = entry.Expense.Date + "  " + entry.Expense.Name
+						return ap.entryLabel.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						ap.balanceLabel.Text = entry.Change.String()
+						if entry.Change > 0 {
+							ap.balanceLabel.Text = "+" + ap.balanceLabel.Text
+						}
+						return ap.balanceLabel.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						ap.balanceLabel.Text = entry.Balance.String()
+						return ap.balanceLabel.Layout(gtx)
+					}),
+				)
+			})
+		})
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, accountList),
+			layout.Flexed(1, entries),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, input(&ap.nameInput), input(&ap.openingInput))
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, radios...)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, ap.messageLabel.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis: layout.Horizontal,
+					}.Layout(gtx,
+						layout.Flexed(1, ap.submitButton.Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Flexed(1, ap.cancelButton.Layout),
+					)
+				})
+			}),
+		)
+	})
+}
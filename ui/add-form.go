@@ -1,10 +1,10 @@
 package ui
 
 import (
-	"fmt"
 	"image"
 	"image/color"
 	"strconv"
+	"strings"
 
 	"gioui.org/layout"
 	"gioui.org/op/clip"
@@ -14,6 +14,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
 )
 
 type FormPage struct {
@@ -21,25 +22,48 @@ type FormPage struct {
 	dateInput     material.EditorStyle
 	categoryInput material.EditorStyle
 	amountInput   material.EditorStyle
+	payeeInput    material.EditorStyle
+	tagsInput     material.EditorStyle
+	kind          *widget.Enum
+	kindRadios    []material.RadioButtonStyle
+	account       *widget.Enum
+	toAccount     *widget.Enum
+	accountRadios []material.RadioButtonStyle
+	toRadios      []material.RadioButtonStyle
+	accountLabel  material.LabelStyle
+	toLabel       material.LabelStyle
+	messageLabel  material.LabelStyle
+	splitEditor   SplitEditor
+	attachments   AttachmentEditor
+	history       HistoryPanel
+	theme         *material.Theme
 	submitButton  material.ButtonStyle
 	cancelButton  material.ButtonStyle
 	controller    domain.API
 	allInputs     []*material.EditorStyle
 	refreshData   bool
+	editing       bool
+	expenseId     int
+	currentPage   *Page
+	monthView     *MonthView
 }
 
 // createFormPage returns FormPage struct.
-func createFormPage(th *material.Theme, controller domain.API) FormPage {
+func createFormPage(th *material.Theme, currentPage *Page, monthData *MonthView, controller domain.API) FormPage {
 	nameInput := material.Editor(th, &widget.Editor{}, "name")
 	dateInput := material.Editor(th, &widget.Editor{}, "date (YYYY-MM-DD or YYYY-MM)")
 	categoryInput := material.Editor(th, &widget.Editor{}, "category")
 	amountInput := material.Editor(th, &widget.Editor{}, "amount")
+	payeeInput := material.Editor(th, &widget.Editor{}, "payee (optional)")
+	tagsInput := material.Editor(th, &widget.Editor{}, "tags (comma separated, optional)")
 
 	inputs := []*material.EditorStyle{
 		&nameInput,
 		&dateInput,
 		&categoryInput,
 		&amountInput,
+		&payeeInput,
+		&tagsInput,
 	}
 
 	for i := range inputs {
@@ -49,6 +73,19 @@ func createFormPage(th *material.Theme, controller domain.API) FormPage {
 		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
 	}
 
+	kind := &widget.Enum{Value: string(domain.KindExpense)}
+	kindRadios := []material.RadioButtonStyle{
+		material.RadioButton(th, kind, string(domain.KindExpense), "Expense"),
+		material.RadioButton(th, kind, string(domain.KindIncome), "Income"),
+		material.RadioButton(th, kind, string(domain.KindTransfer), "Transfer"),
+	}
+
+	accountLabel := material.Label(th, unit.Sp(16), "Account:")
+	toLabel := material.Label(th, unit.Sp(16), "To:")
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+	messageLabel.Alignment = text.Middle
+
 	submitButton := material.Button(th, &widget.Clickable{}, "Submit")
 	submitButton.Background = color.NRGBA{53, 53, 113, 255}
 	cancelButton := material.Button(th, &widget.Clickable{}, "Cancel")
@@ -59,11 +96,88 @@ func createFormPage(th *material.Theme, controller domain.API) FormPage {
 		dateInput:     dateInput,
 		categoryInput: categoryInput,
 		amountInput:   amountInput,
+		payeeInput:    payeeInput,
+		tagsInput:     tagsInput,
+		kind:          kind,
+		kindRadios:    kindRadios,
+		account:       &widget.Enum{},
+		toAccount:     &widget.Enum{},
+		accountLabel:  accountLabel,
+		toLabel:       toLabel,
+		messageLabel:  messageLabel,
+		splitEditor:   createSplitEditor(th),
+		attachments:   createAttachmentEditor(th, controller),
+		history:       createHistoryPanel(th, nil),
+		theme:         th,
 		submitButton:  submitButton,
 		cancelButton:  cancelButton,
 		controller:    controller,
 		allInputs:     inputs,
+		currentPage:   currentPage,
+		monthView:     monthData,
+	}
+}
+
+// Reload fetches accounts from controller and rebuilds the account
+// selectors, selecting the first account if none is.
+func (fp *FormPage) Reload() {
+	accounts, err := fp.controller.GetAccounts()
+	if err != nil {
+		logger.Error(err.Error())
+		fp.messageLabel.Text = "Could not load accounts: " + err.Error()
+		accounts = []domain.Account{}
+	}
+
+	fp.accountRadios = []material.RadioButtonStyle{}
+	fp.toRadios = []material.RadioButtonStyle{}
+	selected := false
+
+	for _, account := range accounts {
+		id := strconv.Itoa(account.Id)
+		fp.accountRadios = append(fp.accountRadios, material.RadioButton(fp.theme, fp.account, id, account.Name))
+		fp.toRadios = append(fp.toRadios, material.RadioButton(fp.theme, fp.toAccount, id, account.Name))
+		selected = selected || fp.account.Value == id
+	}
+
+	if !selected && len(accounts) > 0 {
+		fp.account.Value = strconv.Itoa(accounts[0].Id)
+	}
+}
+
+// startEditing prefills its inputs with an existing expense and
+// switches the form to edit mode, saving instead of inserting.
+func (fp *FormPage) startEditing(expense domain.Expense) {
+	fp.nameInput.Editor.SetText(expense.Name)
+	fp.dateInput.Editor.SetText(expense.Date)
+	fp.categoryInput.Editor.SetText(expense.Category)
+	fp.amountInput.Editor.SetText(expense.Amount.String())
+	fp.payeeInput.Editor.SetText(expense.Payee)
+	fp.tagsInput.Editor.SetText(strings.Join(expense.Tags, ", "))
+	fp.kind.Value = string(expense.Kind)
+	fp.account.Value = strconv.Itoa(expense.AccountId)
+	fp.toAccount.Value = strconv.Itoa(expense.ToAccountId)
+	fp.splitEditor.setSplits(expense.Splits)
+	fp.attachments.load(expense.Id)
+	fp.history.close()
+	fp.history.fetch = func() ([]domain.AuditEntry, error) {
+		return fp.controller.GetExpenseHistory(expense.Id)
+	}
+	fp.submitButton.Text = "Save"
+	fp.expenseId = expense.Id
+	fp.editing = true
+}
+
+// stopEditing clears its inputs and switches the form back to add mode.
+func (fp *FormPage) stopEditing() {
+	if !fp.editing {
+		return
 	}
+	fp.clearInputs()
+	fp.attachments.load(0)
+	fp.history.close()
+	fp.submitButton.Text = "Submit"
+	fp.expenseId = 0
+	fp.editing = false
 }
 
 // clearInputs clear its inputs.
@@ -71,29 +185,82 @@ func (fp *FormPage) clearInputs() {
 	for i := range fp.allInputs {
 		fp.allInputs[i].Editor.SetText("")
 	}
+	fp.kind.Value = string(domain.KindExpense)
+	fp.toAccount.Value = ""
+	fp.splitEditor.clear()
+	fp.messageLabel.Text = ""
 }
 
 // Update updates data based on button clicks.
 func (fp *FormPage) Update() {
 	if fp.cancelButton.Button.Clicked() {
+		if fp.editing {
+			fp.stopEditing()
+			fp.backToList()
+			return
+		}
 		fp.clearInputs()
 	}
 
+	fp.splitEditor.total, _ = domain.ParseMoney(fp.amountInput.Editor.Text())
+	fp.splitEditor.Update()
+	fp.attachments.Update()
+	if fp.editing {
+		fp.history.Update()
+	}
+
 	if fp.submitButton.Button.Clicked() {
-		amount, err := strconv.ParseFloat(fp.amountInput.Editor.Text(), 64)
+		amount, err := domain.ParseMoney(fp.amountInput.Editor.Text())
 		if err != nil {
-			fmt.Println(err)
+			fp.messageLabel.Text = err.Error()
+			return
 		}
-		fp.controller.AddExpense(domain.Expense{
+		expense := domain.Expense{
+			Id:       fp.expenseId,
 			Name:     fp.nameInput.Editor.Text(),
 			Date:     fp.dateInput.Editor.Text(),
 			Category: fp.categoryInput.Editor.Text(),
 			Amount:   amount,
-		})
+			Kind:     domain.Kind(fp.kind.Value),
+			Payee:    fp.payeeInput.Editor.Text(),
+			Tags:     []string{fp.tagsInput.Editor.Text()},
+		}
+		expense.AccountId, _ = strconv.Atoi(fp.account.Value)
+		expense.ToAccountId, _ = strconv.Atoi(fp.toAccount.Value)
+
+		if expense.Kind == domain.KindExpense {
+			expense.Splits, err = fp.splitEditor.splits()
+			if err != nil {
+				fp.messageLabel.Text = err.Error()
+				return
+			}
+		}
+
+		if fp.editing {
+			if err := fp.controller.UpdateExpense(expense); err != nil {
+				logger.Error(err.Error())
+				fp.messageLabel.Text = err.Error()
+				return
+			}
+			fp.stopEditing()
+			fp.backToList()
+			return
+		}
+
+		if err := fp.controller.AddExpense(expense); err != nil {
+			fp.messageLabel.Text = err.Error()
+			return
+		}
 		fp.clearInputs()
 	}
 }
 
+// backToList switches to the list page and refreshes the month data.
+func (fp *FormPage) backToList() {
+	*fp.currentPage = List
+	fp.monthView.Reload(fp.controller)
+}
+
 // Layout returns its layout.
 func (fp *FormPage) Layout(gtx layout.Context) layout.Dimensions {
 	margins := layout.Inset{
@@ -186,7 +353,102 @@ func (fp *FormPage) Layout(gtx layout.Context) layout.Dimensions {
 					},
 				),
 				layout.Rigid(
-					layout.Spacer{Height: unit.Dp(80)}.Layout,
+					func(gtx layout.Context) layout.Dimensions {
+						return marginTop.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								r := clip.Rect{
+									Min: image.Pt(gtx.Dp(borders.Width), gtx.Dp(borders.Width)),
+									Max: image.Pt(fp.payeeInput.Layout(gtx).Size.X, fp.payeeInput.Layout(gtx).Size.Y+gtx.Dp(insideBorderMargins.Top*2)-gtx.Dp(borders.Width)),
+								}
+								paint.FillShape(gtx.Ops, color, r.Op())
+								return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									return insideBorderMargins.Layout(gtx, fp.payeeInput.Layout)
+								})
+							},
+						)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						return marginTop.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								r := clip.Rect{
+									Min: image.Pt(gtx.Dp(borders.Width), gtx.Dp(borders.Width)),
+									Max: image.Pt(fp.tagsInput.Layout(gtx).Size.X, fp.tagsInput.Layout(gtx).Size.Y+gtx.Dp(insideBorderMargins.Top*2)-gtx.Dp(borders.Width)),
+								}
+								paint.FillShape(gtx.Ops, color, r.Op())
+								return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									return insideBorderMargins.Layout(gtx, fp.tagsInput.Layout)
+								})
+							},
+						)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						return marginTop.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								return layout.Flex{
+									Axis:    layout.Horizontal,
+									Spacing: layout.SpaceEvenly,
+								}.Layout(gtx,
+									layout.Rigid(fp.kindRadios[0].Layout),
+									layout.Rigid(fp.kindRadios[1].Layout),
+									layout.Rigid(fp.kindRadios[2].Layout),
+								)
+							},
+						)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						return marginTop.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								return layoutRadioRow(gtx, fp.accountLabel, fp.accountRadios)
+							},
+						)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						if fp.kind.Value != string(domain.KindTransfer) {
+							return layout.Dimensions{}
+						}
+						return marginTop.Layout(gtx,
+							func(gtx layout.Context) layout.Dimensions {
+								return layoutRadioRow(gtx, fp.toLabel, fp.toRadios)
+							},
+						)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						if fp.kind.Value != string(domain.KindExpense) {
+							return layout.Dimensions{}
+						}
+						return marginTop.Layout(gtx, fp.splitEditor.Layout)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						if !fp.editing {
+							return layout.Dimensions{}
+						}
+						return marginTop.Layout(gtx, fp.attachments.Layout)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						if !fp.editing {
+							return layout.Dimensions{}
+						}
+						return marginTop.Layout(gtx, fp.history.Layout)
+					},
+				),
+				layout.Rigid(
+					func(gtx layout.Context) layout.Dimensions {
+						return marginTop.Layout(gtx, fp.messageLabel.Layout)
+					},
 				),
 				layout.Rigid(
 					func(gtx layout.Context) layout.Dimensions {
@@ -206,3 +468,20 @@ func (fp *FormPage) Layout(gtx layout.Context) layout.Dimensions {
 		},
 	)
 }
+
+// layoutRadioRow lays out a label followed by radio buttons on one row.
+func layoutRadioRow(gtx layout.Context, label material.LabelStyle, radios []material.RadioButtonStyle) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(label.Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+	}
+
+	for i := range radios {
+		children = append(children, layout.Rigid(radios[i].Layout))
+	}
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Alignment: layout.Middle,
+	}.Layout(gtx, children...)
+}
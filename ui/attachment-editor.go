@@ -0,0 +1,186 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// AttachmentEditor lists, opens, adds and removes
+// the attachments of the expense being edited.
+type AttachmentEditor struct {
+	theme         *material.Theme
+	pathInput     material.EditorStyle
+	attachButton  material.ButtonStyle
+	nameLabel     material.LabelStyle
+	messageLabel  material.LabelStyle
+	openButtons   []material.ButtonStyle
+	removeButtons []material.ButtonStyle
+	attachments   []domain.Attachment
+	expenseId     int
+	controller    domain.API
+}
+
+// createAttachmentEditor returns AttachmentEditor struct.
+func createAttachmentEditor(th *material.Theme, controller domain.API) AttachmentEditor {
+	pathInput := material.Editor(th, &widget.Editor{}, "path of an image or PDF to attach")
+	pathInput.Editor.Alignment = text.Middle
+	pathInput.Editor.SingleLine = true
+	pathInput.Color = color.NRGBA{235, 235, 235, 255}
+	pathInput.HintColor = color.NRGBA{255, 255, 255, 40}
+
+	attachButton := material.Button(th, &widget.Clickable{}, "Attach")
+	attachButton.Background = color.NRGBA{3, 106, 102, 255}
+
+	nameLabel := material.Label(th, unit.Sp(14), "")
+	nameLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	return AttachmentEditor{
+		theme:        th,
+		pathInput:    pathInput,
+		attachButton: attachButton,
+		nameLabel:    nameLabel,
+		messageLabel: messageLabel,
+		controller:   controller,
+	}
+}
+
+// load fetches the attachments of an expense, 0 meaning none is edited.
+func (ae *AttachmentEditor) load(expenseId int) {
+	ae.expenseId = expenseId
+	ae.attachments = []domain.Attachment{}
+	ae.openButtons = []material.ButtonStyle{}
+	ae.removeButtons = []material.ButtonStyle{}
+	ae.messageLabel.Text = ""
+
+	if expenseId == 0 {
+		ae.pathInput.Editor.SetText("")
+		return
+	}
+
+	attachments, err := ae.controller.GetAttachments(expenseId)
+	if err != nil {
+		logger.Error(err.Error())
+		ae.messageLabel.Text = "Could not load attachments: " + err.Error()
+		return
+	}
+
+	ae.attachments = attachments
+
+	for range attachments {
+		openButton := material.Button(ae.theme, &widget.Clickable{}, "open")
+		openButton.Background = color.NRGBA{3, 106, 102, 255}
+		removeButton := material.Button(ae.theme, &widget.Clickable{}, "x")
+		removeButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		ae.openButtons = append(ae.openButtons, openButton)
+		ae.removeButtons = append(ae.removeButtons, removeButton)
+	}
+}
+
+// Update updates data based on button clicks.
+func (ae *AttachmentEditor) Update() {
+	if ae.expenseId == 0 {
+		return
+	}
+
+	if ae.attachButton.Button.Clicked() {
+		if err := ae.controller.AttachFile(ae.expenseId, ae.pathInput.Editor.Text()); err != nil {
+			ae.messageLabel.Text = err.Error()
+			return
+		}
+		ae.pathInput.Editor.SetText("")
+		ae.load(ae.expenseId)
+		return
+	}
+
+	for i := range ae.attachments {
+		if ae.openButtons[i].Button.Clicked() {
+			if err := openFile(ae.controller.AttachmentPath(ae.attachments[i])); err != nil {
+				ae.messageLabel.Text = err.Error()
+			}
+			return
+		}
+
+		if ae.removeButtons[i].Button.Clicked() {
+			if err := ae.controller.RemoveAttachment(ae.attachments[i].Id); err != nil {
+				ae.messageLabel.Text = err.Error()
+				return
+			}
+			ae.load(ae.expenseId)
+			return
+		}
+	}
+}
+
+// Layout returns its layout, nothing when no expense is edited.
+func (ae *AttachmentEditor) Layout(gtx layout.Context) layout.Dimensions {
+	if ae.expenseId == 0 {
+		return layout.Dimensions{}
+	}
+
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	children := []layout.FlexChild{}
+
+	for i := range ae.attachments {
+		attachment := ae.attachments[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						ae.nameLabel.Text = fmt.Sprintf("%s (%d KB)", attachment.Name, (attachment.Size+1023)/1024)
+						return ae.nameLabel.Layout(gtx)
+					}),
+					layout.Rigid(ae.openButtons[i].Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+					layout.Rigid(ae.removeButtons[i].Layout),
+				)
+			})
+		}))
+	}
+
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+						paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+						return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+							return insideBorderMargins.Layout(gtx, ae.pathInput.Layout)
+						})
+					})
+				}),
+				layout.Rigid(ae.attachButton.Layout),
+			)
+		}),
+		layout.Rigid(ae.messageLabel.Layout),
+	)
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
@@ -0,0 +1,384 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// categoryRow is a category with its depth in the hierarchy.
+type categoryRow struct {
+	category domain.Category
+	depth    int
+}
+
+type CategoryPage struct {
+	list           material.ListStyle
+	theme          *material.Theme
+	nameLabel      material.LabelStyle
+	messageLabel   material.LabelStyle
+	nameInput      material.EditorStyle
+	parentInput    material.EditorStyle
+	colorInput     material.EditorStyle
+	iconInput      material.EditorStyle
+	submitButton   material.ButtonStyle
+	cancelButton   material.ButtonStyle
+	editButtons    []material.ButtonStyle
+	archiveButtons []material.ButtonStyle
+	deleteButtons  []material.ButtonStyle
+	allInputs      []*material.EditorStyle
+	rows           []categoryRow
+	editingId      int
+	controller     domain.API
+}
+
+// createCategoryPage returns CategoryPage struct.
+func createCategoryPage(th *material.Theme, controller domain.API) CategoryPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	nameLabel := material.Label(th, unit.Sp(16), "")
+	nameLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	nameInput := material.Editor(th, &widget.Editor{}, "name")
+	parentInput := material.Editor(th, &widget.Editor{}, "parent category (optional)")
+	colorInput := material.Editor(th, &widget.Editor{}, "color (#rrggbb, optional)")
+	iconInput := material.Editor(th, &widget.Editor{}, "icon (optional)")
+
+	inputs := []*material.EditorStyle{
+		&nameInput,
+		&parentInput,
+		&colorInput,
+		&iconInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	submitButton := material.Button(th, &widget.Clickable{}, "Add")
+	submitButton.Background = color.NRGBA{53, 53, 113, 255}
+	cancelButton := material.Button(th, &widget.Clickable{}, "Cancel")
+	cancelButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	return CategoryPage{
+		list:         material.List(th, &list),
+		theme:        th,
+		nameLabel:    nameLabel,
+		messageLabel: messageLabel,
+		nameInput:    nameInput,
+		parentInput:  parentInput,
+		colorInput:   colorInput,
+		iconInput:    iconInput,
+		submitButton: submitButton,
+		cancelButton: cancelButton,
+		allInputs:    inputs,
+		controller:   controller,
+	}
+}
+
+// Reload fetches categories from controller and rebuilds the rows.
+func (cp *CategoryPage) Reload() {
+	categories, err := cp.controller.GetCategories()
+	if err != nil {
+		logger.Error(err.Error())
+		cp.messageLabel.Text = "Could not load categories: " + err.Error()
+		categories = []domain.Category{}
+	}
+
+	cp.rows = orderCategoriesByHierarchy(categories)
+	cp.editButtons = []material.ButtonStyle{}
+	cp.archiveButtons = []material.ButtonStyle{}
+	cp.deleteButtons = []material.ButtonStyle{}
+
+	for _, row := range cp.rows {
+		editButton := material.Button(cp.theme, &widget.Clickable{}, "e")
+		editButton.Background = color.NRGBA{53, 53, 113, 255}
+
+		archiveText := "archive"
+		if row.category.Archived {
+			archiveText = "restore"
+		}
+		archiveButton := material.Button(cp.theme, &widget.Clickable{}, archiveText)
+		archiveButton.Background = color.NRGBA{3, 106, 102, 255}
+
+		deleteButton := material.Button(cp.theme, &widget.Clickable{}, "x")
+		deleteButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		cp.editButtons = append(cp.editButtons, editButton)
+		cp.archiveButtons = append(cp.archiveButtons, archiveButton)
+		cp.deleteButtons = append(cp.deleteButtons, deleteButton)
+	}
+}
+
+// orderCategoriesByHierarchy returns categories with every child
+// placed right after its parent.
+func orderCategoriesByHierarchy(categories []domain.Category) []categoryRow {
+	children := map[int][]domain.Category{}
+	known := map[int]bool{}
+
+	for _, category := range categories {
+		known[category.Id] = true
+	}
+
+	for _, category := range categories {
+		parentId := category.ParentId
+		if !known[parentId] {
+			parentId = 0
+		}
+		children[parentId] = append(children[parentId], category)
+	}
+
+	rows := []categoryRow{}
+
+	var walk func(parentId, depth int)
+	walk = func(parentId, depth int) {
+		for _, category := range children[parentId] {
+			rows = append(rows, categoryRow{category: category, depth: depth})
+			walk(category.Id, depth+1)
+		}
+	}
+	walk(0, 0)
+
+	return rows
+}
+
+// clearInputs clear its inputs and leaves edit mode.
+func (cp *CategoryPage) clearInputs() {
+	for i := range cp.allInputs {
+		cp.allInputs[i].Editor.SetText("")
+	}
+	cp.editingId = 0
+	cp.submitButton.Text = "Add"
+}
+
+// startEditing prefills its inputs with an existing category.
+func (cp *CategoryPage) startEditing(category domain.Category) {
+	cp.nameInput.Editor.SetText(category.Name)
+	cp.parentInput.Editor.SetText(cp.categoryName(category.ParentId))
+	cp.colorInput.Editor.SetText(category.Color)
+	cp.iconInput.Editor.SetText(category.Icon)
+	cp.editingId = category.Id
+	cp.submitButton.Text = "Save"
+}
+
+// categoryName returns the name of a category by its id.
+func (cp *CategoryPage) categoryName(id int) string {
+	for _, row := range cp.rows {
+		if row.category.Id == id {
+			return row.category.Name
+		}
+	}
+	return ""
+}
+
+// categoryWithId returns a category by its id.
+func (cp *CategoryPage) categoryWithId(id int) domain.Category {
+	for _, row := range cp.rows {
+		if row.category.Id == id {
+			return row.category
+		}
+	}
+	return domain.Category{}
+}
+
+// parentId returns the id of the category named like the parent input.
+func (cp *CategoryPage) parentId() (int, bool) {
+	name := domain.NormalizeCategoryName(cp.parentInput.Editor.Text())
+	if name == "" {
+		return 0, true
+	}
+
+	for _, row := range cp.rows {
+		if strings.EqualFold(row.category.Name, name) {
+			return row.category.Id, true
+		}
+	}
+
+	return 0, false
+}
+
+// Update updates data based on button clicks.
+func (cp *CategoryPage) Update() {
+	if cp.cancelButton.Button.Clicked() {
+		cp.clearInputs()
+		cp.messageLabel.Text = ""
+	}
+
+	if cp.submitButton.Button.Clicked() {
+		parentId, ok := cp.parentId()
+		if !ok {
+			cp.messageLabel.Text = "Parent category does not exist."
+			return
+		}
+
+		category := cp.categoryWithId(cp.editingId)
+		category.Name = cp.nameInput.Editor.Text()
+		category.ParentId = parentId
+		category.Color = strings.TrimSpace(cp.colorInput.Editor.Text())
+		category.Icon = strings.TrimSpace(cp.iconInput.Editor.Text())
+
+		var err error
+		if cp.editingId != 0 {
+			err = cp.controller.UpdateCategory(category)
+		} else {
+			err = cp.controller.AddCategory(category)
+		}
+		if err != nil {
+			cp.messageLabel.Text = err.Error()
+			return
+		}
+
+		cp.messageLabel.Text = ""
+		cp.clearInputs()
+		cp.Reload()
+		return
+	}
+
+	for i := range cp.rows {
+		if cp.editButtons[i].Button.Clicked() {
+			cp.startEditing(cp.rows[i].category)
+			return
+		}
+
+		if cp.archiveButtons[i].Button.Clicked() {
+			category := cp.rows[i].category
+			category.Archived = !category.Archived
+			if err := cp.controller.UpdateCategory(category); err != nil {
+				cp.messageLabel.Text = err.Error()
+				return
+			}
+			cp.Reload()
+			return
+		}
+
+		if cp.deleteButtons[i].Button.Clicked() {
+			if err := cp.controller.RemoveCategory(cp.rows[i].category.Id); err != nil {
+				cp.messageLabel.Text = err.Error()
+				return
+			}
+			if cp.editingId == cp.rows[i].category.Id {
+				cp.clearInputs()
+			}
+			cp.Reload()
+			return
+		}
+	}
+}
+
+// parseHexColor returns the color of a #rrggbb string, grey if invalid.
+func parseHexColor(hex string) color.NRGBA {
+	value, err := strconv.ParseUint(strings.TrimPrefix(hex, "#"), 16, 32)
+	if len(hex) != 7 || err != nil {
+		return color.NRGBA{120, 120, 130, 255}
+	}
+	return color.NRGBA{uint8(value >> 16), uint8(value >> 8), uint8(value), 255}
+}
+
+// Layout returns its layout.
+func (cp *CategoryPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(10)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(10))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(20)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return cp.list.Layout(gtx, len(cp.rows), func(gtx layout.Context, i int) layout.Dimensions {
+					row := cp.rows[i]
+					cp.nameLabel.Text = strings.TrimSpace(row.category.Icon + " " + row.category.Name)
+					if row.category.Archived {
+						cp.nameLabel.Text += " (archived)"
+					}
+					return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+						paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+						return layout.Flex{
+							Axis: layout.Horizontal,
+						}.Layout(gtx,
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10 + 20*float32(row.depth))}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									size := image.Pt(gtx.Dp(16), gtx.Dp(16))
+									paint.FillShape(gtx.Ops, parseHexColor(row.category.Color), clip.Rect{Max: size}.Op())
+									return layout.Dimensions{Size: size}
+								})
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, cp.nameLabel.Layout)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, cp.editButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, cp.archiveButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, cp.deleteButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						)
+					})
+				})
+			}),
+			input(&cp.nameInput),
+			input(&cp.parentInput),
+			input(&cp.colorInput),
+			input(&cp.iconInput),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, cp.messageLabel.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis: layout.Horizontal,
+					}.Layout(gtx,
+						layout.Flexed(1, cp.submitButton.Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Flexed(1, cp.cancelButton.Layout),
+					)
+				})
+			}),
+		)
+	})
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"image"
 	"image/color"
-	"strconv"
 
 	"gioui.org/layout"
 	"gioui.org/op/clip"
@@ -21,13 +20,24 @@ type DataDisplay struct {
 	cancelBudget  material.ButtonStyle
 	submitBudget  material.ButtonStyle
 	inputBudget   material.EditorStyle
+	inputCategory material.EditorStyle
 	editBudget    material.ButtonStyle
 	budgetLabel   material.LabelStyle
 	totalLabel    material.LabelStyle
 	leftoverLabel material.LabelStyle
+	incomeLabel   material.LabelStyle
+	netLabel      material.LabelStyle
+	savingsLabel  material.LabelStyle
+	categoryLabel material.LabelStyle
+	messageLabel  material.LabelStyle
+	rollover      *widget.Enum
+	rolloverRadio []material.RadioButtonStyle
+	inputCap      material.EditorStyle
+	saveRollover  material.ButtonStyle
+	history       HistoryPanel
 	state         State
 	controller    domain.API
-	monthData     *domain.MonthData
+	monthData     *MonthView
 }
 
 type State int
@@ -40,43 +50,196 @@ const (
 // Update updates data based on button clicks
 func (d *DataDisplay) Update() {
 	if d.editBudget.Button.Clicked() {
+		d.loadRollover()
+		d.history.close()
 		d.state = Editing
 	}
 
+	if d.state == Editing {
+		d.history.Update()
+	}
+
+	if d.saveRollover.Button.Clicked() {
+		settings := domain.RolloverSettings{Mode: domain.RolloverMode(d.rollover.Value)}
+		if d.inputCap.Editor.Text() != "" {
+			limit, err := domain.ParseMoney(d.inputCap.Editor.Text())
+			if err != nil {
+				d.messageLabel.Text = err.Error()
+				return
+			}
+			settings.Cap = limit
+		}
+
+		if err := d.controller.UpdateRolloverSettings(settings); err != nil {
+			d.messageLabel.Text = err.Error()
+			return
+		}
+
+		d.messageLabel.Text = ""
+		d.monthData.Reload(d.controller)
+	}
+
 	if d.cancelBudget.Button.Clicked() {
 		d.inputBudget.Editor.SetText("")
+		d.inputCategory.Editor.SetText("")
+		d.messageLabel.Text = ""
 		d.state = Visual
 	}
 
 	if d.submitBudget.Button.Clicked() {
 		money := d.inputBudget.Editor.Text()
 
-		_, err := strconv.ParseFloat(money, 64)
+		_, err := domain.ParseMoney(money)
 		if err != nil {
 			d.inputBudget.Editor.SetText("")
 			return
 		}
 
 		date := fmt.Sprintf("%d-%02d", d.monthData.Year, d.monthData.Month)
-		d.controller.InsertBudgetMonth(money, date)
+		category := d.inputCategory.Editor.Text()
 
-		if d.checkBox.CheckBox.Value == true {
-			d.controller.UpdateDefaultBudget(money)
-			d.checkBox.CheckBox.Value = false
+		// An empty category sets the budget of the whole month.
+		// Checked, the amount becomes the default budget too,
+		// undone along with the month.
+		asDefault := d.checkBox.CheckBox.Value
+		switch {
+		case category == "" && asDefault:
+			err = d.controller.InsertBudgetMonthAndDefault(money, date)
+		case category == "":
+			err = d.controller.InsertBudgetMonth(money, date)
+		case asDefault:
+			err = d.controller.InsertCategoryBudgetMonthAndDefault(category, money, date)
+		default:
+			err = d.controller.InsertCategoryBudgetMonth(category, money, date)
+		}
+		if err != nil {
+			d.messageLabel.Text = err.Error()
+			return
 		}
+		d.checkBox.CheckBox.Value = false
 
 		d.inputBudget.Editor.SetText("")
-		*d.monthData = d.controller.CreateMonthData(d.monthData.Year, d.monthData.Month)
+		d.inputCategory.Editor.SetText("")
+		d.messageLabel.Text = ""
+		d.monthData.Reload(d.controller)
 		d.state = Visual
 	}
 
-	d.budgetLabel.Text = fmt.Sprintf("Budget: %.2f", d.monthData.Budget)
-	d.totalLabel.Text = fmt.Sprintf("Total: %.2f", d.monthData.TotalSpendings)
-	d.leftoverLabel.Text = fmt.Sprintf("Leftover: %.2f", d.monthData.MoneyLeft)
+	if d.monthData.Err != nil {
+		d.budgetLabel.Text = "Budget: -"
+		d.totalLabel.Text = "Total: -"
+		d.leftoverLabel.Text = "Leftover: -"
+		d.incomeLabel.Text = "Income: -"
+		d.netLabel.Text = "Net: -"
+		d.savingsLabel.Text = "Saved: -"
+		return
+	}
+
+	d.budgetLabel.Text = fmt.Sprintf("Budget: %s", d.monthData.Budget)
+	if d.monthData.CarriedOver > 0 {
+		d.budgetLabel.Text += fmt.Sprintf(" (%s + %s carried)", d.monthData.BaseBudget, d.monthData.CarriedOver)
+	} else if d.monthData.CarriedOver < 0 {
+		d.budgetLabel.Text += fmt.Sprintf(" (%s - %s carried)", d.monthData.BaseBudget, -d.monthData.CarriedOver)
+	}
+	d.totalLabel.Text = fmt.Sprintf("Total: %s", d.monthData.TotalSpendings)
+	d.leftoverLabel.Text = fmt.Sprintf("Leftover: %s", d.monthData.MoneyLeft)
+	d.incomeLabel.Text = fmt.Sprintf("Income: %s", d.monthData.TotalIncome)
+	d.netLabel.Text = fmt.Sprintf("Net: %s", d.monthData.NetCashFlow)
+	d.savingsLabel.Text = fmt.Sprintf("Saved: %.0f%%", d.monthData.SavingsRate*100)
+}
+
+// loadRollover fills the rollover inputs with the current settings.
+func (d *DataDisplay) loadRollover() {
+	settings, err := d.controller.GetRolloverSettings()
+	if err != nil {
+		d.messageLabel.Text = err.Error()
+		return
+	}
+
+	d.rollover.Value = string(settings.Mode)
+	d.inputCap.Editor.SetText("")
+	if settings.Cap != 0 {
+		d.inputCap.Editor.SetText(settings.Cap.String())
+	}
 }
 
-// Layout returns its layout.
+// Layout returns its layout: the month summary with
+// the per category breakdown beneath it.
 func (d *DataDisplay) Layout(gtx layout.Context) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(d.layoutSummary),
+		layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+	}
+
+	if d.state == Editing {
+		children = append(children,
+			layout.Rigid(d.layoutRollover),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Left: unit.Dp(25), Right: unit.Dp(25)}.Layout(gtx, d.history.Layout)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+		)
+	}
+
+	children = append(children,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:    layout.Horizontal,
+				Spacing: layout.SpaceEvenly,
+			}.Layout(gtx,
+				layout.Rigid(d.incomeLabel.Layout),
+				layout.Rigid(d.netLabel.Layout),
+				layout.Rigid(d.savingsLabel.Layout),
+			)
+		}),
+	)
+
+	if d.messageLabel.Text != "" {
+		children = append(children,
+			layout.Rigid(layout.Spacer{Height: unit.Dp(6)}.Layout),
+			layout.Rigid(d.messageLabel.Layout),
+		)
+	}
+
+	if len(d.monthData.Categories) > 0 {
+		children = append(children, layout.Rigid(layout.Spacer{Height: unit.Dp(10)}.Layout))
+	}
+
+	for i := range d.monthData.Categories {
+		category := d.monthData.Categories[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(25), Right: unit.Dp(25)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis: layout.Horizontal,
+				}.Layout(gtx,
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						d.categoryLabel.Text = category.Category
+						d.categoryLabel.Alignment = text.Start
+						return d.categoryLabel.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						d.categoryLabel.Text = fmt.Sprintf("%s / %s", category.Spent, category.Budget)
+						d.categoryLabel.Alignment = text.End
+						return d.categoryLabel.Layout(gtx)
+					}),
+					layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+						d.categoryLabel.Text = fmt.Sprintf("Left: %s", category.Remaining)
+						d.categoryLabel.Alignment = text.End
+						return d.categoryLabel.Layout(gtx)
+					}),
+				)
+			})
+		}))
+	}
+
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx, children...)
+}
+
+// layoutSummary returns the layout of the budget, total and leftover row.
+func (d *DataDisplay) layoutSummary(gtx layout.Context) layout.Dimensions {
 	if d.state == Editing {
 		return layout.Flex{
 			Axis:    layout.Horizontal,
@@ -103,6 +266,15 @@ func (d *DataDisplay) Layout(gtx layout.Context) layout.Dimensions {
 								return d.inputBudget.Layout(gtx)
 							},
 						),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Rigid(
+							func(gtx layout.Context) layout.Dimensions {
+								dims := d.inputCategory.Layout(gtx)
+								r := clip.Rect{Max: dims.Size}
+								paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+								return d.inputCategory.Layout(gtx)
+							},
+						),
 					)
 				},
 			),
@@ -140,18 +312,71 @@ func (d *DataDisplay) Layout(gtx layout.Context) layout.Dimensions {
 	)
 }
 
+// layoutRollover returns the layout of the rollover settings row.
+func (d *DataDisplay) layoutRollover(gtx layout.Context) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	}
+
+	for i := range d.rolloverRadio {
+		children = append(children, layout.Rigid(d.rolloverRadio[i].Layout))
+	}
+
+	children = append(children,
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Rigid(
+			func(gtx layout.Context) layout.Dimensions {
+				dims := d.inputCap.Layout(gtx)
+				r := clip.Rect{Max: dims.Size}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return d.inputCap.Layout(gtx)
+			},
+		),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+		layout.Rigid(d.saveRollover.Layout),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	)
+
+	return layout.Flex{
+		Axis:      layout.Horizontal,
+		Alignment: layout.Middle,
+	}.Layout(gtx, children...)
+}
+
 // createDataDisplay returns DataDisplay struct.
-func createDataDisplay(th *material.Theme, controller domain.API, monthData *domain.MonthData) DataDisplay {
+func createDataDisplay(th *material.Theme, controller domain.API, monthData *MonthView) DataDisplay {
 	inputBudget := material.Editor(th, &widget.Editor{}, "0.00")
+	inputCategory := material.Editor(th, &widget.Editor{}, "category")
 	checkBox := material.CheckBox(th, &widget.Bool{}, "Default")
 	cancelBudget := material.Button(th, &widget.Clickable{}, "Cancel")
 	submitBudget := material.Button(th, &widget.Clickable{}, "Submit")
 	editBudget := material.Button(th, &widget.Clickable{}, "Edit")
-	budgetLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Budget: %.2f", 0.00))
-	totalLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Total: %.2f", 0.00))
-	leftoverLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Leftover: %.2f", 0.00))
+	budgetLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Budget: %s", domain.Money(0)))
+	totalLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Total: %s", domain.Money(0)))
+	leftoverLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Leftover: %s", domain.Money(0)))
+	incomeLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Income: %s", domain.Money(0)))
+	netLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("Net: %s", domain.Money(0)))
+	savingsLabel := material.Label(th, unit.Sp(16), "Saved: 0%")
+	categoryLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+	messageLabel.Alignment = text.Middle
 	state := Visual
 
+	rollover := &widget.Enum{Value: string(domain.RolloverNone)}
+	rolloverRadio := []material.RadioButtonStyle{}
+	for _, mode := range []domain.RolloverMode{domain.RolloverNone, domain.RolloverFull, domain.RolloverCapped, domain.RolloverNegative} {
+		rolloverRadio = append(rolloverRadio, material.RadioButton(th, rollover, string(mode), string(mode)))
+	}
+	inputCap := material.Editor(th, &widget.Editor{}, "cap")
+	saveRollover := material.Button(th, &widget.Clickable{}, "Save rollover")
+	saveRollover.Background = color.NRGBA{53, 53, 113, 255}
+
+	// The history follows the month shown.
+	history := createHistoryPanel(th, func() ([]domain.AuditEntry, error) {
+		return controller.GetBudgetHistory(fmt.Sprintf("%d-%02d", monthData.Year, monthData.Month))
+	})
+
 	submitBudget.Background = color.NRGBA{53, 53, 113, 255}
 	cancelBudget.Background = color.NRGBA{113, 53, 53, 255}
 	editBudget.Background = color.NRGBA{53, 53, 113, 255}
@@ -161,12 +386,27 @@ func createDataDisplay(th *material.Theme, controller domain.API, monthData *dom
 	inputBudget.Color = color.NRGBA{235, 235, 235, 255}
 	inputBudget.HintColor = color.NRGBA{255, 255, 255, 40}
 
+	inputCategory.Editor.Alignment = text.Middle
+	inputCategory.Editor.SingleLine = true
+	inputCategory.Color = color.NRGBA{235, 235, 235, 255}
+	inputCategory.HintColor = color.NRGBA{255, 255, 255, 40}
+
+	inputCap.Editor.Alignment = text.Middle
+	inputCap.Editor.SingleLine = true
+	inputCap.Color = color.NRGBA{235, 235, 235, 255}
+	inputCap.HintColor = color.NRGBA{255, 255, 255, 40}
+
 	budgetLabel.MaxLines = 1
 	totalLabel.MaxLines = 1
 	leftoverLabel.MaxLines = 1
+	incomeLabel.MaxLines = 1
+	netLabel.MaxLines = 1
+	savingsLabel.MaxLines = 1
+	categoryLabel.MaxLines = 1
 
 	return DataDisplay{
 		inputBudget:   inputBudget,
+		inputCategory: inputCategory,
 		checkBox:      checkBox,
 		cancelBudget:  cancelBudget,
 		submitBudget:  submitBudget,
@@ -174,6 +414,16 @@ func createDataDisplay(th *material.Theme, controller domain.API, monthData *dom
 		budgetLabel:   budgetLabel,
 		totalLabel:    totalLabel,
 		leftoverLabel: leftoverLabel,
+		incomeLabel:   incomeLabel,
+		netLabel:      netLabel,
+		savingsLabel:  savingsLabel,
+		categoryLabel: categoryLabel,
+		messageLabel:  messageLabel,
+		rollover:      rollover,
+		rolloverRadio: rolloverRadio,
+		inputCap:      inputCap,
+		saveRollover:  saveRollover,
+		history:       history,
 		state:         state,
 		controller:    controller,
 		monthData:     monthData,
@@ -0,0 +1,223 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+)
+
+// ExportPage writes the expenses and budgets of a date range to a file,
+// and the report of a month.
+type ExportPage struct {
+	theme         *material.Theme
+	messageLabel  material.LabelStyle
+	pathInput     material.EditorStyle
+	fromInput     material.EditorStyle
+	toInput       material.EditorStyle
+	currencyInput material.EditorStyle
+	format        *widget.Enum
+	formatRadios  []material.RadioButtonStyle
+	exportButton  material.ButtonStyle
+	monthInput    material.EditorStyle
+	reportInput   material.EditorStyle
+	reportButton  material.ButtonStyle
+	allInputs     []*material.EditorStyle
+	controller    domain.API
+}
+
+// createExportPage returns ExportPage struct.
+func createExportPage(th *material.Theme, controller domain.API) ExportPage {
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	pathInput := material.Editor(th, &widget.Editor{}, "path of the exported file")
+	fromInput := material.Editor(th, &widget.Editor{}, "from (YYYY-MM-DD, YYYY-MM or YYYY)")
+	toInput := material.Editor(th, &widget.Editor{}, "to (YYYY-MM-DD, YYYY-MM or YYYY)")
+	currencyInput := material.Editor(th, &widget.Editor{}, "currency (needed by beancount)")
+	monthInput := material.Editor(th, &widget.Editor{}, "month of the report (YYYY-MM)")
+	reportInput := material.Editor(th, &widget.Editor{}, "path of the report (.html or .pdf)")
+
+	inputs := []*material.EditorStyle{
+		&pathInput,
+		&fromInput,
+		&toInput,
+		&currencyInput,
+		&monthInput,
+		&reportInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	format := &widget.Enum{Value: ""}
+	formatRadios := []material.RadioButtonStyle{
+		material.RadioButton(th, format, "", "by extension"),
+	}
+	for _, value := range []domain.ExportFormat{domain.ExportCSV, domain.ExportJSON, domain.ExportLedger, domain.ExportBeancount} {
+		formatRadios = append(formatRadios, material.RadioButton(th, format, string(value), string(value)))
+	}
+
+	exportButton := material.Button(th, &widget.Clickable{}, "Export")
+	exportButton.Background = color.NRGBA{53, 53, 113, 255}
+	reportButton := material.Button(th, &widget.Clickable{}, "Write report")
+	reportButton.Background = color.NRGBA{3, 106, 102, 255}
+
+	return ExportPage{
+		theme:         th,
+		messageLabel:  messageLabel,
+		pathInput:     pathInput,
+		fromInput:     fromInput,
+		toInput:       toInput,
+		currencyInput: currencyInput,
+		format:        format,
+		formatRadios:  formatRadios,
+		exportButton:  exportButton,
+		monthInput:    monthInput,
+		reportInput:   reportInput,
+		reportButton:  reportButton,
+		allInputs:     inputs,
+		controller:    controller,
+	}
+}
+
+// Reload clears the last message and fills an empty range with the
+// current year and an empty month with the current one.
+func (ep *ExportPage) Reload() {
+	ep.messageLabel.Text = ""
+
+	year := fmt.Sprint(time.Now().Year())
+	if ep.fromInput.Editor.Text() == "" && ep.toInput.Editor.Text() == "" {
+		ep.fromInput.Editor.SetText(year)
+		ep.toInput.Editor.SetText(year)
+	}
+
+	if ep.monthInput.Editor.Text() == "" {
+		ep.monthInput.Editor.SetText(time.Now().Format("2006-01"))
+	}
+}
+
+// showMessage shows the outcome of the last click, in red when failed.
+func (ep *ExportPage) showMessage(text string, failed bool) {
+	ep.messageLabel.Color = color.NRGBA{113, 235, 113, 255}
+	if failed {
+		ep.messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+	}
+	ep.messageLabel.Text = text
+}
+
+// Update updates data based on button clicks.
+func (ep *ExportPage) Update() {
+	if ep.reportButton.Button.Clicked() {
+		path := ep.reportInput.Editor.Text()
+
+		month, err := time.Parse("2006-01", strings.TrimSpace(ep.monthInput.Editor.Text()))
+		if err != nil {
+			ep.showMessage("Month should be YYYY-MM.", true)
+			return
+		}
+
+		if err := ep.controller.WriteMonthReport(month.Year(), month.Month(), path); err != nil {
+			ep.showMessage(err.Error(), true)
+			return
+		}
+
+		ep.showMessage(fmt.Sprintf("Wrote the report of %s %d to %s.", month.Month(), month.Year(), path), false)
+		return
+	}
+
+	if !ep.exportButton.Button.Clicked() {
+		return
+	}
+
+	path := ep.pathInput.Editor.Text()
+	count, err := ep.controller.Export(path, domain.ExportOptions{
+		From:     ep.fromInput.Editor.Text(),
+		To:       ep.toInput.Editor.Text(),
+		Format:   domain.ExportFormat(ep.format.Value),
+		Currency: ep.currencyInput.Editor.Text(),
+	})
+	if err != nil {
+		ep.showMessage(err.Error(), true)
+		return
+	}
+
+	ep.showMessage(fmt.Sprintf("Exported %d expenses to %s.", count, path), false)
+}
+
+// Layout returns its layout.
+func (ep *ExportPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(8)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	inputRow := func(children ...layout.FlexChild) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+			})
+		})
+	}
+
+	radios := []layout.FlexChild{}
+	for i := range ep.formatRadios {
+		radios = append(radios, layout.Rigid(ep.formatRadios[i].Layout))
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			inputRow(input(&ep.pathInput)),
+			inputRow(input(&ep.fromInput), input(&ep.toInput), input(&ep.currencyInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, radios...)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, ep.exportButton.Layout)
+			}),
+			layout.Rigid(layout.Spacer{Height: unit.Dp(24)}.Layout),
+			inputRow(input(&ep.monthInput), input(&ep.reportInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, ep.reportButton.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, ep.messageLabel.Layout)
+			}),
+		)
+	})
+}
@@ -0,0 +1,248 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// maxHistoryLines is the number of latest changes a HistoryPanel shows.
+const maxHistoryLines = 8
+
+// HistoryPanel shows, once opened, the latest changes
+// recorded in the audit log for a record.
+type HistoryPanel struct {
+	toggleButton material.ButtonStyle
+	lineLabel    material.LabelStyle
+	messageLabel material.LabelStyle
+	lines        []string
+	open         bool
+	fetch        func() ([]domain.AuditEntry, error)
+}
+
+// createHistoryPanel returns HistoryPanel struct, fetch returning
+// the changes to show.
+func createHistoryPanel(th *material.Theme, fetch func() ([]domain.AuditEntry, error)) HistoryPanel {
+	toggleButton := material.Button(th, &widget.Clickable{}, "History")
+	toggleButton.Background = color.NRGBA{53, 53, 113, 255}
+
+	lineLabel := material.Label(th, unit.Sp(14), "")
+	lineLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	return HistoryPanel{
+		toggleButton: toggleButton,
+		lineLabel:    lineLabel,
+		messageLabel: messageLabel,
+		fetch:        fetch,
+	}
+}
+
+// reload fetches the changes again.
+func (hp *HistoryPanel) reload() {
+	hp.lines = []string{}
+	hp.messageLabel.Text = ""
+
+	entries, err := hp.fetch()
+	if err != nil {
+		logger.Error(err.Error())
+		hp.messageLabel.Text = "Could not load history: " + err.Error()
+		return
+	}
+
+	if len(entries) == 0 {
+		hp.messageLabel.Text = "No change recorded."
+	}
+
+	for i, entry := range entries {
+		if i == maxHistoryLines {
+			hp.lines = append(hp.lines, fmt.Sprintf("... %d older changes", len(entries)-i))
+			break
+		}
+		hp.lines = append(hp.lines, describeAuditEntry(entry))
+	}
+}
+
+// close hides the changes.
+func (hp *HistoryPanel) close() {
+	hp.open = false
+	hp.lines = nil
+	hp.toggleButton.Text = "History"
+}
+
+// Update updates data based on button clicks.
+func (hp *HistoryPanel) Update() {
+	if !hp.toggleButton.Button.Clicked() {
+		return
+	}
+
+	if hp.open {
+		hp.close()
+		return
+	}
+
+	hp.open = true
+	hp.toggleButton.Text = "Hide history"
+	hp.reload()
+}
+
+// describeAuditEntry returns a one line description of a change:
+// when, who and what.
+func describeAuditEntry(entry domain.AuditEntry) string {
+	when := entry.Timestamp
+	if t, err := time.Parse(time.RFC3339, when); err == nil {
+		when = t.Local().Format("2006-01-02 15:04")
+	}
+
+	who := entry.User
+	if who == "" {
+		who = "someone"
+	}
+
+	what := string(entry.Action)
+	switch entry.Entity {
+	case domain.AuditExpense:
+		what = describeExpenseChange(entry)
+	case domain.AuditBudget, domain.AuditCategoryBudget:
+		what = describeBudgetChange(entry)
+	}
+
+	return fmt.Sprintf("%s %s: %s", when, who, what)
+}
+
+// describeExpenseChange returns what an audit entry of an expense changed.
+func describeExpenseChange(entry domain.AuditEntry) string {
+	var before, after struct {
+		Date      string            `json:"date"`
+		Name      string            `json:"name"`
+		Kind      string            `json:"kind"`
+		Amount    auditAmount       `json:"amount"`
+		Category  string            `json:"category"`
+		Account   string            `json:"account"`
+		ToAccount string            `json:"to_account"`
+		Payee     string            `json:"payee"`
+		Tags      []string          `json:"tags"`
+		Splits    []json.RawMessage `json:"splits"`
+	}
+	json.Unmarshal([]byte(entry.Before), &before)
+	json.Unmarshal([]byte(entry.After), &after)
+
+	switch entry.Action {
+	case domain.AuditInsert:
+		return fmt.Sprintf("added %q %s", after.Name, after.Amount)
+	case domain.AuditDelete:
+		return "moved to the trash"
+	case domain.AuditRestore:
+		return "restored from the trash"
+	case domain.AuditPurge:
+		return "deleted for good"
+	}
+
+	changes := []string{}
+	change := func(field, from, to string) {
+		if from != to {
+			changes = append(changes, fmt.Sprintf("%s %s -> %s", field, orNone(from), orNone(to)))
+		}
+	}
+
+	change("name", before.Name, after.Name)
+	change("date", before.Date, after.Date)
+	change("amount", string(before.Amount), string(after.Amount))
+	change("kind", before.Kind, after.Kind)
+	change("category", before.Category, after.Category)
+	change("account", before.Account, after.Account)
+	change("to", before.ToAccount, after.ToAccount)
+	change("payee", before.Payee, after.Payee)
+	change("tags", strings.Join(before.Tags, ", "), strings.Join(after.Tags, ", "))
+	if len(before.Splits) != len(after.Splits) {
+		changes = append(changes, fmt.Sprintf("splits %d -> %d", len(before.Splits), len(after.Splits)))
+	}
+
+	if len(changes) == 0 {
+		return "saved without change"
+	}
+
+	return strings.Join(changes, ", ")
+}
+
+// auditAmount is an amount in the audit log, written as text,
+// or as cents by older versions.
+type auditAmount string
+
+func (a *auditAmount) UnmarshalJSON(data []byte) error {
+	cents := domain.Money(0)
+	if err := json.Unmarshal(data, &cents); err == nil {
+		*a = auditAmount(cents.String())
+		return nil
+	}
+
+	text := ""
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*a = auditAmount(text)
+
+	return nil
+}
+
+// describeBudgetChange returns what an audit entry of a budget changed.
+func describeBudgetChange(entry domain.AuditEntry) string {
+	var before, after struct {
+		Category string `json:"category"`
+		Amount   string `json:"amount"`
+	}
+	json.Unmarshal([]byte(entry.Before), &before)
+	json.Unmarshal([]byte(entry.After), &after)
+
+	subject := "budget"
+	if entry.Entity == domain.AuditCategoryBudget {
+		subject = after.Category
+		if subject == "" {
+			subject = before.Category
+		}
+	}
+
+	if entry.Key == "default" {
+		subject = "default " + subject
+	}
+
+	return fmt.Sprintf("%s %s -> %s", subject, orNone(before.Amount), orNone(after.Amount))
+}
+
+// Layout returns its layout: the toggle button and, once opened,
+// the changes beneath it.
+func (hp *HistoryPanel) Layout(gtx layout.Context) layout.Dimensions {
+	children := []layout.FlexChild{
+		layout.Rigid(hp.toggleButton.Layout),
+	}
+
+	if hp.open {
+		for i := range hp.lines {
+			line := hp.lines[i]
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					hp.lineLabel.Text = line
+					return hp.lineLabel.Layout(gtx)
+				})
+			}))
+		}
+
+		if hp.messageLabel.Text != "" {
+			children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Inset{Top: unit.Dp(4)}.Layout(gtx, hp.messageLabel.Layout)
+			}))
+		}
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
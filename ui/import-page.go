@@ -0,0 +1,521 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// ImportPage reads a bank statement with a profile, previews the
+// expenses it holds and imports the selected ones.
+type ImportPage struct {
+	list             material.ListStyle
+	theme            *material.Theme
+	rowLabel         material.LabelStyle
+	messageLabel     material.LabelStyle
+	pathInput        material.EditorStyle
+	profileInput     material.EditorStyle
+	delimiterInput   material.EditorStyle
+	encodingInput    material.EditorStyle
+	dateInput        material.EditorStyle
+	nameInput        material.EditorStyle
+	amountInput      material.EditorStyle
+	categoryInput    material.EditorStyle
+	accountInput     material.EditorStyle
+	hasHeader        material.CheckBoxStyle
+	expensesPositive material.CheckBoxStyle
+	dateOrder        *widget.Enum
+	dateRadios       []material.RadioButtonStyle
+	decimal          *widget.Enum
+	decimalRadios    []material.RadioButtonStyle
+	previewButton    material.ButtonStyle
+	saveButton       material.ButtonStyle
+	importButton     material.ButtonStyle
+	closeButton      material.ButtonStyle
+	useButtons       []material.ButtonStyle
+	deleteButtons    []material.ButtonStyle
+	rowChecks        []material.CheckBoxStyle
+	allInputs        []*material.EditorStyle
+	profiles         []domain.ImportProfile
+	accounts         []domain.Account
+	preview          domain.ImportPreview
+	previewing       bool
+	controller       domain.API
+}
+
+// createImportPage returns ImportPage struct.
+func createImportPage(th *material.Theme, controller domain.API) ImportPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	rowLabel := material.Label(th, unit.Sp(16), "")
+	rowLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	pathInput := material.Editor(th, &widget.Editor{}, "path of a CSV, OFX, QFX or QIF statement")
+	profileInput := material.Editor(th, &widget.Editor{}, "profile name")
+	delimiterInput := material.Editor(th, &widget.Editor{}, "delimiter (detected)")
+	encodingInput := material.Editor(th, &widget.Editor{}, "encoding (detected)")
+	dateInput := material.Editor(th, &widget.Editor{}, "date column")
+	nameInput := material.Editor(th, &widget.Editor{}, "name column")
+	amountInput := material.Editor(th, &widget.Editor{}, "amount column")
+	categoryInput := material.Editor(th, &widget.Editor{}, "category column (optional)")
+	accountInput := material.Editor(th, &widget.Editor{}, "account (optional)")
+
+	inputs := []*material.EditorStyle{
+		&pathInput,
+		&profileInput,
+		&delimiterInput,
+		&encodingInput,
+		&dateInput,
+		&nameInput,
+		&amountInput,
+		&categoryInput,
+		&accountInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	hasHeader := material.CheckBox(th, &widget.Bool{Value: true}, "Header line")
+	expensesPositive := material.CheckBox(th, &widget.Bool{}, "Expenses are positive")
+
+	dateOrder := &widget.Enum{Value: string(domain.DateYMD)}
+	dateRadios := []material.RadioButtonStyle{}
+	for _, value := range []domain.DateOrder{domain.DateYMD, domain.DateDMY, domain.DateMDY} {
+		dateRadios = append(dateRadios, material.RadioButton(th, dateOrder, string(value), string(value)))
+	}
+
+	decimal := &widget.Enum{Value: ""}
+	decimalRadios := []material.RadioButtonStyle{
+		material.RadioButton(th, decimal, "", "auto"),
+		material.RadioButton(th, decimal, ".", "12.34"),
+		material.RadioButton(th, decimal, ",", "12,34"),
+	}
+
+	previewButton := material.Button(th, &widget.Clickable{}, "Preview")
+	previewButton.Background = color.NRGBA{53, 53, 113, 255}
+	saveButton := material.Button(th, &widget.Clickable{}, "Save profile")
+	saveButton.Background = color.NRGBA{3, 106, 102, 255}
+	importButton := material.Button(th, &widget.Clickable{}, "Import")
+	importButton.Background = color.NRGBA{53, 53, 113, 255}
+	closeButton := material.Button(th, &widget.Clickable{}, "Close")
+	closeButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	return ImportPage{
+		list:             material.List(th, &list),
+		theme:            th,
+		rowLabel:         rowLabel,
+		messageLabel:     messageLabel,
+		pathInput:        pathInput,
+		profileInput:     profileInput,
+		delimiterInput:   delimiterInput,
+		encodingInput:    encodingInput,
+		dateInput:        dateInput,
+		nameInput:        nameInput,
+		amountInput:      amountInput,
+		categoryInput:    categoryInput,
+		accountInput:     accountInput,
+		hasHeader:        hasHeader,
+		expensesPositive: expensesPositive,
+		dateOrder:        dateOrder,
+		dateRadios:       dateRadios,
+		decimal:          decimal,
+		decimalRadios:    decimalRadios,
+		previewButton:    previewButton,
+		saveButton:       saveButton,
+		importButton:     importButton,
+		closeButton:      closeButton,
+		allInputs:        inputs,
+		controller:       controller,
+	}
+}
+
+// Reload fetches import profiles and accounts from controller
+// and rebuilds the rows.
+func (ip *ImportPage) Reload() {
+	profiles, err := ip.controller.GetImportProfiles()
+	if err != nil {
+		logger.Error(err.Error())
+		ip.messageLabel.Text = "Could not load import profiles: " + err.Error()
+		profiles = []domain.ImportProfile{}
+	}
+
+	accounts, err := ip.controller.GetAccounts()
+	if err != nil {
+		logger.Error(err.Error())
+		ip.messageLabel.Text = "Could not load accounts: " + err.Error()
+		accounts = []domain.Account{}
+	}
+
+	ip.profiles = profiles
+	ip.accounts = accounts
+	ip.previewing = false
+	ip.useButtons = []material.ButtonStyle{}
+	ip.deleteButtons = []material.ButtonStyle{}
+
+	for range ip.profiles {
+		useButton := material.Button(ip.theme, &widget.Clickable{}, "use")
+		useButton.Background = color.NRGBA{53, 53, 113, 255}
+		deleteButton := material.Button(ip.theme, &widget.Clickable{}, "x")
+		deleteButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		ip.useButtons = append(ip.useButtons, useButton)
+		ip.deleteButtons = append(ip.deleteButtons, deleteButton)
+	}
+}
+
+// useProfile prefills its inputs with a saved profile.
+func (ip *ImportPage) useProfile(profile domain.ImportProfile) {
+	delimiter := profile.Delimiter
+	if delimiter == "\t" {
+		delimiter = "tab"
+	}
+
+	account := ""
+	for _, a := range ip.accounts {
+		if a.Id == profile.AccountId {
+			account = a.Name
+		}
+	}
+
+	ip.profileInput.Editor.SetText(profile.Name)
+	ip.delimiterInput.Editor.SetText(delimiter)
+	ip.encodingInput.Editor.SetText(profile.Encoding)
+	ip.dateInput.Editor.SetText(profile.DateColumn)
+	ip.nameInput.Editor.SetText(profile.NameColumn)
+	ip.amountInput.Editor.SetText(profile.AmountColumn)
+	ip.categoryInput.Editor.SetText(profile.CategoryColumn)
+	ip.accountInput.Editor.SetText(account)
+	ip.hasHeader.CheckBox.Value = profile.HasHeader
+	ip.expensesPositive.CheckBox.Value = profile.ExpensesPositive
+	ip.dateOrder.Value = string(profile.DateOrder)
+	ip.decimal.Value = profile.DecimalSeparator
+}
+
+// profileFromInputs returns the profile described by its inputs.
+func (ip *ImportPage) profileFromInputs() (domain.ImportProfile, error) {
+	profile := domain.ImportProfile{
+		Name:             ip.profileInput.Editor.Text(),
+		Delimiter:        ip.delimiterInput.Editor.Text(),
+		Encoding:         ip.encodingInput.Editor.Text(),
+		HasHeader:        ip.hasHeader.CheckBox.Value,
+		DateColumn:       ip.dateInput.Editor.Text(),
+		NameColumn:       ip.nameInput.Editor.Text(),
+		AmountColumn:     ip.amountInput.Editor.Text(),
+		CategoryColumn:   ip.categoryInput.Editor.Text(),
+		DateOrder:        domain.DateOrder(ip.dateOrder.Value),
+		DecimalSeparator: ip.decimal.Value,
+		ExpensesPositive: ip.expensesPositive.CheckBox.Value,
+	}
+
+	account := strings.TrimSpace(ip.accountInput.Editor.Text())
+	if account == "" {
+		return profile, nil
+	}
+
+	for _, a := range ip.accounts {
+		if strings.EqualFold(a.Name, account) {
+			profile.AccountId = a.Id
+			return profile, nil
+		}
+	}
+
+	return profile, fmt.Errorf("Account %q does not exist.", account)
+}
+
+// showPreview lists the rows of a preview, the ones not skipped checked.
+func (ip *ImportPage) showPreview(preview domain.ImportPreview) {
+	ip.preview = preview
+	ip.previewing = true
+	ip.rowChecks = []material.CheckBoxStyle{}
+
+	for _, row := range preview.Rows {
+		check := material.CheckBox(ip.theme, &widget.Bool{Value: !row.Skip}, "")
+		ip.rowChecks = append(ip.rowChecks, check)
+	}
+
+	ip.updateImportButton()
+}
+
+// updateImportButton shows how many rows are checked on the import button.
+func (ip *ImportPage) updateImportButton() {
+	count := 0
+	for i := range ip.rowChecks {
+		if ip.rowChecks[i].CheckBox.Value {
+			count++
+		}
+	}
+	ip.importButton.Text = fmt.Sprintf("Import %d expenses", count)
+}
+
+// Update updates data based on button clicks.
+func (ip *ImportPage) Update() {
+	if ip.previewButton.Button.Clicked() {
+		profile, err := ip.profileFromInputs()
+		if err != nil {
+			ip.messageLabel.Text = err.Error()
+			return
+		}
+
+		preview, err := ip.controller.PreviewImport(ip.pathInput.Editor.Text(), profile)
+		if err != nil {
+			ip.messageLabel.Text = err.Error()
+			return
+		}
+
+		ip.messageLabel.Text = ""
+		ip.showPreview(preview)
+		return
+	}
+
+	if ip.saveButton.Button.Clicked() {
+		profile, err := ip.profileFromInputs()
+		if err == nil {
+			err = ip.controller.SaveImportProfile(profile)
+		}
+		if err != nil {
+			ip.messageLabel.Text = err.Error()
+			return
+		}
+
+		ip.messageLabel.Text = ""
+		ip.Reload()
+		return
+	}
+
+	if ip.closeButton.Button.Clicked() {
+		ip.previewing = false
+		return
+	}
+
+	if ip.previewing {
+		for i := range ip.rowChecks {
+			if ip.rowChecks[i].CheckBox.Changed() {
+				if ip.preview.Rows[i].Error != "" {
+					ip.rowChecks[i].CheckBox.Value = false
+				}
+				ip.updateImportButton()
+			}
+		}
+	}
+
+	if ip.importButton.Button.Clicked() {
+		rows := []domain.ImportRow{}
+		for i, row := range ip.preview.Rows {
+			row.Skip = !ip.rowChecks[i].CheckBox.Value
+			rows = append(rows, row)
+		}
+
+		count, err := ip.controller.ImportExpenses(rows)
+		if err != nil {
+			ip.messageLabel.Text = err.Error()
+			return
+		}
+
+		ip.messageLabel.Text = fmt.Sprintf("Imported %d expenses.", count)
+		ip.Reload()
+		return
+	}
+
+	for i := range ip.profiles {
+		if ip.useButtons[i].Button.Clicked() {
+			ip.useProfile(ip.profiles[i])
+			ip.messageLabel.Text = ""
+			return
+		}
+
+		if ip.deleteButtons[i].Button.Clicked() {
+			if err := ip.controller.RemoveImportProfile(ip.profiles[i].Name); err != nil {
+				ip.messageLabel.Text = err.Error()
+				return
+			}
+			ip.Reload()
+			return
+		}
+	}
+}
+
+// describeImportRow returns a short description of a row for the preview.
+func describeImportRow(row domain.ImportRow) string {
+	if row.Error != "" {
+		return fmt.Sprintf("line %d: %s", row.Line, row.Error)
+	}
+
+	expense := row.Expense
+	amount := expense.Amount.String()
+	if expense.IsIncome() {
+		amount = "+" + amount
+	}
+
+	description := fmt.Sprintf("%s %s %s", expense.Date, expense.Name, amount)
+	if expense.Category != "" {
+		description += " [" + expense.Category + "]"
+	}
+	if row.Duplicate.Id != 0 {
+		description += fmt.Sprintf(" duplicate of %s %s", row.Duplicate.Date, row.Duplicate.Name)
+	}
+
+	return description
+}
+
+// describeImportProfile returns a short description of a profile for its row.
+func describeImportProfile(profile domain.ImportProfile) string {
+	return fmt.Sprintf("%s: %s, %s, %s (%s)", profile.Name, profile.DateColumn, profile.NameColumn, profile.AmountColumn, profile.DateOrder)
+}
+
+// Layout returns its layout.
+func (ip *ImportPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(8)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	inputRow := func(children ...layout.FlexChild) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+			})
+		})
+	}
+
+	buttonRow := func(left, right *material.ButtonStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis: layout.Horizontal,
+				}.Layout(gtx,
+					layout.Flexed(1, left.Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, right.Layout),
+				)
+			})
+		})
+	}
+
+	row := func(gtx layout.Context, children ...layout.FlexChild) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+			paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+			children = append([]layout.FlexChild{layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout)}, children...)
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout))
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		})
+	}
+
+	button := func(b *material.ButtonStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return topBottomMargins.Layout(gtx, b.Layout)
+			})
+		})
+	}
+
+	message := layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return marginTop.Layout(gtx, ip.messageLabel.Layout)
+	})
+
+	if ip.previewing {
+		return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return ip.list.Layout(gtx, len(ip.preview.Rows), func(gtx layout.Context, i int) layout.Dimensions {
+						ip.rowLabel.Text = describeImportRow(ip.preview.Rows[i])
+						return row(gtx,
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, ip.rowChecks[i].Layout)
+							}),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, ip.rowLabel.Layout)
+							}),
+						)
+					})
+				}),
+				message,
+				buttonRow(&ip.importButton, &ip.closeButton),
+			)
+		})
+	}
+
+	radios := []layout.FlexChild{}
+	for i := range ip.dateRadios {
+		radios = append(radios, layout.Rigid(ip.dateRadios[i].Layout))
+	}
+	for i := range ip.decimalRadios {
+		radios = append(radios, layout.Rigid(ip.decimalRadios[i].Layout))
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return ip.list.Layout(gtx, len(ip.profiles), func(gtx layout.Context, i int) layout.Dimensions {
+					ip.rowLabel.Text = describeImportProfile(ip.profiles[i])
+					return row(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return topBottomMargins.Layout(gtx, ip.rowLabel.Layout)
+						}),
+						button(&ip.useButtons[i]),
+						button(&ip.deleteButtons[i]),
+					)
+				})
+			}),
+			inputRow(input(&ip.pathInput)),
+			inputRow(input(&ip.profileInput), input(&ip.delimiterInput), input(&ip.encodingInput)),
+			inputRow(input(&ip.dateInput), input(&ip.nameInput), input(&ip.amountInput)),
+			inputRow(input(&ip.categoryInput), input(&ip.accountInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, radios...)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx,
+						layout.Rigid(ip.hasHeader.Layout),
+						layout.Rigid(ip.expensesPositive.Layout),
+					)
+				})
+			}),
+			message,
+			buttonRow(&ip.previewButton, &ip.saveButton),
+		)
+	})
+}
@@ -13,6 +13,7 @@ import (
 	"gioui.org/widget"
 	"gioui.org/widget/material"
 	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
 )
 
 type ListContainer struct {
@@ -22,23 +23,23 @@ type ListContainer struct {
 	dateLabel     material.LabelStyle
 	categoryLabel material.LabelStyle
 	amountLabel   material.LabelStyle
+	errorLabel    material.LabelStyle
+	messageLabel  material.LabelStyle
 
 	deleteButtons []material.ButtonStyle
+	editButtons   []material.ButtonStyle
+	openButtons   []material.ButtonStyle
 	controller    domain.API
-	monthView     *domain.MonthData
+	monthView     *MonthView
+	currentPage   *Page
+	formPage      *FormPage
 }
 
 // TODO handle this mess better.
 // Update updates the list and monthView.
 func (c *ListContainer) Update() {
 	if len(c.deleteButtons) != len(c.monthView.Expenses) {
-		delButtons := []material.ButtonStyle{}
-		for _ = range c.monthView.Expenses {
-			delButton := material.Button(c.theme, &widget.Clickable{}, "x")
-			delButton.Background = color.NRGBA{113, 53, 53, 255}
-			delButtons = append(delButtons, delButton)
-		}
-		c.deleteButtons = delButtons
+		c.deleteButtons, c.editButtons, c.openButtons = createRowButtons(c.theme, len(c.monthView.Expenses))
 		return
 	}
 
@@ -47,25 +48,92 @@ func (c *ListContainer) Update() {
 		return
 	}
 
+	for i := range c.editButtons {
+		if c.editButtons[i].Button.Clicked() {
+			c.formPage.startEditing((c.monthView.Expenses)[i])
+			*c.currentPage = Add
+			return
+		}
+	}
+
+	for i := range c.openButtons {
+		if c.openButtons[i].Button.Clicked() {
+			c.openAttachments((c.monthView.Expenses)[i].Id)
+			return
+		}
+	}
+
 	for i := range c.deleteButtons {
 		if c.deleteButtons[i].Button.Clicked() {
-			c.controller.RemoveExpense((c.monthView.Expenses)[i].Id)
-
-			*c.monthView = c.controller.CreateMonthData(c.monthView.Year, c.monthView.Month)
-			delButtons := []material.ButtonStyle{}
-			for _ = range c.monthView.Expenses {
-				delButton := material.Button(c.theme, &widget.Clickable{}, "x")
-				delButton.Background = color.NRGBA{113, 53, 53, 255}
-				delButtons = append(delButtons, delButton)
+			c.messageLabel.Text = ""
+			if err := c.controller.RemoveExpense((c.monthView.Expenses)[i].Id); err != nil {
+				c.messageLabel.Text = err.Error()
 			}
-			c.deleteButtons = delButtons
+
+			c.monthView.Reload(c.controller)
+			c.deleteButtons, c.editButtons, c.openButtons = createRowButtons(c.theme, len(c.monthView.Expenses))
 			break
 		}
 	}
 }
 
-// Layout returns its layout.
+// openAttachments opens every attachment of an expense
+// with the default application of the system.
+func (c *ListContainer) openAttachments(expenseId int) {
+	attachments, err := c.controller.GetAttachments(expenseId)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	for _, attachment := range attachments {
+		if err := openFile(c.controller.AttachmentPath(attachment)); err != nil {
+			logger.Error(err.Error())
+		}
+	}
+}
+
+// createRowButtons returns a delete, an edit and an open attachments
+// button for each row.
+func createRowButtons(th *material.Theme, count int) ([]material.ButtonStyle, []material.ButtonStyle, []material.ButtonStyle) {
+	delButtons := []material.ButtonStyle{}
+	editButtons := []material.ButtonStyle{}
+	openButtons := []material.ButtonStyle{}
+
+	for i := 0; i < count; i++ {
+		delButton := material.Button(th, &widget.Clickable{}, "x")
+		delButton.Background = color.NRGBA{113, 53, 53, 255}
+		delButtons = append(delButtons, delButton)
+
+		editButton := material.Button(th, &widget.Clickable{}, "e")
+		editButton.Background = color.NRGBA{53, 53, 113, 255}
+		editButtons = append(editButtons, editButton)
+
+		openButton := material.Button(th, &widget.Clickable{}, "open")
+		openButton.Background = color.NRGBA{3, 106, 102, 255}
+		openButtons = append(openButtons, openButton)
+	}
+
+	return delButtons, editButtons, openButtons
+}
+
+// Layout returns its layout: the list, beneath the message of the last
+// failed change if any.
 func (c *ListContainer) Layout(gtx layout.Context) layout.Dimensions {
+	if c.messageLabel.Text == "" {
+		return c.layoutList(gtx)
+	}
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx,
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Top: unit.Dp(10), Left: unit.Dp(25), Right: unit.Dp(25)}.Layout(gtx, c.messageLabel.Layout)
+		}),
+		layout.Flexed(1, c.layoutList),
+	)
+}
+
+// layoutList returns the layout of the list of expenses.
+func (c *ListContainer) layoutList(gtx layout.Context) layout.Dimensions {
 	margins := layout.Inset{
 		Top:    unit.Dp(25),
 		Bottom: unit.Dp(25),
@@ -102,11 +170,26 @@ func (c *ListContainer) Layout(gtx layout.Context) layout.Dimensions {
 		func(gtx layout.Context) layout.Dimensions {
 			return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 				return insideBorderMargins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					if c.monthView.Err != nil {
+						c.errorLabel.Text = "Could not load this month: " + c.monthView.Err.Error()
+						return c.errorLabel.Layout(gtx)
+					}
 					return c.list.Layout(gtx, len(c.monthView.Expenses), func(gtx layout.Context, i int) layout.Dimensions {
 						c.nameLabel.Text = (c.monthView.Expenses)[i].Name
 						c.dateLabel.Text = (c.monthView.Expenses)[i].Date
 						c.categoryLabel.Text = (c.monthView.Expenses)[i].Category
-						c.amountLabel.Text = fmt.Sprintf("%.2f", (c.monthView.Expenses)[i].Amount)
+						if (c.monthView.Expenses)[i].IsTransfer() {
+							c.categoryLabel.Text = (c.monthView.Expenses)[i].Account + " > " + (c.monthView.Expenses)[i].ToAccount
+						}
+						if len((c.monthView.Expenses)[i].Splits) > 0 {
+							c.categoryLabel.Text = fmt.Sprintf("Split (%d)", len((c.monthView.Expenses)[i].Splits))
+						}
+						c.amountLabel.Text = (c.monthView.Expenses)[i].Amount.String()
+						c.amountLabel.Color = c.theme.Fg
+						if (c.monthView.Expenses)[i].IsIncome() {
+							c.amountLabel.Text = "+" + c.amountLabel.Text
+							c.amountLabel.Color = color.NRGBA{113, 200, 113, 255}
+						}
 						return bottomMargin.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 							r2 := clip.Rect{
 								Min: image.Pt(0, 0),
@@ -130,6 +213,24 @@ func (c *ListContainer) Layout(gtx layout.Context) layout.Dimensions {
 								layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
 									return topBottomMargins.Layout(gtx, c.amountLabel.Layout)
 								}),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									// Only expenses with attachments can be opened.
+									if len(c.monthView.Expenses) != len(c.openButtons) || (c.monthView.Expenses)[i].Attachments == 0 {
+										return layout.Dimensions{}
+									}
+									return layout.Inset{Right: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+										return topBottomMargins.Layout(gtx, c.openButtons[i].Layout)
+									})
+								}),
+								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+									// Don't show buttons if there is a difference between
+									// length of expenses vs length of buttons
+									if len(c.monthView.Expenses) != len(c.editButtons) {
+										return layout.Dimensions{}
+									}
+									return topBottomMargins.Layout(gtx, c.editButtons[i].Layout)
+								}),
+								layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
 								layout.Rigid(func(gtx layout.Context) layout.Dimensions {
 									// Don't show buttons if there is a difference between
 									// length of expenses vs length of buttons
@@ -149,7 +250,7 @@ func (c *ListContainer) Layout(gtx layout.Context) layout.Dimensions {
 }
 
 // createListContainer returns ListContainer struct.
-func createListContainer(th *material.Theme, monthData *domain.MonthData, controller domain.API) ListContainer {
+func createListContainer(th *material.Theme, currentPage *Page, monthData *MonthView, formPage *FormPage, controller domain.API) ListContainer {
 	var list widget.List
 	list.Axis = layout.Vertical
 	listWithStyle := material.List(th, &list)
@@ -157,8 +258,12 @@ func createListContainer(th *material.Theme, monthData *domain.MonthData, contro
 	nameLabel := material.Label(th, unit.Sp(16), "")
 	dateLabel := material.Label(th, unit.Sp(16), "")
 	categoryLabel := material.Label(th, unit.Sp(16), "")
-	amountLabel := material.Label(th, unit.Sp(16), fmt.Sprintf("%.2f", 0.00))
+	amountLabel := material.Label(th, unit.Sp(16), domain.Money(0).String())
 	amountLabel.Alignment = text.End
+	errorLabel := material.Label(th, unit.Sp(16), "")
+	errorLabel.Color = color.NRGBA{235, 113, 113, 255}
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
 
 	labels := []*material.LabelStyle{
 		&nameLabel,
@@ -171,13 +276,7 @@ func createListContainer(th *material.Theme, monthData *domain.MonthData, contro
 		labels[i].MaxLines = 1
 	}
 
-	delButtons := []material.ButtonStyle{}
-
-	for _ = range monthData.Expenses {
-		delButton := material.Button(th, &widget.Clickable{}, "x")
-		delButton.Background = color.NRGBA{113, 53, 53, 255}
-		delButtons = append(delButtons, delButton)
-	}
+	delButtons, editButtons, openButtons := createRowButtons(th, len(monthData.Expenses))
 
 	return ListContainer{
 		list:          listWithStyle,
@@ -186,8 +285,14 @@ func createListContainer(th *material.Theme, monthData *domain.MonthData, contro
 		dateLabel:     dateLabel,
 		categoryLabel: categoryLabel,
 		amountLabel:   amountLabel,
+		errorLabel:    errorLabel,
+		messageLabel:  messageLabel,
 		monthView:     monthData,
 		deleteButtons: delButtons,
+		editButtons:   editButtons,
+		openButtons:   openButtons,
 		controller:    controller,
+		currentPage:   currentPage,
+		formPage:      formPage,
 	}
 }
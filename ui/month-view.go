@@ -0,0 +1,35 @@
+package ui
+
+import (
+	"time"
+
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// MonthView holds the month shown by the UI and
+// the error of its last load, if any.
+type MonthView struct {
+	domain.MonthData
+	Err error
+}
+
+// Load fetches the data of a month from controller. On failure the view
+// keeps the year and month but no data, and Err is set.
+func (mv *MonthView) Load(controller domain.API, year int, month time.Month) {
+	monthData, err := controller.CreateMonthData(year, month)
+	if err != nil {
+		logger.Error(err.Error())
+		mv.MonthData = domain.MonthData{Year: year, Month: month}
+		mv.Err = err
+		return
+	}
+
+	mv.MonthData = monthData
+	mv.Err = nil
+}
+
+// Reload fetches the data of the current month again.
+func (mv *MonthView) Reload(controller domain.API) {
+	mv.Load(controller, mv.Year, mv.Month)
+}
@@ -0,0 +1,30 @@
+package ui
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+)
+
+// openFile opens a file with the default application of the system.
+func openFile(path string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", path)
+	case "darwin":
+		cmd = exec.Command("open", path)
+	default:
+		cmd = exec.Command("xdg-open", path)
+	}
+
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("Could not open %s: %w", path, err)
+	}
+
+	// Reap the process without blocking the interface.
+	go cmd.Wait()
+
+	return nil
+}
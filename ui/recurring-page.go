@@ -0,0 +1,413 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+type RecurringPage struct {
+	list           material.ListStyle
+	theme          *material.Theme
+	ruleLabel      material.LabelStyle
+	messageLabel   material.LabelStyle
+	nameInput      material.EditorStyle
+	amountInput    material.EditorStyle
+	categoryInput  material.EditorStyle
+	everyInput     material.EditorStyle
+	dayInput       material.EditorStyle
+	startInput     material.EditorStyle
+	endInput       material.EditorStyle
+	countInput     material.EditorStyle
+	interval       *widget.Enum
+	intervalRadios []material.RadioButtonStyle
+	updateUpcoming material.CheckBoxStyle
+	submitButton   material.ButtonStyle
+	cancelButton   material.ButtonStyle
+	editButtons    []material.ButtonStyle
+	pauseButtons   []material.ButtonStyle
+	deleteButtons  []material.ButtonStyle
+	allInputs      []*material.EditorStyle
+	rules          []domain.RecurringRule
+	editing        domain.RecurringRule
+	controller     domain.API
+}
+
+// createRecurringPage returns RecurringPage struct.
+func createRecurringPage(th *material.Theme, controller domain.API) RecurringPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	ruleLabel := material.Label(th, unit.Sp(16), "")
+	ruleLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	nameInput := material.Editor(th, &widget.Editor{}, "name")
+	amountInput := material.Editor(th, &widget.Editor{}, "amount")
+	categoryInput := material.Editor(th, &widget.Editor{}, "category")
+	everyInput := material.Editor(th, &widget.Editor{}, "every (1)")
+	dayInput := material.Editor(th, &widget.Editor{}, "day of month (optional)")
+	startInput := material.Editor(th, &widget.Editor{}, "start (YYYY-MM-DD)")
+	endInput := material.Editor(th, &widget.Editor{}, "end (YYYY-MM-DD, optional)")
+	countInput := material.Editor(th, &widget.Editor{}, "count (optional)")
+
+	inputs := []*material.EditorStyle{
+		&nameInput,
+		&amountInput,
+		&categoryInput,
+		&everyInput,
+		&dayInput,
+		&startInput,
+		&endInput,
+		&countInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	interval := &widget.Enum{Value: string(domain.Monthly)}
+	intervalRadios := []material.RadioButtonStyle{}
+
+	for _, value := range []domain.Interval{domain.Daily, domain.Weekly, domain.Monthly, domain.Yearly} {
+		intervalRadios = append(intervalRadios, material.RadioButton(th, interval, string(value), string(value)))
+	}
+
+	updateUpcoming := material.CheckBox(th, &widget.Bool{}, "Update upcoming")
+	submitButton := material.Button(th, &widget.Clickable{}, "Add")
+	submitButton.Background = color.NRGBA{53, 53, 113, 255}
+	cancelButton := material.Button(th, &widget.Clickable{}, "Cancel")
+	cancelButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	return RecurringPage{
+		list:           material.List(th, &list),
+		theme:          th,
+		ruleLabel:      ruleLabel,
+		messageLabel:   messageLabel,
+		nameInput:      nameInput,
+		amountInput:    amountInput,
+		categoryInput:  categoryInput,
+		everyInput:     everyInput,
+		dayInput:       dayInput,
+		startInput:     startInput,
+		endInput:       endInput,
+		countInput:     countInput,
+		interval:       interval,
+		intervalRadios: intervalRadios,
+		updateUpcoming: updateUpcoming,
+		submitButton:   submitButton,
+		cancelButton:   cancelButton,
+		allInputs:      inputs,
+		controller:     controller,
+	}
+}
+
+// Reload fetches recurring rules from controller and rebuilds the rows.
+func (rp *RecurringPage) Reload() {
+	rules, err := rp.controller.GetRecurringRules()
+	if err != nil {
+		logger.Error(err.Error())
+		rp.messageLabel.Text = "Could not load recurring expenses: " + err.Error()
+		rules = []domain.RecurringRule{}
+	}
+
+	rp.rules = rules
+	rp.editButtons = []material.ButtonStyle{}
+	rp.pauseButtons = []material.ButtonStyle{}
+	rp.deleteButtons = []material.ButtonStyle{}
+
+	for _, rule := range rp.rules {
+		editButton := material.Button(rp.theme, &widget.Clickable{}, "e")
+		editButton.Background = color.NRGBA{53, 53, 113, 255}
+
+		pauseText := "pause"
+		if rule.Paused {
+			pauseText = "resume"
+		}
+		pauseButton := material.Button(rp.theme, &widget.Clickable{}, pauseText)
+		pauseButton.Background = color.NRGBA{3, 106, 102, 255}
+
+		deleteButton := material.Button(rp.theme, &widget.Clickable{}, "x")
+		deleteButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		rp.editButtons = append(rp.editButtons, editButton)
+		rp.pauseButtons = append(rp.pauseButtons, pauseButton)
+		rp.deleteButtons = append(rp.deleteButtons, deleteButton)
+	}
+}
+
+// clearInputs clear its inputs and leaves edit mode.
+func (rp *RecurringPage) clearInputs() {
+	for i := range rp.allInputs {
+		rp.allInputs[i].Editor.SetText("")
+	}
+	rp.interval.Value = string(domain.Monthly)
+	rp.updateUpcoming.CheckBox.Value = false
+	rp.editing = domain.RecurringRule{}
+	rp.submitButton.Text = "Add"
+}
+
+// startEditing prefills its inputs with an existing rule.
+func (rp *RecurringPage) startEditing(rule domain.RecurringRule) {
+	rp.nameInput.Editor.SetText(rule.Name)
+	rp.amountInput.Editor.SetText(rule.Amount.String())
+	rp.categoryInput.Editor.SetText(rule.Category)
+	rp.everyInput.Editor.SetText(strconv.Itoa(rule.Every))
+	rp.dayInput.Editor.SetText(formatOptionalNumber(rule.DayOfMonth))
+	rp.startInput.Editor.SetText(rule.StartDate)
+	rp.endInput.Editor.SetText(rule.EndDate)
+	rp.countInput.Editor.SetText(formatOptionalNumber(rule.Count))
+	rp.interval.Value = string(rule.Interval)
+	rp.editing = rule
+	rp.submitButton.Text = "Save"
+}
+
+// formatOptionalNumber returns "" for 0 and the number otherwise.
+func formatOptionalNumber(number int) string {
+	if number == 0 {
+		return ""
+	}
+	return strconv.Itoa(number)
+}
+
+// parseOptionalNumber returns 0 for an empty string and the number otherwise.
+func parseOptionalNumber(name, number string) (int, error) {
+	number = strings.TrimSpace(number)
+	if number == "" {
+		return 0, nil
+	}
+
+	parsed, err := strconv.Atoi(number)
+	if err != nil {
+		return 0, fmt.Errorf("%s should be a number.", name)
+	}
+
+	return parsed, nil
+}
+
+// ruleFromInputs returns the rule being edited updated with its inputs.
+func (rp *RecurringPage) ruleFromInputs() (domain.RecurringRule, error) {
+	rule := rp.editing
+
+	amount, err := domain.ParseMoney(rp.amountInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	every, err := parseOptionalNumber("Every", rp.everyInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	day, err := parseOptionalNumber("Day of month", rp.dayInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	count, err := parseOptionalNumber("Count", rp.countInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	rule.Name = strings.TrimSpace(rp.nameInput.Editor.Text())
+	rule.Amount = amount
+	rule.Category = rp.categoryInput.Editor.Text()
+	rule.CategoryId = 0
+	rule.Interval = domain.Interval(rp.interval.Value)
+	rule.Every = every
+	rule.DayOfMonth = day
+	rule.StartDate = strings.TrimSpace(rp.startInput.Editor.Text())
+	rule.EndDate = strings.TrimSpace(rp.endInput.Editor.Text())
+	rule.Count = count
+
+	return rule, nil
+}
+
+// Update updates data based on button clicks.
+func (rp *RecurringPage) Update() {
+	if rp.cancelButton.Button.Clicked() {
+		rp.clearInputs()
+		rp.messageLabel.Text = ""
+	}
+
+	if rp.submitButton.Button.Clicked() {
+		rule, err := rp.ruleFromInputs()
+		if err == nil {
+			if rule.Id != 0 {
+				err = rp.controller.UpdateRecurringRule(rule, rp.updateUpcoming.CheckBox.Value)
+			} else {
+				err = rp.controller.AddRecurringRule(rule)
+			}
+		}
+		if err != nil {
+			rp.messageLabel.Text = err.Error()
+			return
+		}
+
+		rp.messageLabel.Text = ""
+		rp.clearInputs()
+		rp.Reload()
+		return
+	}
+
+	for i := range rp.rules {
+		if rp.editButtons[i].Button.Clicked() {
+			rp.startEditing(rp.rules[i])
+			return
+		}
+
+		if rp.pauseButtons[i].Button.Clicked() {
+			rule := rp.rules[i]
+			rule.Paused = !rule.Paused
+			if err := rp.controller.UpdateRecurringRule(rule, false); err != nil {
+				rp.messageLabel.Text = err.Error()
+				return
+			}
+			rp.Reload()
+			return
+		}
+
+		if rp.deleteButtons[i].Button.Clicked() {
+			if err := rp.controller.RemoveRecurringRule(rp.rules[i].Id); err != nil {
+				rp.messageLabel.Text = err.Error()
+				return
+			}
+			if rp.editing.Id == rp.rules[i].Id {
+				rp.clearInputs()
+			}
+			rp.Reload()
+			return
+		}
+	}
+}
+
+// describeRule returns a short description of a rule for its row.
+func describeRule(rule domain.RecurringRule) string {
+	schedule := string(rule.Interval)
+	if rule.Every > 1 {
+		schedule = fmt.Sprintf("every %d %s", rule.Every, rule.Interval)
+	}
+
+	description := fmt.Sprintf("%s  %s  %s from %s", rule.Name, rule.Amount, schedule, rule.StartDate)
+	if rule.Paused {
+		description += " (paused)"
+	}
+
+	return description
+}
+
+// Layout returns its layout.
+func (rp *RecurringPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(8)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	inputRow := func(children ...layout.FlexChild) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+			})
+		})
+	}
+
+	radios := []layout.FlexChild{}
+	for i := range rp.intervalRadios {
+		radios = append(radios, layout.Rigid(rp.intervalRadios[i].Layout))
+	}
+	if rp.editing.Id != 0 {
+		radios = append(radios, layout.Flexed(1, layout.Spacer{}.Layout), layout.Rigid(rp.updateUpcoming.Layout))
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return rp.list.Layout(gtx, len(rp.rules), func(gtx layout.Context, i int) layout.Dimensions {
+					rp.ruleLabel.Text = describeRule(rp.rules[i])
+					return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+						r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+						paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+						return layout.Flex{
+							Axis: layout.Horizontal,
+						}.Layout(gtx,
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+							layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, rp.ruleLabel.Layout)
+							}),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, rp.editButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, rp.pauseButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout),
+							layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+								return topBottomMargins.Layout(gtx, rp.deleteButtons[i].Layout)
+							}),
+							layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						)
+					})
+				})
+			}),
+			inputRow(input(&rp.nameInput), input(&rp.amountInput), input(&rp.categoryInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, radios...)
+				})
+			}),
+			inputRow(input(&rp.everyInput), input(&rp.dayInput), input(&rp.countInput)),
+			inputRow(input(&rp.startInput), input(&rp.endInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, rp.messageLabel.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis: layout.Horizontal,
+					}.Layout(gtx,
+						layout.Flexed(1, rp.submitButton.Layout),
+						layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+						layout.Flexed(1, rp.cancelButton.Layout),
+					)
+				})
+			}),
+		)
+	})
+}
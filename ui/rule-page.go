@@ -0,0 +1,489 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+type RulePage struct {
+	list          material.ListStyle
+	theme         *material.Theme
+	ruleLabel     material.LabelStyle
+	messageLabel  material.LabelStyle
+	patternInput  material.EditorStyle
+	minInput      material.EditorStyle
+	maxInput      material.EditorStyle
+	categoryInput material.EditorStyle
+	payeeInput    material.EditorStyle
+	tagsInput     material.EditorStyle
+	matchType     *widget.Enum
+	matchRadios   []material.RadioButtonStyle
+	submitButton  material.ButtonStyle
+	cancelButton  material.ButtonStyle
+	previewButton material.ButtonStyle
+	applyButton   material.ButtonStyle
+	closeButton   material.ButtonStyle
+	upButtons     []material.ButtonStyle
+	downButtons   []material.ButtonStyle
+	editButtons   []material.ButtonStyle
+	deleteButtons []material.ButtonStyle
+	allInputs     []*material.EditorStyle
+	rules         []domain.CategorizationRule
+	changes       []domain.RuleChange
+	previewing    bool
+	editing       domain.CategorizationRule
+	controller    domain.API
+}
+
+// createRulePage returns RulePage struct.
+func createRulePage(th *material.Theme, controller domain.API) RulePage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	ruleLabel := material.Label(th, unit.Sp(16), "")
+	ruleLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	patternInput := material.Editor(th, &widget.Editor{}, "expense name pattern")
+	minInput := material.Editor(th, &widget.Editor{}, "min amount (optional)")
+	maxInput := material.Editor(th, &widget.Editor{}, "max amount (optional)")
+	categoryInput := material.Editor(th, &widget.Editor{}, "category")
+	payeeInput := material.Editor(th, &widget.Editor{}, "payee")
+	tagsInput := material.Editor(th, &widget.Editor{}, "tags (comma separated)")
+
+	inputs := []*material.EditorStyle{
+		&patternInput,
+		&minInput,
+		&maxInput,
+		&categoryInput,
+		&payeeInput,
+		&tagsInput,
+	}
+
+	for i := range inputs {
+		inputs[i].Editor.Alignment = text.Middle
+		inputs[i].Editor.SingleLine = true
+		inputs[i].Color = color.NRGBA{235, 235, 235, 255}
+		inputs[i].HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	matchType := &widget.Enum{Value: string(domain.MatchExact)}
+	matchRadios := []material.RadioButtonStyle{}
+
+	for _, value := range []domain.MatchType{domain.MatchExact, domain.MatchPrefix, domain.MatchRegex} {
+		matchRadios = append(matchRadios, material.RadioButton(th, matchType, string(value), string(value)))
+	}
+
+	submitButton := material.Button(th, &widget.Clickable{}, "Add")
+	submitButton.Background = color.NRGBA{53, 53, 113, 255}
+	cancelButton := material.Button(th, &widget.Clickable{}, "Cancel")
+	cancelButton.Background = color.NRGBA{113, 53, 53, 255}
+	previewButton := material.Button(th, &widget.Clickable{}, "Re-apply to past expenses")
+	previewButton.Background = color.NRGBA{3, 106, 102, 255}
+	applyButton := material.Button(th, &widget.Clickable{}, "Apply")
+	applyButton.Background = color.NRGBA{53, 53, 113, 255}
+	closeButton := material.Button(th, &widget.Clickable{}, "Close")
+	closeButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	return RulePage{
+		list:          material.List(th, &list),
+		theme:         th,
+		ruleLabel:     ruleLabel,
+		messageLabel:  messageLabel,
+		patternInput:  patternInput,
+		minInput:      minInput,
+		maxInput:      maxInput,
+		categoryInput: categoryInput,
+		payeeInput:    payeeInput,
+		tagsInput:     tagsInput,
+		matchType:     matchType,
+		matchRadios:   matchRadios,
+		submitButton:  submitButton,
+		cancelButton:  cancelButton,
+		previewButton: previewButton,
+		applyButton:   applyButton,
+		closeButton:   closeButton,
+		allInputs:     inputs,
+		controller:    controller,
+	}
+}
+
+// Reload fetches categorization rules from controller and rebuilds the rows.
+func (rp *RulePage) Reload() {
+	rules, err := rp.controller.GetCategorizationRules()
+	if err != nil {
+		logger.Error(err.Error())
+		rp.messageLabel.Text = "Could not load rules: " + err.Error()
+		rules = []domain.CategorizationRule{}
+	}
+
+	rp.rules = rules
+	rp.previewing = false
+	rp.changes = nil
+	rp.upButtons = []material.ButtonStyle{}
+	rp.downButtons = []material.ButtonStyle{}
+	rp.editButtons = []material.ButtonStyle{}
+	rp.deleteButtons = []material.ButtonStyle{}
+
+	for range rp.rules {
+		upButton := material.Button(rp.theme, &widget.Clickable{}, "up")
+		upButton.Background = color.NRGBA{3, 106, 102, 255}
+		downButton := material.Button(rp.theme, &widget.Clickable{}, "down")
+		downButton.Background = color.NRGBA{3, 106, 102, 255}
+		editButton := material.Button(rp.theme, &widget.Clickable{}, "e")
+		editButton.Background = color.NRGBA{53, 53, 113, 255}
+		deleteButton := material.Button(rp.theme, &widget.Clickable{}, "x")
+		deleteButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		rp.upButtons = append(rp.upButtons, upButton)
+		rp.downButtons = append(rp.downButtons, downButton)
+		rp.editButtons = append(rp.editButtons, editButton)
+		rp.deleteButtons = append(rp.deleteButtons, deleteButton)
+	}
+}
+
+// clearInputs clear its inputs and leaves edit mode.
+func (rp *RulePage) clearInputs() {
+	for i := range rp.allInputs {
+		rp.allInputs[i].Editor.SetText("")
+	}
+	rp.matchType.Value = string(domain.MatchExact)
+	rp.editing = domain.CategorizationRule{}
+	rp.submitButton.Text = "Add"
+}
+
+// startEditing prefills its inputs with an existing rule.
+func (rp *RulePage) startEditing(rule domain.CategorizationRule) {
+	rp.patternInput.Editor.SetText(rule.Pattern)
+	rp.minInput.Editor.SetText(formatOptionalMoney(rule.MinAmount))
+	rp.maxInput.Editor.SetText(formatOptionalMoney(rule.MaxAmount))
+	rp.categoryInput.Editor.SetText(rule.Category)
+	rp.payeeInput.Editor.SetText(rule.Payee)
+	rp.tagsInput.Editor.SetText(strings.Join(rule.Tags, ", "))
+	rp.matchType.Value = string(rule.MatchType)
+	rp.editing = rule
+	rp.submitButton.Text = "Save"
+}
+
+// formatOptionalMoney returns "" for 0 and the amount otherwise.
+func formatOptionalMoney(amount domain.Money) string {
+	if amount == 0 {
+		return ""
+	}
+	return amount.String()
+}
+
+// parseOptionalMoney returns 0 for an empty string and the amount otherwise.
+func parseOptionalMoney(amount string) (domain.Money, error) {
+	if strings.TrimSpace(amount) == "" {
+		return 0, nil
+	}
+	return domain.ParseMoney(amount)
+}
+
+// ruleFromInputs returns the rule being edited updated with its inputs.
+func (rp *RulePage) ruleFromInputs() (domain.CategorizationRule, error) {
+	rule := rp.editing
+
+	minAmount, err := parseOptionalMoney(rp.minInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	maxAmount, err := parseOptionalMoney(rp.maxInput.Editor.Text())
+	if err != nil {
+		return rule, err
+	}
+
+	rule.MatchType = domain.MatchType(rp.matchType.Value)
+	rule.Pattern = rp.patternInput.Editor.Text()
+	rule.MinAmount = minAmount
+	rule.MaxAmount = maxAmount
+	rule.Category = rp.categoryInput.Editor.Text()
+	rule.CategoryId = 0
+	rule.Payee = rp.payeeInput.Editor.Text()
+	rule.PayeeId = 0
+	rule.Tags = []string{rp.tagsInput.Editor.Text()}
+
+	return rule, nil
+}
+
+// Update updates data based on button clicks.
+func (rp *RulePage) Update() {
+	if rp.cancelButton.Button.Clicked() {
+		rp.clearInputs()
+		rp.messageLabel.Text = ""
+	}
+
+	if rp.previewButton.Button.Clicked() {
+		changes, err := rp.controller.PreviewReapplyRules()
+		if err != nil {
+			rp.messageLabel.Text = err.Error()
+			return
+		}
+		rp.changes = changes
+		rp.previewing = true
+		rp.applyButton.Text = fmt.Sprintf("Apply %d changes", len(changes))
+		rp.messageLabel.Text = ""
+		return
+	}
+
+	if rp.closeButton.Button.Clicked() {
+		rp.previewing = false
+		rp.changes = nil
+		return
+	}
+
+	if rp.applyButton.Button.Clicked() {
+		if err := rp.controller.ApplyRuleChanges(rp.changes); err != nil {
+			rp.messageLabel.Text = err.Error()
+			return
+		}
+		rp.messageLabel.Text = ""
+		rp.Reload()
+		return
+	}
+
+	if rp.submitButton.Button.Clicked() {
+		rule, err := rp.ruleFromInputs()
+		if err == nil {
+			if rule.Id != 0 {
+				err = rp.controller.UpdateCategorizationRule(rule)
+			} else {
+				err = rp.controller.AddCategorizationRule(rule)
+			}
+		}
+		if err != nil {
+			rp.messageLabel.Text = err.Error()
+			return
+		}
+
+		rp.messageLabel.Text = ""
+		rp.clearInputs()
+		rp.Reload()
+		return
+	}
+
+	for i := range rp.rules {
+		offset := 0
+		if rp.upButtons[i].Button.Clicked() {
+			offset = -1
+		}
+		if rp.downButtons[i].Button.Clicked() {
+			offset = 1
+		}
+		if offset != 0 {
+			if err := rp.controller.MoveCategorizationRule(rp.rules[i].Id, offset); err != nil {
+				rp.messageLabel.Text = err.Error()
+				return
+			}
+			rp.Reload()
+			return
+		}
+
+		if rp.editButtons[i].Button.Clicked() {
+			rp.startEditing(rp.rules[i])
+			return
+		}
+
+		if rp.deleteButtons[i].Button.Clicked() {
+			if err := rp.controller.RemoveCategorizationRule(rp.rules[i].Id); err != nil {
+				rp.messageLabel.Text = err.Error()
+				return
+			}
+			if rp.editing.Id == rp.rules[i].Id {
+				rp.clearInputs()
+			}
+			rp.Reload()
+			return
+		}
+	}
+}
+
+// describeCategorizationRule returns a short description of a rule for its row.
+func describeCategorizationRule(rule domain.CategorizationRule) string {
+	description := fmt.Sprintf("%d. %s \"%s\"", rule.Priority, rule.MatchType, rule.Pattern)
+
+	if rule.MinAmount != 0 || rule.MaxAmount != 0 {
+		description += fmt.Sprintf(" [%s..%s]", formatOptionalMoney(rule.MinAmount), formatOptionalMoney(rule.MaxAmount))
+	}
+
+	targets := []string{}
+	if rule.Category != "" {
+		targets = append(targets, rule.Category)
+	}
+	if rule.Payee != "" {
+		targets = append(targets, "payee "+rule.Payee)
+	}
+	if len(rule.Tags) > 0 {
+		targets = append(targets, "#"+strings.Join(rule.Tags, " #"))
+	}
+
+	return description + " -> " + strings.Join(targets, ", ")
+}
+
+// describeRuleChange returns a short description of a change for its row.
+func describeRuleChange(change domain.RuleChange) string {
+	before, after := change.Before, change.After
+	description := before.Date + " " + before.Name + ":"
+
+	if before.CategoryId != after.CategoryId {
+		description += fmt.Sprintf(" %s -> %s", orNone(before.Category), orNone(after.Category))
+	}
+	if before.PayeeId != after.PayeeId {
+		description += fmt.Sprintf(" payee %s -> %s", orNone(before.Payee), orNone(after.Payee))
+	}
+	if tags := strings.Join(after.Tags, ", "); tags != strings.Join(before.Tags, ", ") {
+		description += fmt.Sprintf(" tags %s -> %s", orNone(strings.Join(before.Tags, ", ")), tags)
+	}
+
+	return description
+}
+
+// orNone returns "none" for an empty string and the string otherwise.
+func orNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+	return value
+}
+
+// Layout returns its layout.
+func (rp *RulePage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(8)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4), Left: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	inputRow := func(children ...layout.FlexChild) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+			})
+		})
+	}
+
+	buttonRow := func(left, right *material.ButtonStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis: layout.Horizontal,
+				}.Layout(gtx,
+					layout.Flexed(1, left.Layout),
+					layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+					layout.Flexed(1, right.Layout),
+				)
+			})
+		})
+	}
+
+	row := func(gtx layout.Context, children ...layout.FlexChild) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+			paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+			children = append([]layout.FlexChild{layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout)}, children...)
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout))
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		})
+	}
+
+	button := func(b *material.ButtonStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return topBottomMargins.Layout(gtx, b.Layout)
+			})
+		})
+	}
+
+	if rp.previewing {
+		return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis: layout.Vertical,
+			}.Layout(gtx,
+				layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+					return rp.list.Layout(gtx, len(rp.changes), func(gtx layout.Context, i int) layout.Dimensions {
+						rp.ruleLabel.Text = describeRuleChange(rp.changes[i])
+						return row(gtx, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return topBottomMargins.Layout(gtx, rp.ruleLabel.Layout)
+						}))
+					})
+				}),
+				layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+					return marginTop.Layout(gtx, rp.messageLabel.Layout)
+				}),
+				buttonRow(&rp.applyButton, &rp.closeButton),
+			)
+		})
+	}
+
+	radios := []layout.FlexChild{}
+	for i := range rp.matchRadios {
+		radios = append(radios, layout.Rigid(rp.matchRadios[i].Layout))
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return rp.list.Layout(gtx, len(rp.rules), func(gtx layout.Context, i int) layout.Dimensions {
+					rp.ruleLabel.Text = describeCategorizationRule(rp.rules[i])
+					return row(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return topBottomMargins.Layout(gtx, rp.ruleLabel.Layout)
+						}),
+						button(&rp.upButtons[i]),
+						button(&rp.downButtons[i]),
+						button(&rp.editButtons[i]),
+						button(&rp.deleteButtons[i]),
+					)
+				})
+			}),
+			inputRow(input(&rp.patternInput), input(&rp.minInput), input(&rp.maxInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, radios...)
+				})
+			}),
+			inputRow(input(&rp.categoryInput), input(&rp.payeeInput), input(&rp.tagsInput)),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, rp.messageLabel.Layout)
+			}),
+			buttonRow(&rp.submitButton, &rp.cancelButton),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, rp.previewButton.Layout)
+			}),
+		)
+	})
+}
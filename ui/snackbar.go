@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"time"
+
+	"gioui.org/io/key"
+	"gioui.org/layout"
+	"gioui.org/op"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// snackbarDuration is how long the snackbar stays after a change.
+const snackbarDuration = 6 * time.Second
+
+// undoShortcuts are the keys undoing (Ctrl+Z) and redoing (Ctrl+Shift+Z)
+// the latest change, Cmd replacing Ctrl on macOS.
+const undoShortcuts = key.Set("Short-Z|Short-Shift-Z")
+
+// Snackbar briefly shows the latest change with a button undoing it,
+// or redoing it once undone.
+type Snackbar struct {
+	label      material.LabelStyle
+	button     material.ButtonStyle
+	redoing    bool
+	lastSeenId int
+	hideAt     time.Time
+	controller domain.API
+}
+
+// createSnackbar returns Snackbar struct.
+func createSnackbar(th *material.Theme, controller domain.API) Snackbar {
+	label := material.Label(th, unit.Sp(14), "")
+	label.MaxLines = 1
+	label.Color = color.NRGBA{235, 235, 235, 255}
+
+	button := material.Button(th, &widget.Clickable{}, "Undo")
+	button.Background = color.NRGBA{3, 106, 102, 255}
+
+	return Snackbar{
+		label:      label,
+		button:     button,
+		controller: controller,
+	}
+}
+
+// show shows text with a button undoing, or redoing, the change.
+func (sb *Snackbar) show(text string, redoing bool) {
+	sb.label.Text = text
+	sb.redoing = redoing
+	sb.button.Text = "Undo"
+	if redoing {
+		sb.button.Text = "Redo"
+	}
+	sb.hideAt = time.Now().Add(snackbarDuration)
+}
+
+// undo undoes the latest change and reports whether it did.
+func (sb *Snackbar) undo() bool {
+	action, err := sb.controller.Undo()
+	if err != nil {
+		logger.Warn(err.Error())
+		sb.show(err.Error(), false)
+		return false
+	}
+
+	sb.show("Undone: "+action.Description, true)
+	return true
+}
+
+// redo redoes the latest undone change and reports whether it did.
+func (sb *Snackbar) redo() bool {
+	action, err := sb.controller.Redo()
+	if err != nil {
+		logger.Warn(err.Error())
+		sb.show(err.Error(), true)
+		return false
+	}
+
+	sb.show("Done: "+action.Description, false)
+	return true
+}
+
+// Update shows new changes, handles the undo shortcuts and button clicks
+// and reports whether a change was undone or redone.
+func (sb *Snackbar) Update(gtx layout.Context) bool {
+	changed := false
+
+	for _, e := range gtx.Events(sb) {
+		if e, ok := e.(key.Event); ok && e.State == key.Press && e.Name == "Z" {
+			if e.Modifiers.Contain(key.ModShift) {
+				changed = sb.redo() || changed
+			} else {
+				changed = sb.undo() || changed
+			}
+		}
+	}
+
+	if sb.button.Button.Clicked() {
+		if sb.redoing {
+			changed = sb.redo() || changed
+		} else {
+			changed = sb.undo() || changed
+		}
+	}
+
+	// Undo goes back to older changes, so only a newer one is shown.
+	if action, ok := sb.controller.LastAction(); ok && action.Id > sb.lastSeenId {
+		sb.lastSeenId = action.Id
+		sb.show("Done: "+action.Description, false)
+	}
+
+	// Listen to the shortcuts whatever has the focus.
+	key.InputOp{Tag: sb, Keys: undoShortcuts}.Add(gtx.Ops)
+
+	return changed
+}
+
+// Layout returns its layout, nothing once it timed out.
+func (sb *Snackbar) Layout(gtx layout.Context) layout.Dimensions {
+	if !gtx.Now.Before(sb.hideAt) {
+		return layout.Dimensions{}
+	}
+
+	op.InvalidateOp{At: sb.hideAt}.Add(gtx.Ops)
+
+	return layout.UniformInset(unit.Dp(10)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(32))}
+		paint.FillShape(gtx.Ops, color.NRGBA{23, 23, 33, 255}, r.Op())
+
+		return layout.UniformInset(unit.Dp(8)).Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			return layout.Flex{
+				Axis:      layout.Horizontal,
+				Alignment: layout.Middle,
+			}.Layout(gtx,
+				layout.Flexed(1, sb.label.Layout),
+				layout.Rigid(sb.button.Layout),
+			)
+		})
+	})
+}
@@ -0,0 +1,192 @@
+package ui
+
+import (
+	"image"
+	"image/color"
+	"strings"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+)
+
+type splitRow struct {
+	categoryInput material.EditorStyle
+	amountInput   material.EditorStyle
+	noteInput     material.EditorStyle
+	removeButton  material.ButtonStyle
+}
+
+// SplitEditor edits the split lines spreading an expense
+// over several categories.
+type SplitEditor struct {
+	theme          *material.Theme
+	rows           []splitRow
+	addButton      material.ButtonStyle
+	remainingLabel material.LabelStyle
+	total          domain.Money
+}
+
+// createSplitEditor returns SplitEditor struct.
+func createSplitEditor(th *material.Theme) SplitEditor {
+	addButton := material.Button(th, &widget.Clickable{}, "Add split")
+	addButton.Background = color.NRGBA{3, 106, 102, 255}
+
+	return SplitEditor{
+		theme:          th,
+		addButton:      addButton,
+		remainingLabel: material.Label(th, unit.Sp(14), ""),
+	}
+}
+
+// addRow appends an empty split line.
+func (se *SplitEditor) addRow() {
+	categoryInput := material.Editor(se.theme, &widget.Editor{}, "category")
+	amountInput := material.Editor(se.theme, &widget.Editor{}, "amount")
+	noteInput := material.Editor(se.theme, &widget.Editor{}, "note")
+
+	for _, input := range []*material.EditorStyle{&categoryInput, &amountInput, &noteInput} {
+		input.Editor.Alignment = text.Middle
+		input.Editor.SingleLine = true
+		input.Color = color.NRGBA{235, 235, 235, 255}
+		input.HintColor = color.NRGBA{255, 255, 255, 40}
+	}
+
+	removeButton := material.Button(se.theme, &widget.Clickable{}, "x")
+	removeButton.Background = color.NRGBA{113, 53, 53, 255}
+
+	se.rows = append(se.rows, splitRow{
+		categoryInput: categoryInput,
+		amountInput:   amountInput,
+		noteInput:     noteInput,
+		removeButton:  removeButton,
+	})
+}
+
+// setSplits replaces its lines with the split lines of an expense.
+func (se *SplitEditor) setSplits(splits []domain.Split) {
+	se.rows = []splitRow{}
+
+	for _, split := range splits {
+		se.addRow()
+		row := &se.rows[len(se.rows)-1]
+		row.categoryInput.Editor.SetText(split.Category)
+		row.amountInput.Editor.SetText(split.Amount.String())
+		row.noteInput.Editor.SetText(split.Note)
+	}
+}
+
+// clear removes every line.
+func (se *SplitEditor) clear() {
+	se.rows = []splitRow{}
+}
+
+// splits returns the split lines typed in, leaving out empty lines.
+func (se *SplitEditor) splits() ([]domain.Split, error) {
+	splits := []domain.Split{}
+
+	for _, row := range se.rows {
+		category := row.categoryInput.Editor.Text()
+		amount := row.amountInput.Editor.Text()
+		note := row.noteInput.Editor.Text()
+
+		if strings.TrimSpace(category+amount+note) == "" {
+			continue
+		}
+
+		money, err := domain.ParseMoney(amount)
+		if err != nil {
+			return nil, err
+		}
+
+		splits = append(splits, domain.Split{Category: category, Amount: money, Note: note})
+	}
+
+	return splits, nil
+}
+
+// Update updates data based on button clicks.
+func (se *SplitEditor) Update() {
+	if se.addButton.Button.Clicked() {
+		se.addRow()
+	}
+
+	for i := range se.rows {
+		if se.rows[i].removeButton.Button.Clicked() {
+			se.rows = append(se.rows[:i], se.rows[i+1:]...)
+			return
+		}
+	}
+}
+
+// Layout returns its layout.
+func (se *SplitEditor) Layout(gtx layout.Context) layout.Dimensions {
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	input := func(weight float32, editor *material.EditorStyle) layout.FlexChild {
+		return layout.Flexed(weight, func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Right: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+				paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+				return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return insideBorderMargins.Layout(gtx, editor.Layout)
+				})
+			})
+		})
+	}
+
+	children := []layout.FlexChild{}
+
+	for i := range se.rows {
+		row := &se.rows[i]
+		children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx,
+					input(2, &row.categoryInput),
+					input(1, &row.amountInput),
+					input(2, &row.noteInput),
+					layout.Rigid(row.removeButton.Layout),
+				)
+			})
+		}))
+	}
+
+	se.remainingLabel.Text = ""
+	if len(se.rows) > 0 {
+		splits, err := se.splits()
+		if err == nil {
+			amounts := []domain.Money{}
+			for _, split := range splits {
+				amounts = append(amounts, split.Amount)
+			}
+			se.remainingLabel.Text = "Left to split: " + se.total.Sub(domain.Sum(amounts...)).String()
+		}
+	}
+
+	children = append(children, layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis:      layout.Horizontal,
+			Alignment: layout.Middle,
+		}.Layout(gtx,
+			layout.Rigid(se.addButton.Layout),
+			layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout),
+			layout.Rigid(se.remainingLabel.Layout),
+		)
+	}))
+
+	return layout.Flex{Axis: layout.Vertical}.Layout(gtx, children...)
+}
@@ -21,18 +21,28 @@ type TopBar struct {
 	nextMonthButton material.ButtonStyle
 	listPageButton  material.ButtonStyle
 	addPageButton   material.ButtonStyle
+	catPageButton   material.ButtonStyle
+	recPageButton   material.ButtonStyle
+	accPageButton   material.ButtonStyle
+	rulPageButton   material.ButtonStyle
+	yearPageButton  material.ButtonStyle
+	trashPageButton material.ButtonStyle
+	impPageButton   material.ButtonStyle
+	expPageButton   material.ButtonStyle
+	menuButton      material.ButtonStyle
 	closeButton     material.ButtonStyle
+	menuOpen        bool
 	labelMonth      material.LabelStyle
 	margins         layout.Inset
 	labelMarginTop  layout.Inset
 	currentMonth    string
 	currentPage     *Page
-	monthView       *domain.MonthData
+	monthView       *MonthView
 	controller      domain.API
 }
 
 // createTopBar returns TopBar struct
-func createTopBar(th *material.Theme, currentPage *Page, monthData *domain.MonthData, controller domain.API) TopBar {
+func createTopBar(th *material.Theme, currentPage *Page, monthData *MonthView, controller domain.API) TopBar {
 	currentMonth := fmt.Sprintf("%s %d", monthData.Month.String(), monthData.Year)
 
 	prevMonthButton := material.Button(th, &widget.Clickable{}, "<")
@@ -40,6 +50,15 @@ func createTopBar(th *material.Theme, currentPage *Page, monthData *domain.Month
 	nextMonthButton := material.Button(th, &widget.Clickable{}, ">")
 	listPageButton := material.Button(th, &widget.Clickable{}, "MAIN")
 	addPageButton := material.Button(th, &widget.Clickable{}, "ADD")
+	catPageButton := material.Button(th, &widget.Clickable{}, "CAT")
+	recPageButton := material.Button(th, &widget.Clickable{}, "REC")
+	accPageButton := material.Button(th, &widget.Clickable{}, "ACC")
+	rulPageButton := material.Button(th, &widget.Clickable{}, "RUL")
+	yearPageButton := material.Button(th, &widget.Clickable{}, "YEAR")
+	trashPageButton := material.Button(th, &widget.Clickable{}, "TRASH")
+	impPageButton := material.Button(th, &widget.Clickable{}, "IMP")
+	expPageButton := material.Button(th, &widget.Clickable{}, "EXP")
+	menuButton := material.Button(th, &widget.Clickable{}, "MENU")
 	closeButton := material.Button(th, &widget.Clickable{}, "X")
 
 	labelMonth.MaxLines = 1
@@ -49,6 +68,15 @@ func createTopBar(th *material.Theme, currentPage *Page, monthData *domain.Month
 		&nextMonthButton,
 		&listPageButton,
 		&addPageButton,
+		&catPageButton,
+		&recPageButton,
+		&accPageButton,
+		&rulPageButton,
+		&yearPageButton,
+		&trashPageButton,
+		&impPageButton,
+		&expPageButton,
+		&menuButton,
 		&closeButton,
 	}
 
@@ -65,6 +93,15 @@ func createTopBar(th *material.Theme, currentPage *Page, monthData *domain.Month
 		nextMonthButton: nextMonthButton,
 		listPageButton:  listPageButton,
 		addPageButton:   addPageButton,
+		catPageButton:   catPageButton,
+		recPageButton:   recPageButton,
+		accPageButton:   accPageButton,
+		rulPageButton:   rulPageButton,
+		yearPageButton:  yearPageButton,
+		trashPageButton: trashPageButton,
+		impPageButton:   impPageButton,
+		expPageButton:   expPageButton,
+		menuButton:      menuButton,
 		closeButton:     closeButton,
 		labelMonth:      labelMonth,
 		margins:         margins,
@@ -85,7 +122,7 @@ func (t *TopBar) Update() {
 		} else {
 			t.monthView.Month--
 		}
-		*t.monthView = t.controller.CreateMonthData(t.monthView.Year, t.monthView.Month)
+		t.monthView.Reload(t.controller)
 	} else if t.nextMonthButton.Button.Clicked() {
 		if t.monthView.Month == time.December {
 			t.monthView.Month = time.January
@@ -93,13 +130,31 @@ func (t *TopBar) Update() {
 		} else {
 			t.monthView.Month++
 		}
-		*t.monthView = t.controller.CreateMonthData(t.monthView.Year, t.monthView.Month)
+		t.monthView.Reload(t.controller)
 	} else if t.listPageButton.Button.Clicked() {
-		*t.currentPage = List
-		*t.monthView = t.controller.CreateMonthData(t.monthView.Year, t.monthView.Month)
+		t.showPage(List)
+		t.monthView.Reload(t.controller)
 	} else if t.addPageButton.Button.Clicked() {
-		*t.currentPage = Add
-		*t.monthView = t.controller.CreateMonthData(t.monthView.Year, t.monthView.Month)
+		t.showPage(Add)
+		t.monthView.Reload(t.controller)
+	} else if t.catPageButton.Button.Clicked() {
+		t.showPage(Categories)
+	} else if t.recPageButton.Button.Clicked() {
+		t.showPage(Recurring)
+	} else if t.accPageButton.Button.Clicked() {
+		t.showPage(Accounts)
+	} else if t.rulPageButton.Button.Clicked() {
+		t.showPage(Rules)
+	} else if t.yearPageButton.Button.Clicked() {
+		t.showPage(Year)
+	} else if t.trashPageButton.Button.Clicked() {
+		t.showPage(Trash)
+	} else if t.impPageButton.Button.Clicked() {
+		t.showPage(Import)
+	} else if t.expPageButton.Button.Clicked() {
+		t.showPage(Export)
+	} else if t.menuButton.Button.Clicked() {
+		t.menuOpen = !t.menuOpen
 	} else if t.closeButton.Button.Clicked() {
 		os.Exit(0)
 	}
@@ -107,25 +162,56 @@ func (t *TopBar) Update() {
 	t.labelMonth.Text = t.currentMonth
 }
 
-// Layout returns its layout
+// showPage switches to page and closes the menu.
+func (t *TopBar) showPage(page Page) {
+	*t.currentPage = page
+	t.menuOpen = false
+}
+
+// Layout returns its layout. The pages are listed in a menu
+// opened below the bar so they fit narrow windows.
 func (t *TopBar) Layout(gtx layout.Context) layout.Dimensions {
-	r := clip.Rect{
-		Min: image.Pt(0, 0),
-		Max: image.Pt(gtx.Constraints.Max.X, gtx.Metric.Dp(50)),
+	if !t.menuOpen {
+		return t.layoutBar(gtx)
 	}
 
-	color := color.NRGBA{3, 106, 102, 255}
-	paint.FillShape(gtx.Ops, color, r.Op())
+	return layout.Flex{
+		Axis: layout.Vertical,
+	}.Layout(gtx,
+		layout.Rigid(t.layoutBar),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return t.layoutRow(gtx,
+				t.listPageButton,
+				t.addPageButton,
+				t.catPageButton,
+				t.recPageButton,
+				t.accPageButton,
+			)
+		}),
+		layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return t.layoutRow(gtx,
+				t.rulPageButton,
+				t.yearPageButton,
+				t.trashPageButton,
+				t.impPageButton,
+				t.expPageButton,
+			)
+		}),
+	)
+}
+
+// layoutBar returns the layout of the bar, with the month
+// navigation on the list page.
+func (t *TopBar) layoutBar(gtx layout.Context) layout.Dimensions {
+	fillBar(gtx)
 
-	if *t.currentPage == Add {
+	if *t.currentPage != List {
 		return t.margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
 			return layout.Flex{
 				Axis: layout.Horizontal,
 			}.Layout(gtx,
 				layout.Flexed(1, layout.Spacer{}.Layout),
-				layout.Rigid(t.listPageButton.Layout),
-				layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
-				layout.Rigid(t.addPageButton.Layout),
+				layout.Rigid(t.menuButton.Layout),
 				layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
 				layout.Rigid(t.closeButton.Layout),
 			)
@@ -146,11 +232,39 @@ func (t *TopBar) Layout(gtx layout.Context) layout.Dimensions {
 			layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
 			layout.Rigid(t.nextMonthButton.Layout),
 			layout.Flexed(1, layout.Spacer{}.Layout),
-			layout.Rigid(t.listPageButton.Layout),
-			layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
-			layout.Rigid(t.addPageButton.Layout),
+			layout.Rigid(t.menuButton.Layout),
 			layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
 			layout.Rigid(t.closeButton.Layout),
 		)
 	})
 }
+
+// layoutRow returns the layout of a menu row sharing its width
+// equally between buttons.
+func (t *TopBar) layoutRow(gtx layout.Context, buttons ...material.ButtonStyle) layout.Dimensions {
+	fillBar(gtx)
+
+	children := []layout.FlexChild{}
+	for i, button := range buttons {
+		if i > 0 {
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(6)}.Layout))
+		}
+		children = append(children, layout.Flexed(1, button.Layout))
+	}
+
+	return t.margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Horizontal,
+		}.Layout(gtx, children...)
+	})
+}
+
+// fillBar paints the background of a bar row.
+func fillBar(gtx layout.Context) {
+	r := clip.Rect{
+		Min: image.Pt(0, 0),
+		Max: image.Pt(gtx.Constraints.Max.X, gtx.Metric.Dp(50)),
+	}
+
+	paint.FillShape(gtx.Ops, color.NRGBA{3, 106, 102, 255}, r.Op())
+}
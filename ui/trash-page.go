@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"strings"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// TrashPage lists the deleted expenses, restoring or purging them,
+// and sets how long they stay in the trash.
+type TrashPage struct {
+	list           material.ListStyle
+	theme          *material.Theme
+	expenseLabel   material.LabelStyle
+	messageLabel   material.LabelStyle
+	retentionLabel material.LabelStyle
+	retentionInput material.EditorStyle
+	saveButton     material.ButtonStyle
+	restoreButtons []material.ButtonStyle
+	purgeButtons   []material.ButtonStyle
+	expenses       []domain.Expense
+	controller     domain.API
+}
+
+// createTrashPage returns TrashPage struct.
+func createTrashPage(th *material.Theme, controller domain.API) TrashPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	expenseLabel := material.Label(th, unit.Sp(16), "")
+	expenseLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+	retentionLabel := material.Label(th, unit.Sp(16), "Days kept in the trash (0 = forever)")
+
+	retentionInput := material.Editor(th, &widget.Editor{}, "days")
+	retentionInput.Editor.Alignment = text.Middle
+	retentionInput.Editor.SingleLine = true
+	retentionInput.Color = color.NRGBA{235, 235, 235, 255}
+	retentionInput.HintColor = color.NRGBA{255, 255, 255, 40}
+
+	saveButton := material.Button(th, &widget.Clickable{}, "Save")
+	saveButton.Background = color.NRGBA{53, 53, 113, 255}
+
+	return TrashPage{
+		list:           material.List(th, &list),
+		theme:          th,
+		expenseLabel:   expenseLabel,
+		messageLabel:   messageLabel,
+		retentionLabel: retentionLabel,
+		retentionInput: retentionInput,
+		saveButton:     saveButton,
+		controller:     controller,
+	}
+}
+
+// Reload fetches the deleted expenses and the retention from controller
+// and rebuilds the rows.
+func (tp *TrashPage) Reload() {
+	tp.messageLabel.Text = ""
+
+	expenses, err := tp.controller.GetTrash()
+	if err != nil {
+		logger.Error(err.Error())
+		tp.messageLabel.Text = "Could not load the trash: " + err.Error()
+		expenses = []domain.Expense{}
+	}
+
+	days, err := tp.controller.GetTrashRetention()
+	if err != nil {
+		logger.Error(err.Error())
+		tp.messageLabel.Text = "Could not load the trash retention: " + err.Error()
+	}
+	tp.retentionInput.Editor.SetText(strconv.Itoa(days))
+
+	tp.expenses = expenses
+	tp.restoreButtons = []material.ButtonStyle{}
+	tp.purgeButtons = []material.ButtonStyle{}
+
+	for range expenses {
+		restoreButton := material.Button(tp.theme, &widget.Clickable{}, "restore")
+		restoreButton.Background = color.NRGBA{3, 106, 102, 255}
+		purgeButton := material.Button(tp.theme, &widget.Clickable{}, "x")
+		purgeButton.Background = color.NRGBA{113, 53, 53, 255}
+
+		tp.restoreButtons = append(tp.restoreButtons, restoreButton)
+		tp.purgeButtons = append(tp.purgeButtons, purgeButton)
+	}
+}
+
+// Update updates data based on button clicks.
+func (tp *TrashPage) Update() {
+	if tp.saveButton.Button.Clicked() {
+		days, err := strconv.Atoi(strings.TrimSpace(tp.retentionInput.Editor.Text()))
+		if err == nil {
+			err = tp.controller.UpdateTrashRetention(days)
+		}
+		if err != nil {
+			tp.messageLabel.Text = err.Error()
+			return
+		}
+
+		if _, err := tp.controller.PurgeExpiredTrash(); err != nil {
+			tp.messageLabel.Text = err.Error()
+			return
+		}
+		tp.Reload()
+		return
+	}
+
+	for i := range tp.expenses {
+		if tp.restoreButtons[i].Button.Clicked() {
+			if err := tp.controller.RestoreExpense(tp.expenses[i].Id); err != nil {
+				tp.messageLabel.Text = err.Error()
+				return
+			}
+			tp.Reload()
+			return
+		}
+
+		if tp.purgeButtons[i].Button.Clicked() {
+			if err := tp.controller.PurgeExpense(tp.expenses[i].Id); err != nil {
+				tp.messageLabel.Text = err.Error()
+				return
+			}
+			tp.Reload()
+			return
+		}
+	}
+}
+
+// describeDeletedExpense returns a short description of a deleted expense
+// for its row.
+func describeDeletedExpense(expense domain.Expense) string {
+	deletedAt := expense.DeletedAt
+	if t, err := time.Parse(time.RFC3339, deletedAt); err == nil {
+		deletedAt = t.Local().Format("2006-01-02 15:04")
+	}
+
+	return fmt.Sprintf("%s %s %s (deleted %s)", expense.Date, expense.Name, expense.Amount, deletedAt)
+}
+
+// Layout returns its layout.
+func (tp *TrashPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(8)}
+	topBottomMargins := layout.Inset{Bottom: unit.Dp(6), Top: unit.Dp(12)}
+	insideBorderMargins := layout.UniformInset(unit.Dp(8))
+
+	borders := widget.Border{
+		Color:        color.NRGBA{R: 53, G: 53, B: 63, A: 255},
+		CornerRadius: unit.Dp(3),
+		Width:        unit.Dp(2),
+	}
+
+	row := func(gtx layout.Context, children ...layout.FlexChild) layout.Dimensions {
+		return layout.Inset{Bottom: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(24)+gtx.Sp(24))}
+			paint.FillShape(gtx.Ops, color.NRGBA{73, 73, 83, 255}, r.Op())
+			children = append([]layout.FlexChild{layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout)}, children...)
+			children = append(children, layout.Rigid(layout.Spacer{Width: unit.Dp(10)}.Layout))
+			return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+		})
+	}
+
+	button := func(b *material.ButtonStyle) layout.FlexChild {
+		return layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+			return layout.Inset{Left: unit.Dp(6)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+				return topBottomMargins.Layout(gtx, b.Layout)
+			})
+		})
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return tp.list.Layout(gtx, len(tp.expenses), func(gtx layout.Context, i int) layout.Dimensions {
+					tp.expenseLabel.Text = describeDeletedExpense(tp.expenses[i])
+					return row(gtx,
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return topBottomMargins.Layout(gtx, tp.expenseLabel.Layout)
+						}),
+						button(&tp.restoreButtons[i]),
+						button(&tp.purgeButtons[i]),
+					)
+				})
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, tp.messageLabel.Layout)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return layout.Flex{
+						Axis:      layout.Horizontal,
+						Alignment: layout.Middle,
+					}.Layout(gtx,
+						layout.Rigid(tp.retentionLabel.Layout),
+						layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+							return layout.Inset{Right: unit.Dp(6), Left: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+								r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(16)+gtx.Sp(20))}
+								paint.FillShape(gtx.Ops, color.NRGBA{53, 53, 63, 255}, r.Op())
+								return borders.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+									return insideBorderMargins.Layout(gtx, tp.retentionInput.Layout)
+								})
+							})
+						}),
+						layout.Rigid(tp.saveButton.Layout),
+					)
+				})
+			}),
+		)
+	})
+}
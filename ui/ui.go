@@ -21,6 +21,14 @@ type Page int
 const (
 	List Page = iota
 	Add
+	Categories
+	Recurring
+	Accounts
+	Rules
+	Year
+	Trash
+	Import
+	Export
 )
 
 func Run(w *app.Window, controller domain.API) error {
@@ -33,13 +41,48 @@ func Run(w *app.Window, controller domain.API) error {
 
 	currentPage := List
 	year, month, _ := time.Now().Date()
-	monthView := controller.CreateMonthData(year, month)
+	monthView := MonthView{}
+	monthView.Load(controller, year, month)
 
 	// Create UI parts
 	topBar := createTopBar(th, &currentPage, &monthView, controller)
-	list := createListContainer(th, &monthView, controller)
+	addFormPage := createFormPage(th, &currentPage, &monthView, controller)
+	list := createListContainer(th, &currentPage, &monthView, &addFormPage, controller)
 	dataDisplay := createDataDisplay(th, controller, &monthView)
-	addFormPage := createFormPage(th, controller)
+	categoryPage := createCategoryPage(th, controller)
+	recurringPage := createRecurringPage(th, controller)
+	accountPage := createAccountPage(th, controller)
+	rulePage := createRulePage(th, controller)
+	yearPage := createYearPage(th, controller)
+	trashPage := createTrashPage(th, controller)
+	importPage := createImportPage(th, controller)
+	exportPage := createExportPage(th, controller)
+	snackbar := createSnackbar(th, controller)
+	previousPage := currentPage
+
+	// reloadPage fetches the data shown by a page again.
+	reloadPage := func(page Page) {
+		switch page {
+		case Add:
+			addFormPage.Reload()
+		case Categories:
+			categoryPage.Reload()
+		case Recurring:
+			recurringPage.Reload()
+		case Accounts:
+			accountPage.Reload()
+		case Rules:
+			rulePage.Reload()
+		case Year:
+			yearPage.Reload()
+		case Trash:
+			trashPage.Reload()
+		case Import:
+			importPage.Reload()
+		case Export:
+			exportPage.Reload()
+		}
+	}
 
 	for {
 		e := <-w.Events()
@@ -54,9 +97,29 @@ func Run(w *app.Window, controller domain.API) error {
 
 			// UPDATE
 			topBar.Update()
+			// Leaving the form page drops any unsaved edit.
+			if currentPage != Add {
+				addFormPage.stopEditing()
+			}
+			if currentPage != previousPage {
+				reloadPage(currentPage)
+			}
+			previousPage = currentPage
+			if snackbar.Update(gtx) {
+				monthView.Reload(controller)
+				reloadPage(currentPage)
+			}
 			dataDisplay.Update()
 			addFormPage.Update()
 			list.Update()
+			categoryPage.Update()
+			recurringPage.Update()
+			accountPage.Update()
+			rulePage.Update()
+			yearPage.Update()
+			trashPage.Update()
+			importPage.Update()
+			exportPage.Update()
 
 			// LAYOUT
 			if currentPage == List {
@@ -77,7 +140,64 @@ func Run(w *app.Window, controller domain.API) error {
 					layout.Rigid(addFormPage.Layout),
 					layout.Flexed(1, layout.Spacer{Height: unit.Dp(25)}.Layout),
 				)
+			} else if currentPage == Categories {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, categoryPage.Layout),
+				)
+			} else if currentPage == Recurring {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, recurringPage.Layout),
+				)
+			} else if currentPage == Accounts {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, accountPage.Layout),
+				)
+			} else if currentPage == Rules {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, rulePage.Layout),
+				)
+			} else if currentPage == Year {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, yearPage.Layout),
+				)
+			} else if currentPage == Trash {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, trashPage.Layout),
+				)
+			} else if currentPage == Import {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, importPage.Layout),
+				)
+			} else if currentPage == Export {
+				layout.Flex{
+					Axis: layout.Vertical,
+				}.Layout(gtx,
+					layout.Rigid(topBar.Layout),
+					layout.Flexed(1, exportPage.Layout),
+				)
 			}
+			layout.S.Layout(gtx, snackbar.Layout)
 			// Send context operation to event frame
 			e.Frame(gtx.Ops)
 		case system.DestroyEvent:
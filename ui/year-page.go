@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strconv"
+	"time"
+
+	"gioui.org/layout"
+	"gioui.org/op/clip"
+	"gioui.org/op/paint"
+	"gioui.org/text"
+	"gioui.org/unit"
+	"gioui.org/widget"
+	"gioui.org/widget/material"
+	"github.com/alx-b/expensetracker/domain"
+	"github.com/alx-b/expensetracker/logger"
+)
+
+// yearPeriod is the value of the period selector showing the whole year,
+// the other values being the number of a quarter.
+const yearPeriod = "year"
+
+type YearPage struct {
+	list         material.ListStyle
+	theme        *material.Theme
+	yearLabel    material.LabelStyle
+	cellLabel    material.LabelStyle
+	messageLabel material.LabelStyle
+	prevButton   material.ButtonStyle
+	nextButton   material.ButtonStyle
+	period       *widget.Enum
+	periodRadios []material.RadioButtonStyle
+	year         int
+	data         domain.PeriodData
+	controller   domain.API
+}
+
+// createYearPage returns YearPage struct.
+func createYearPage(th *material.Theme, controller domain.API) YearPage {
+	var list widget.List
+	list.Axis = layout.Vertical
+
+	yearLabel := material.Label(th, unit.Sp(20), "")
+	cellLabel := material.Label(th, unit.Sp(16), "")
+	cellLabel.MaxLines = 1
+	messageLabel := material.Label(th, unit.Sp(14), "")
+	messageLabel.Color = color.NRGBA{235, 113, 113, 255}
+
+	prevButton := material.Button(th, &widget.Clickable{}, "<")
+	nextButton := material.Button(th, &widget.Clickable{}, ">")
+	prevButton.Background = color.NRGBA{53, 53, 113, 255}
+	nextButton.Background = color.NRGBA{53, 53, 113, 255}
+
+	period := &widget.Enum{Value: yearPeriod}
+	periodRadios := []material.RadioButtonStyle{
+		material.RadioButton(th, period, yearPeriod, "Year"),
+	}
+	for quarter := 1; quarter <= 4; quarter++ {
+		periodRadios = append(periodRadios, material.RadioButton(th, period, strconv.Itoa(quarter), fmt.Sprintf("Q%d", quarter)))
+	}
+
+	return YearPage{
+		list:         material.List(th, &list),
+		theme:        th,
+		yearLabel:    yearLabel,
+		cellLabel:    cellLabel,
+		messageLabel: messageLabel,
+		prevButton:   prevButton,
+		nextButton:   nextButton,
+		period:       period,
+		periodRadios: periodRadios,
+		year:         time.Now().Year(),
+		controller:   controller,
+	}
+}
+
+// Reload fetches the data of the selected year or quarter from controller.
+func (yp *YearPage) Reload() {
+	yp.yearLabel.Text = strconv.Itoa(yp.year)
+	yp.messageLabel.Text = ""
+
+	var data domain.PeriodData
+	var err error
+
+	if yp.period.Value == yearPeriod {
+		data, err = yp.controller.CreateYearData(yp.year)
+	} else {
+		quarter, _ := strconv.Atoi(yp.period.Value)
+		data, err = yp.controller.CreatePeriodData(yp.year, time.Month(quarter*3-2), 3)
+	}
+
+	if err != nil {
+		logger.Error(err.Error())
+		yp.messageLabel.Text = "Could not load this period: " + err.Error()
+		data = domain.PeriodData{}
+	}
+
+	yp.data = data
+}
+
+// Update updates data based on button clicks.
+func (yp *YearPage) Update() {
+	if yp.prevButton.Button.Clicked() {
+		yp.year--
+		yp.Reload()
+	}
+
+	if yp.nextButton.Button.Clicked() {
+		yp.year++
+		yp.Reload()
+	}
+
+	if yp.period.Changed() {
+		yp.Reload()
+	}
+}
+
+// layoutCells lays out cells as equally wide columns, the first one
+// aligned to the start and the others to the end.
+func (yp *YearPage) layoutCells(gtx layout.Context, cells ...string) layout.Dimensions {
+	children := []layout.FlexChild{}
+
+	for i := range cells {
+		cell := cells[i]
+		alignment := text.End
+		if i == 0 {
+			alignment = text.Start
+		}
+		children = append(children, layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+			yp.cellLabel.Text = cell
+			yp.cellLabel.Alignment = alignment
+			return yp.cellLabel.Layout(gtx)
+		}))
+	}
+
+	return layout.Inset{Top: unit.Dp(6), Bottom: unit.Dp(6), Left: unit.Dp(10), Right: unit.Dp(10)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{Axis: layout.Horizontal}.Layout(gtx, children...)
+	})
+}
+
+// Layout returns its layout.
+func (yp *YearPage) Layout(gtx layout.Context) layout.Dimensions {
+	margins := layout.UniformInset(unit.Dp(25))
+	marginTop := layout.Inset{Top: unit.Dp(10)}
+
+	months := yp.data.Months
+	categories := yp.data.Categories
+
+	// Rows are the month header, one row per month, the total, the
+	// category header and one row per category.
+	rows := len(months) + 3 + len(categories)
+
+	row := func(gtx layout.Context, i int) layout.Dimensions {
+		background := color.NRGBA{73, 73, 83, 255}
+		if i == 0 || i == len(months)+1 || i == len(months)+2 {
+			background = color.NRGBA{53, 53, 63, 255}
+		}
+
+		return layout.Inset{Bottom: unit.Dp(4)}.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+			r := clip.Rect{Max: image.Pt(gtx.Constraints.Max.X, gtx.Dp(12)+gtx.Sp(22))}
+			paint.FillShape(gtx.Ops, background, r.Op())
+
+			switch {
+			case i == 0:
+				return yp.layoutCells(gtx, "Month", "Budget", "Spent", "Left", "Income", "Net")
+			case i <= len(months):
+				month := months[i-1]
+				return yp.layoutCells(gtx,
+					fmt.Sprintf("%s %d", month.Month, month.Year),
+					month.Budget.String(),
+					month.TotalSpendings.String(),
+					month.MoneyLeft.String(),
+					month.TotalIncome.String(),
+					month.NetCashFlow.String(),
+				)
+			case i == len(months)+1:
+				return yp.layoutCells(gtx,
+					fmt.Sprintf("Total (saved %.0f%%)", yp.data.SavingsRate*100),
+					yp.data.Budget.String(),
+					yp.data.TotalSpendings.String(),
+					yp.data.MoneyLeft.String(),
+					yp.data.TotalIncome.String(),
+					yp.data.NetCashFlow.String(),
+				)
+			case i == len(months)+2:
+				return yp.layoutCells(gtx, "Category", "Budget", "Spent", "Left")
+			default:
+				category := categories[i-len(months)-3]
+				return yp.layoutCells(gtx,
+					category.Category,
+					category.Budget.String(),
+					category.Spent.String(),
+					category.Remaining.String(),
+				)
+			}
+		})
+	}
+
+	radios := []layout.FlexChild{
+		layout.Rigid(yp.prevButton.Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
+		layout.Rigid(yp.yearLabel.Layout),
+		layout.Rigid(layout.Spacer{Width: unit.Dp(18)}.Layout),
+		layout.Rigid(yp.nextButton.Layout),
+		layout.Flexed(1, layout.Spacer{}.Layout),
+	}
+	for i := range yp.periodRadios {
+		radios = append(radios, layout.Rigid(yp.periodRadios[i].Layout))
+	}
+
+	return margins.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+		return layout.Flex{
+			Axis: layout.Vertical,
+		}.Layout(gtx,
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				return layout.Flex{
+					Axis:      layout.Horizontal,
+					Alignment: layout.Middle,
+				}.Layout(gtx, radios...)
+			}),
+			layout.Rigid(func(gtx layout.Context) layout.Dimensions {
+				if yp.messageLabel.Text == "" {
+					return layout.Dimensions{}
+				}
+				return marginTop.Layout(gtx, yp.messageLabel.Layout)
+			}),
+			layout.Flexed(1, func(gtx layout.Context) layout.Dimensions {
+				return marginTop.Layout(gtx, func(gtx layout.Context) layout.Dimensions {
+					return yp.list.Layout(gtx, rows, row)
+				})
+			}),
+		)
+	})
+}